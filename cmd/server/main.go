@@ -19,6 +19,7 @@ import (
 	"solid/internal/module/slurmctld"
 	"solid/internal/module/slurmdb"
 	ldapc "solid/internal/pkg/client/ldap"
+	"solid/internal/pkg/client/sacctmgr"
 	"solid/internal/pkg/client/slurmctl"
 	"solid/internal/pkg/log"
 
@@ -26,6 +27,9 @@ import (
 	slurmdbc "solid/internal/pkg/client/slurmdb"
 
 	kingpin "github.com/alecthomas/kingpin/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/prometheus/common/version"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -68,7 +72,7 @@ func main() {
 	// Internal helper to create configured logger
 	logger, logClose, err := log.NewLogger(logOutput, logFormat, logFile, logLevel)
 	if err != nil {
-		fmt.Fprintf(os.Stdout, "unable to create logger: %w", err)
+		fmt.Fprintf(os.Stdout, "unable to create logger: %v", err)
 		return
 	}
 	defer logClose()
@@ -100,12 +104,38 @@ func main() {
 	slurmctlClient := &slurmctl.Client{}
 	slurmctlClient.Set(exec.CommandContext, logger)
 	slurmctl.SetDefault(slurmctlClient)
+	slurmctl.SetCluster(cfg.Server.Slurmdb.ClusterName, slurmctlClient)
+	for _, cl := range cfg.Server.Slurmctl.Clusters {
+		cc := &slurmctl.Client{}
+		cc.Set(slurmctl.WithEnv(exec.CommandContext, "SLURM_CONF="+cl.SlurmConf), logger.With("cluster", cl.Name))
+		slurmctl.SetCluster(cl.Name, cc)
+	}
+
+	sacctmgrClient := &sacctmgr.Client{}
+	sacctmgrClient.Set(exec.CommandContext, logger.With("client", "sacctmgr")).Protect(cfg.Server.Sacctmgr.ProtectedAccounts...)
+	sacctmgr.SetDefault(sacctmgrClient)
+
+	// Start cluster snapshot cache when configured
+	pollCtx, stopPoll := context.WithCancel(context.Background())
+	defer stopPoll()
+	if interval, err := time.ParseDuration(cfg.Server.Slurmctl.SnapshotInterval); err == nil && interval > 0 {
+		cache := slurmctl.NewSnapshotCache(slurmctlClient, interval, logger.With("client", "slurmctl-snapshot"))
+		slurmctl.SetDefaultCache(cache)
+		go cache.Run(pollCtx)
+	} else if cfg.Server.Slurmctl.SnapshotInterval != "" && cfg.Server.Slurmctl.SnapshotInterval != "0" {
+		logger.Warn("invalid slurmctl snapshot interval, snapshot cache disabled", slog.String("interval", cfg.Server.Slurmctl.SnapshotInterval))
+	}
 
 	// Build router
 	r := router.New()
 	docs.SwaggerInfo.BasePath = "/api/v1"
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Prometheus metrics, sdiag 在每次抓取时执行
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(slurmctl.NewDiagCollector(slurmctlClient, logger.With("collector", "sdiag")))
+	r.GET("/metrics", gin.WrapH(promhttp.HandlerFor(registry, promhttp.HandlerOpts{})))
+
 	// 注册所有模块（也可做“按需编译”或通过 build tag 控制）
 	// router.Register(
 	// 	user.Router{},
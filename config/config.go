@@ -11,12 +11,40 @@ type Config struct {
 }
 
 type Server struct {
-    Slurmdb Slurmdb `yaml:"slurmdb"`
-    LDAP    LDAP    `yaml:"ldap"`
+    Slurmdb  Slurmdb  `yaml:"slurmdb"`
+    Slurmctl Slurmctl `yaml:"slurmctl"`
+    Sacctmgr Sacctmgr `yaml:"sacctmgr"`
+    LDAP     LDAP     `yaml:"ldap"`
+}
+
+// Slurmctl configures the command based slurmctld client.
+type Slurmctl struct {
+    // SnapshotInterval is the background refresh interval of the cluster
+    // snapshot cache (e.g. "10s"). Empty or "0" disables the cache.
+    SnapshotInterval string `yaml:"snapshotInterval"`
+    // Clusters are the slurmctld backends of clusters other than
+    // Slurmdb.ClusterName, each reached through its own slurm.conf.
+    Clusters []SlurmctlCluster `yaml:"clusters"`
+}
+
+// SlurmctlCluster configures the slurmctld backend of one cluster.
+type SlurmctlCluster struct {
+    Name      string `yaml:"name"`
+    SlurmConf string `yaml:"slurmConf"`
+}
+
+// Sacctmgr configures the sacctmgr based accounting admin client.
+type Sacctmgr struct {
+    // ProtectedAccounts are never removed by the declarative tree sync,
+    // together with everything below them. root is always protected.
+    ProtectedAccounts []string `yaml:"protectedAccounts"`
 }
 
 type Slurmdb struct {
     ClusterName     string `yaml:"ClusterName"`
+    // Clusters restricts the clusters served from this slurmdbd. Empty means
+    // every cluster in cluster_table.
+    Clusters        []string `yaml:"clusters"`
     Host            string `yaml:"host"`
     Port            int    `yaml:"port"`
     User            string `yaml:"user"`
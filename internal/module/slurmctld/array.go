@@ -0,0 +1,132 @@
+package slurmctld
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"solid/internal/pkg/client/slurmctl"
+	"solid/internal/pkg/common/jobid"
+	"solid/internal/pkg/common/response"
+	"solid/internal/pkg/model"
+)
+
+// HandlerGetArraySummary 获取数组作业在队列中的任务状态统计。
+//
+// @Summary 获取数组作业统计
+// @Description 通过 squeue -r 获取数组作业在队列中的所有任务并按状态统计, 已离开队列的任务请使用 accounting 接口
+// @Tags slurm-scheduling, job
+// @Produce json
+// @Param id path int true "数组作业ID"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/scheduling/job/:id/array [get]
+func HandlerGetArraySummary(c *gin.Context) {
+	client := ctlClient(c)
+	if client == nil {
+		return
+	}
+	id, err := jobid.Parse(c.Param("id"))
+	if err != nil || id.IsArrayTask || id.IsHetComponent {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid array job id"})
+		return
+	}
+
+	tasks, err := client.GetArrayTasks(c.Request.Context(), id.JobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{Results: slurmctl.SummarizeArray(id.JobID, tasks)})
+}
+
+// HandlerGetArrayTasks 获取数组作业在队列中的任务列表（分页）。
+//
+// @Summary 获取数组作业任务列表
+// @Description 通过 squeue -r 获取数组作业在队列中的所有任务, 每个任务一条记录; 分页返回
+// @Tags slurm-scheduling, job
+// @Produce json
+// @Param id path int true "数组作业ID"
+// @Param page query int false "页号(从1开始)" example("1") default(1) minimum(1)
+// @Param page_size query int false "每页数量" example("20") default(20) minimum(1)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/scheduling/job/:id/array/tasks [get]
+func HandlerGetArrayTasks(c *gin.Context) {
+	client := ctlClient(c)
+	if client == nil {
+		return
+	}
+	id, err := jobid.Parse(c.Param("id"))
+	if err != nil || id.IsArrayTask || id.IsHetComponent {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid array job id"})
+		return
+	}
+
+	var pq model.PagingQuery
+	_ = c.ShouldBindQuery(&pq)
+	pq.SetDefaults(1, 20, 100)
+	if err := pq.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid paging parameters"})
+		return
+	}
+
+	tasks, err := client.GetArrayTasks(c.Request.Context(), id.JobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+	total := len(tasks)
+	start := pq.Offset()
+	if start > total {
+		start = total
+	}
+	end := start + pq.Limit()
+	if end > total {
+		end = total
+	}
+	prevURL, nextURL := response.BuildPageLinks(c.Request.URL, pq.Page, pq.PageSize, total)
+	c.JSON(http.StatusOK, response.Response{Count: total, Previous: prevURL, Next: nextURL, Results: tasks[start:end]})
+}
+
+// HandlerGetHetJob 获取异构作业的各组件。
+//
+// @Summary 获取异构作业
+// @Description 通过 scontrol show job 获取异构作业的 leader 与各组件, 每个组件包含自己的 TRES、节点与作业步; id 可为 leader ID 或 1234+1 形式的组件ID; 非异构作业作为只有一个组件返回
+// @Tags slurm-scheduling, job
+// @Produce json
+// @Param id path string true "作业ID, 如 1234 或 1234+1"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/scheduling/job/:id/het [get]
+func HandlerGetHetJob(c *gin.Context) {
+	client := ctlClient(c)
+	if client == nil {
+		return
+	}
+	id, err := jobid.Parse(c.Param("id"))
+	if err != nil || id.IsArrayTask {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid het job id"})
+		return
+	}
+
+	het, err := client.GetHetJob(c.Request.Context(), id.String())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+	if het == nil {
+		c.JSON(http.StatusNotFound, response.Response{Detail: "job not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{Count: len(het.Components), Results: het})
+}
@@ -0,0 +1,33 @@
+package slurmctld
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"solid/internal/pkg/client/slurmctl"
+	"solid/internal/pkg/common/response"
+)
+
+// ctlClient 返回 ?cluster= 指定集群的 slurmctl 客户端, 未指定时为默认客户端; 出错时写入响应并返回 nil.
+func ctlClient(c *gin.Context) *slurmctl.Client {
+	name := strings.TrimSpace(c.Query("cluster"))
+	client := slurmctl.ForCluster(name)
+	if client != nil {
+		return client
+	}
+	if name == "" {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: "slurmclt client not initialized"})
+	} else {
+		c.JSON(http.StatusNotFound, response.Response{Detail: fmt.Sprintf("no slurmctl backend for cluster %s", name)})
+	}
+	return nil
+}
+
+// defaultCluster 请求是否针对默认集群, 快照缓存只覆盖默认集群.
+func defaultCluster(c *gin.Context) bool {
+	name := strings.TrimSpace(c.Query("cluster"))
+	return name == "" || slurmctl.ForCluster(name) == slurmctl.Default()
+}
@@ -0,0 +1,72 @@
+package slurmctld
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"solid/internal/pkg/common/response"
+)
+
+// DiagResetRequest 清零调度统计的请求体.
+type DiagResetRequest struct {
+	Operator string `json:"operator" binding:"required"` // 操作人
+}
+
+// HandlerGetDiag 获取 slurmctld 调度统计。
+//
+// @Summary 获取调度器统计
+// @Description 通过 sdiag 获取 slurmctld 服务线程数、agent 队列长度、主调度与回填调度周期统计、按消息类型与用户的 RPC 统计
+// @Tags slurm-scheduling, diag
+// @Produce json
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/scheduling/diag [get]
+func HandlerGetDiag(c *gin.Context) {
+	client := ctlClient(c)
+	if client == nil {
+		return
+	}
+
+	diag, err := client.GetDiag(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{Results: diag})
+}
+
+// HandlerResetDiag 清零 slurmctld 调度统计。
+//
+// @Summary 清零调度器统计
+// @Description 通过 sdiag --reset 清零 slurmctld 统计, 需要服务以 SlurmUser 或 root 运行; operator 必填
+// @Tags slurm-scheduling, diag
+// @Accept json
+// @Produce json
+// @Param body body DiagResetRequest true "操作人"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/scheduling/diag/reset [post]
+func HandlerResetDiag(c *gin.Context) {
+	client := ctlClient(c)
+	if client == nil {
+		return
+	}
+
+	var req DiagResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: fmt.Sprintf("invalid json: %s", err)})
+		return
+	}
+	if err := client.ResetDiag(c.Request.Context(), req.Operator); err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{Results: req})
+}
@@ -0,0 +1,343 @@
+package slurmctld
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"solid/internal/pkg/client/slurmctl"
+	slurmctlmodels "solid/internal/pkg/client/slurmctl/models"
+	slurmdbc "solid/internal/pkg/client/slurmdb"
+	"solid/internal/pkg/common/hostlist"
+	"solid/internal/pkg/common/response"
+)
+
+// Slurm 中固定的 TRES ID.
+const (
+	tresCPU  = 1
+	tresNode = 4
+)
+
+// 阻塞原因评分, 越高越可能是作业无法启动的原因.
+const (
+	scoreReason    = 100 // squeue 给出的等待原因直接指向的限制
+	scoreExceeded  = 90  // 检查发现已达上限的限制
+	scorePartition = 85  // 分区不可用
+	scoreNodes     = 70  // 分区中没有可用节点
+	scoreResources = 60  // 等待资源
+	scorePriority  = 40  // 等待优先级更高的作业
+)
+
+// PendingBlocker 可能导致作业等待的原因.
+type PendingBlocker struct {
+	Kind    string `json:"kind"`            // reason, association, qos, partition, nodes
+	Score   int    `json:"score"`           // 可能性评分, 越高越可能
+	Message string `json:"message"`         // 说明
+	Limit   string `json:"limit,omitempty"` // 触发的限制, 如 GrpJobs=10
+	Usage   string `json:"usage,omitempty"` // 当前使用量
+}
+
+// PendingDiagnosis 排队作业的诊断结果.
+type PendingDiagnosis struct {
+	JobID      string             `json:"jobid"`
+	State      string             `json:"state"`
+	Reason     string             `json:"reason"`      // squeue 等待原因代码
+	ReasonText string             `json:"reason_text"` // 等待原因说明
+	StartTime  string             `json:"start_time"`  // squeue --start 预计开始时间
+	SchedNodes string             `json:"sched_nodes"` // squeue --start 计划分配的节点
+	Blockers   []PendingBlocker   `json:"blockers"`    // 按评分降序排列的可能原因
+	Warnings   []string           `json:"warnings"`    // 无法完成的检查
+	Job        slurmctlmodels.Job `json:"job"`
+}
+
+// HandlerDiagnosePendingJob 诊断排队作业无法启动的原因。
+//
+// @Summary 诊断排队作业
+// @Description 结合 squeue --start 预计开始时间、等待原因说明、slurmdb 中关联与 QoS 的 GrpTRES/MaxJobs/MaxSubmitJobs 等限制与当前使用量、分区状态与可用节点, 返回按可能性排序的阻塞原因
+// @Tags slurm-scheduling, job
+// @Produce json
+// @Param id path string true "作业ID"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/scheduling/job/:id/diagnosis [get]
+func HandlerDiagnosePendingJob(c *gin.Context) {
+	client := ctlClient(c)
+	if client == nil {
+		return
+	}
+	ctx := c.Request.Context()
+	jobid := strings.TrimSpace(c.Param("id"))
+
+	// 当前队列, 用于统计关联与 QoS 的使用量
+	var jobs slurmctlmodels.Jobs
+	var err error
+	if cache := slurmctl.DefaultCache(); cache != nil && defaultCluster(c) {
+		var snap *slurmctl.Snapshot
+		if snap, err = cache.Get(false); err == nil {
+			jobs = snap.Jobs
+		}
+	} else {
+		jobs, err = client.GetJobs(ctx, slurmctl.JobsFilter{})
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+
+	var job *slurmctlmodels.Job
+	for i := range jobs {
+		if jobs[i].Jobid == jobid {
+			job = &jobs[i]
+			break
+		}
+	}
+	if job == nil {
+		c.JSON(http.StatusNotFound, response.Response{Detail: fmt.Sprintf("job %s not found in queue", jobid)})
+		return
+	}
+	if job.State != "PD" {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: fmt.Sprintf("job %s is not pending (state %s)", jobid, job.State)})
+		return
+	}
+
+	d := PendingDiagnosis{
+		JobID:      job.Jobid,
+		State:      job.State,
+		Reason:     job.Reason,
+		ReasonText: slurmctl.ReasonText(job.Reason),
+		Blockers:   []PendingBlocker{},
+		Warnings:   []string{},
+		Job:        *job,
+	}
+	if start, err := client.GetJobStart(ctx, jobid); err != nil {
+		d.Warnings = append(d.Warnings, fmt.Sprintf("unable to get estimated start time: %s", err))
+	} else if start != nil {
+		d.StartTime, d.SchedNodes = start.StartTime, start.SchedNodes
+	}
+
+	d.Blockers = append(d.Blockers, reasonBlocker(job.Reason)...)
+	d.Blockers = append(d.Blockers, diagnosePartitions(ctx, client, job, &d.Warnings)...)
+	if db := slurmdbc.Default(); db == nil {
+		d.Warnings = append(d.Warnings, "slurmdb client not initialized, association and qos limits are not checked")
+	} else if db, err = db.WithCluster(ctx, c.Query("cluster")); err != nil {
+		d.Warnings = append(d.Warnings, fmt.Sprintf("association and qos limits are not checked: %s", err))
+	} else {
+		d.Blockers = append(d.Blockers, diagnoseAssociation(ctx, db, job, jobs, &d.Warnings)...)
+		d.Blockers = append(d.Blockers, diagnoseQos(ctx, db, job, jobs, &d.Warnings)...)
+	}
+	sort.SliceStable(d.Blockers, func(i, j int) bool { return d.Blockers[i].Score > d.Blockers[j].Score })
+
+	c.JSON(http.StatusOK, response.Response{Results: d})
+}
+
+// reasonBlocker 根据 squeue 的等待原因代码给出阻塞原因.
+func reasonBlocker(reason string) []PendingBlocker {
+	switch {
+	case reason == "" || reason == "None":
+		return nil
+	case reason == "Priority":
+		return []PendingBlocker{{Kind: "priority", Score: scorePriority, Message: slurmctl.ReasonText(reason)}}
+	case reason == "Resources":
+		return []PendingBlocker{{Kind: "nodes", Score: scoreResources, Message: slurmctl.ReasonText(reason)}}
+	case strings.HasPrefix(reason, "Assoc"):
+		return []PendingBlocker{{Kind: "association", Score: scoreReason, Message: slurmctl.ReasonText(reason), Limit: reason}}
+	case strings.HasPrefix(reason, "QOS"):
+		return []PendingBlocker{{Kind: "qos", Score: scoreReason, Message: slurmctl.ReasonText(reason), Limit: reason}}
+	case strings.HasPrefix(reason, "Partition"):
+		return []PendingBlocker{{Kind: "partition", Score: scoreReason, Message: slurmctl.ReasonText(reason), Limit: reason}}
+	default:
+		return []PendingBlocker{{Kind: "reason", Score: scoreReason, Message: slurmctl.ReasonText(reason)}}
+	}
+}
+
+// diagnosePartitions 检查作业所在分区的状态与可用节点.
+func diagnosePartitions(ctx context.Context, client *slurmctl.Client, job *slurmctlmodels.Job, warnings *[]string) []PendingBlocker {
+	blockers := make([]PendingBlocker, 0)
+	for _, name := range strings.Split(job.Partition, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		part, err := client.GetPartition(ctx, name)
+		if err != nil {
+			*warnings = append(*warnings, fmt.Sprintf("unable to check partition %s: %s", name, err))
+			continue
+		}
+		if part.State != "UP" {
+			blockers = append(blockers, PendingBlocker{
+				Kind: "partition", Score: scorePartition,
+				Message: fmt.Sprintf("分区 %s 处于 %s 状态, 不会调度新作业", name, part.State),
+				Usage:   "State=" + part.State,
+			})
+			continue
+		}
+		available := 0
+		for state, n := range part.NodeStates {
+			if strings.HasPrefix(state, "idle") || strings.HasPrefix(state, "mix") {
+				available += n
+			}
+		}
+		if part.NodeStates != nil && available == 0 {
+			blockers = append(blockers, PendingBlocker{
+				Kind: "nodes", Score: scoreNodes,
+				Message: fmt.Sprintf("分区 %s 中没有空闲或部分空闲的节点", name),
+				Usage:   formatNodeStates(part.NodeStates),
+			})
+		}
+	}
+	return blockers
+}
+
+// jobUsage 一组作业的使用量.
+type jobUsage struct {
+	running   int // 运行中的作业数
+	submitted int // 运行与排队的作业数
+	cpus      int // 运行中作业的 CPU 数
+	nodes     int // 运行中作业的节点数
+}
+
+// countUsage 统计满足 match 的作业的使用量.
+func countUsage(jobs slurmctlmodels.Jobs, match func(slurmctlmodels.Job) bool) jobUsage {
+	var u jobUsage
+	for _, j := range jobs {
+		if !match(j) {
+			continue
+		}
+		switch j.State {
+		case "R", "CF", "S":
+			u.running++
+			u.submitted++
+			n, _ := strconv.Atoi(j.CPUs)
+			u.cpus += n
+			if hosts, err := hostlist.Expand(j.Nodelist); err == nil {
+				u.nodes += len(hosts)
+			}
+		case "PD":
+			u.submitted++
+		}
+	}
+	return u
+}
+
+// limitCheck 单项限制, max <= 0 表示不限制.
+type limitCheck struct {
+	name string
+	max  int
+	used int
+}
+
+// checkLimits 返回已达上限的限制.
+func checkLimits(kind, scope string, checks []limitCheck) []PendingBlocker {
+	blockers := make([]PendingBlocker, 0)
+	for _, l := range checks {
+		if l.max <= 0 || l.used < l.max {
+			continue
+		}
+		blockers = append(blockers, PendingBlocker{
+			Kind:    kind,
+			Score:   scoreExceeded,
+			Message: fmt.Sprintf("%s 的 %s 限制已达上限", scope, l.name),
+			Limit:   fmt.Sprintf("%s=%d", l.name, l.max),
+			Usage:   strconv.Itoa(l.used),
+		})
+	}
+	return blockers
+}
+
+// diagnoseAssociation 检查用户关联与账户关联的限制.
+func diagnoseAssociation(ctx context.Context, db *slurmdbc.Client, job *slurmctlmodels.Job, jobs slurmctlmodels.Jobs, warnings *[]string) []PendingBlocker {
+	blockers := make([]PendingBlocker, 0)
+	partition := strings.Split(job.Partition, ",")[0]
+
+	// 用户关联优先取分区级关联
+	user, err := db.GetAssociation(ctx, job.Account, job.User, partition)
+	if err != nil {
+		user, err = db.GetAssociation(ctx, job.Account, job.User, "")
+	}
+	if err != nil {
+		*warnings = append(*warnings, fmt.Sprintf("unable to get association of user %s in account %s: %s", job.User, job.Account, err))
+	} else {
+		u := countUsage(jobs, func(j slurmctlmodels.Job) bool { return j.User == job.User && j.Account == job.Account })
+		blockers = append(blockers, checkLimits("association", fmt.Sprintf("用户 %s 在账户 %s 中的关联", job.User, job.Account), []limitCheck{
+			{"MaxJobs", int(user.MaxJobs), u.running},
+			{"MaxSubmitJobs", int(user.MaxSubmitJobs), u.submitted},
+			{"GrpJobs", int(user.GrpJobs), u.running},
+			{"GrpSubmitJobs", int(user.GrpSubmitJobs), u.submitted},
+			{"GrpTRES cpu", tresCount(user.GrpTres, tresCPU), u.cpus},
+			{"GrpTRES node", tresCount(user.GrpTres, tresNode), u.nodes},
+		})...)
+	}
+
+	acct, err := db.GetAssociation(ctx, job.Account, "", "")
+	if err != nil {
+		*warnings = append(*warnings, fmt.Sprintf("unable to get association of account %s: %s", job.Account, err))
+		return blockers
+	}
+	u := countUsage(jobs, func(j slurmctlmodels.Job) bool { return j.Account == job.Account })
+	blockers = append(blockers, checkLimits("association", fmt.Sprintf("账户 %s 的关联", job.Account), []limitCheck{
+		{"GrpJobs", int(acct.GrpJobs), u.running},
+		{"GrpSubmitJobs", int(acct.GrpSubmitJobs), u.submitted},
+		{"GrpTRES cpu", tresCount(acct.GrpTres, tresCPU), u.cpus},
+		{"GrpTRES node", tresCount(acct.GrpTres, tresNode), u.nodes},
+	})...)
+	return blockers
+}
+
+// diagnoseQos 检查作业 QoS 的限制.
+func diagnoseQos(ctx context.Context, db *slurmdbc.Client, job *slurmctlmodels.Job, jobs slurmctlmodels.Jobs, warnings *[]string) []PendingBlocker {
+	if strings.TrimSpace(job.QoS) == "" {
+		return nil
+	}
+	qos, err := db.GetQosByName(ctx, job.QoS)
+	if err != nil {
+		*warnings = append(*warnings, fmt.Sprintf("unable to get qos %s: %s", job.QoS, err))
+		return nil
+	}
+	all := countUsage(jobs, func(j slurmctlmodels.Job) bool { return j.QoS == job.QoS })
+	user := countUsage(jobs, func(j slurmctlmodels.Job) bool { return j.QoS == job.QoS && j.User == job.User })
+	return checkLimits("qos", fmt.Sprintf("QoS %s", job.QoS), []limitCheck{
+		{"MaxJobsPerUser", int(qos.MaxJobsPerUser), user.running},
+		{"MaxSubmitJobsPerUser", int(qos.MaxSubmitJobsPerUser), user.submitted},
+		{"MaxTRESPerUser cpu", tresCount(qos.MaxTresPU, tresCPU), user.cpus},
+		{"MaxTRESPerUser node", tresCount(qos.MaxTresPU, tresNode), user.nodes},
+		{"GrpJobs", int(qos.GrpJobs), all.running},
+		{"GrpSubmitJobs", int(qos.GrpSubmitJobs), all.submitted},
+		{"GrpTRES cpu", tresCount(qos.GrpTres, tresCPU), all.cpus},
+		{"GrpTRES node", tresCount(qos.GrpTres, tresNode), all.nodes},
+	})
+}
+
+// tresCount 从 slurmdb 中形如 1=100,4=10 的 TRES 字符串中取出指定 TRES ID 的值, 未设置返回 0.
+func tresCount(tres string, id int) int {
+	for _, item := range strings.Split(tres, ",") {
+		k, v, ok := strings.Cut(item, "=")
+		if !ok || strings.TrimSpace(k) != strconv.Itoa(id) {
+			continue
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(v))
+		return n
+	}
+	return 0
+}
+
+// formatNodeStates 将节点状态统计格式化为 alloc=3,drain=1.
+func formatNodeStates(states map[string]int) string {
+	keys := make([]string, 0, len(states))
+	for k := range states {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%d", k, states[k]))
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,110 @@
+package slurmctld
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"solid/internal/pkg/client/slurmctl"
+	"solid/internal/pkg/common/response"
+)
+
+// eventHeartbeatInterval SSE 心跳间隔, 防止代理断开空闲连接.
+const eventHeartbeatInterval = 15 * time.Second
+
+// HandlerStreamEvents 以 Server-Sent Events 推送作业、节点与分区的状态变化。
+//
+// @Summary 订阅调度事件
+// @Description 对比相邻的集群快照并推送状态变化事件(job_submitted, job_started, job_completed, job_failed, job_cancelled, node_drained, node_down, node_resumed, partition_state_changed 等)；需要启用快照缓存；断线后可携带 Last-Event-ID 续传
+// @Tags slurm-scheduling, event
+// @Produce text/event-stream
+// @Param user query string false "用户, 多个以逗号分隔; 设置后只推送作业事件"
+// @Param account query string false "账户, 多个以逗号分隔; 设置后只推送作业事件"
+// @Param partition query string false "分区, 多个以逗号分隔"
+// @Param type query string false "事件类型, 多个以逗号分隔" example("job_started,job_failed")
+// @Param Last-Event-ID header string false "上次收到的事件 ID; 不携带时只推送订阅后的事件, ID 来自重启前时推送全部保留的历史事件"
+// @Success 200 {object} slurmctl.Event
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/scheduling/events [get]
+func HandlerStreamEvents(c *gin.Context) {
+	cache := slurmctl.DefaultCache()
+	if cache == nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: "snapshot cache not enabled"})
+		return
+	}
+
+	filter := slurmctl.EventFilter{
+		User:      strings.TrimSpace(c.Query("user")),
+		Account:   strings.TrimSpace(c.Query("account")),
+		Partition: strings.TrimSpace(c.Query("partition")),
+		Types:     strings.TrimSpace(c.Query("type")),
+	}
+
+	// 浏览器 EventSource 重连时通过请求头携带, 也允许使用查询参数
+	var lastID uint64
+	lastIDStr := strings.TrimSpace(c.GetHeader("Last-Event-ID"))
+	if lastIDStr == "" {
+		lastIDStr = strings.TrimSpace(c.Query("last_event_id"))
+	}
+	if lastIDStr != "" {
+		id, err := strconv.ParseUint(lastIDStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid Last-Event-ID"})
+			return
+		}
+		lastID = id
+	}
+
+	replay, events, cancel := cache.Events().Subscribe(lastID)
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	for _, e := range replay {
+		if filter.Match(e) {
+			writeEvent(c.Writer, e)
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeatInterval)
+	defer heartbeat.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case e, ok := <-events:
+			if !ok {
+				// 订阅者过慢被断开, 客户端可使用 Last-Event-ID 重连
+				return false
+			}
+			if filter.Match(e) {
+				writeEvent(w, e)
+			}
+			return true
+		case <-heartbeat.C:
+			_, _ = io.WriteString(w, ": ping\n\n")
+			return true
+		}
+	})
+}
+
+// writeEvent 按 SSE 格式写出一个事件.
+func writeEvent(w io.Writer, e slurmctl.Event) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+}
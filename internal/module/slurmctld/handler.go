@@ -1,9 +1,13 @@
 package slurmctld
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"solid/internal/pkg/client/slurmctl"
 	slurmctlmodels "solid/internal/pkg/client/slurmctl/models"
+	"solid/internal/pkg/common/hostlist"
+	jobidpkg "solid/internal/pkg/common/jobid"
 	"solid/internal/pkg/common/response"
 	"solid/internal/pkg/model"
 	"sort"
@@ -13,14 +17,16 @@ import (
 )
 
 // @Param partiton query string false "分区, 多分区采用逗号分割" example("p1,p2")
+// @Param fresh query bool false "是否绕过快照缓存实时获取" default(false)
+// @Header 200 {number} X-Snapshot-Age "快照年龄(秒), 仅启用快照缓存时返回"
 // @Param paging query bool false "是否开启分页" default(true)
 // @Param page query int false "页号(从1开始)" example("1") default(1) minimum(1)
 // @Param page_size query int false "每页数量" example("20") default(20) minimum(1)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
 // @Router /api/v1/slurm/scheduling/node/all?partiton=xxx&paging=xxx&page=xxx&page_size=xxx [get]
 func HandlerGetAllNodes(c *gin.Context) {
-	client := slurmctl.Default()
+	client := ctlClient(c)
 	if client == nil {
-		c.JSON(http.StatusInternalServerError, response.Response{Detail: "slurmclt client not initialized"})
 		return
 	}
 
@@ -28,10 +34,20 @@ func HandlerGetAllNodes(c *gin.Context) {
 	condPartition := strings.TrimSpace(c.Query("partiton"))
 
 	// 调用 client.GetNodes()
-	nodesMap, err := client.GetNodes(c.Request.Context(), condPartition)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
-		return
+	// 启用快照缓存时从快照中读取, 否则直接调用 client.GetNodes()
+	var nodesMap slurmctlmodels.Nodes
+	var err error
+	if snap, ok := getSnapshot(c); ok {
+		if snap == nil {
+			return
+		}
+		nodesMap = filterNodesByPartition(snap.Nodes, condPartition)
+	} else {
+		nodesMap, err = client.GetNodes(c.Request.Context(), condPartition)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+			return
+		}
 	}
 
 	// 将 map 转为切片并按名称排序，便于稳定分页
@@ -89,28 +105,75 @@ func HandlerGetAllNodes(c *gin.Context) {
 // HandlerGetAllJobs 获取作业列表（可分页）。
 //
 // @Summary 获取作业列表
-// @Description 通过 squeue 获取全部作业信息；支持分页返回
+// @Description 通过 squeue 获取作业信息；除 reason 外的过滤条件与排序均下推为 squeue 参数；支持分页返回
 // @Tags slurm-scheduling, job
 // @Produce json
+// @Param user query string false "用户, 多个以逗号分隔"
+// @Param account query string false "账户, 多个以逗号分隔"
+// @Param partition query string false "分区, 多个以逗号分隔"
+// @Param state query string false "作业状态, 多个以逗号分隔, all 表示所有状态; 默认与 squeue 一致不含已结束的作业" example("PENDING,RUNNING")
+// @Param qos query string false "QoS, 多个以逗号分隔"
+// @Param reason query string false "挂起原因" example("Priority")
+// @Param name query string false "作业名称, 多个以逗号分隔"
+// @Param sort query string false "排序字段: priority, submit_time, jobid; 前缀 - 表示降序" example("-priority")
+// @Param node query string false "节点名称或 hostlist 表达式, 仅返回运行在这些节点上的作业" example("cn1858")
+// @Param expand_nodes query bool false "是否返回展开后的节点列表" default(false)
+// @Param fresh query bool false "是否绕过快照缓存实时获取" default(false)
 // @Param paging query bool false "是否开启分页" default(true)
 // @Param page query int false "页号(从1开始)" example("1") default(1) minimum(1)
 // @Param page_size query int false "每页数量" example("20") default(20) minimum(1)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
 // @Success 200 {object} response.Response
+// @Header 200 {number} X-Snapshot-Age "快照年龄(秒), 仅启用快照缓存时返回"
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
-// @Router /api/v1/slurm/scheduling/job/all?paging=xxx&page=xxx&page_size=xxx [get]
+// @Router /api/v1/slurm/scheduling/job/all?user=xxx&state=xxx&sort=xxx&paging=xxx&page=xxx&page_size=xxx [get]
 func HandlerGetAllJobs(c *gin.Context) {
-	client := slurmctl.Default()
+	client := ctlClient(c)
 	if client == nil {
-		c.JSON(http.StatusInternalServerError, response.Response{Detail: "slurmclt client not initialized"})
 		return
 	}
 
-	jobs, err := client.GetJobs(c.Request.Context())
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+	filter := slurmctl.JobsFilter{
+		User:      strings.TrimSpace(c.Query("user")),
+		Account:   strings.TrimSpace(c.Query("account")),
+		Partition: strings.TrimSpace(c.Query("partition")),
+		State:     strings.TrimSpace(c.Query("state")),
+		QoS:       strings.TrimSpace(c.Query("qos")),
+		Reason:    strings.TrimSpace(c.Query("reason")),
+		Node:      strings.TrimSpace(c.Query("node")),
+		Name:      strings.TrimSpace(c.Query("name")),
+		Sort:      strings.TrimSpace(c.Query("sort")),
+	}
+	if err := filter.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: err.Error()})
 		return
 	}
+	// 节点过滤支持 hostlist 表达式, 下推前先校验
+	if filter.Node != "" {
+		if _, err := hostlist.Expand(filter.Node); err != nil {
+			c.JSON(http.StatusBadRequest, response.Response{Detail: fmt.Sprintf("invalid node parameter: %s", err)})
+			return
+		}
+	}
+
+	var jobs slurmctlmodels.Jobs
+	var err error
+	if snap, ok := getSnapshot(c); ok {
+		if snap == nil {
+			return
+		}
+		jobs = slurmctl.FilterJobs(snap.Jobs, filter)
+	} else {
+		jobs, err = client.GetJobs(c.Request.Context(), filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+			return
+		}
+	}
+	if c.Query("expand_nodes") == "true" {
+		expandJobNodes(jobs)
+	}
 
 	total := len(jobs)
 
@@ -155,15 +218,16 @@ func HandlerGetAllJobs(c *gin.Context) {
 // @Description 通过 jobid 调用 scontrol show job，返回作业关键信息
 // @Tags slurm-scheduling, job
 // @Produce json
-// @Param jobid query string true "Job ID"
+// @Param jobid query string true "Job ID, 数组任务格式为 123_7"
+// @Param expand_nodes query bool false "是否返回展开后的节点列表" default(false)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/slurm/scheduling/job?jobid=xxx [get]
 func HandlerGetJob(c *gin.Context) {
-	client := slurmctl.Default()
+	client := ctlClient(c)
 	if client == nil {
-		c.JSON(http.StatusInternalServerError, response.Response{Detail: "slurmclt client not initialized"})
 		return
 	}
 
@@ -172,12 +236,19 @@ func HandlerGetJob(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.Response{Detail: "missing jobid parameter"})
 		return
 	}
+	if _, err := jobidpkg.Parse(jobid); err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid jobid parameter"})
+		return
+	}
 
 	job, err := client.GetJob(c.Request.Context(), jobid)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
 		return
 	}
+	if c.Query("expand_nodes") == "true" {
+		job.Nodes, _ = hostlist.Expand(job.Nodelist)
+	}
 
 	c.JSON(http.StatusOK, response.Response{Results: job})
 }
@@ -188,15 +259,15 @@ func HandlerGetJob(c *gin.Context) {
 // @Description 通过 jobid 调用调度端查询步骤信息，返回 stepid/stepname/stepstate
 // @Tags slurm-scheduling, job
 // @Produce json
-// @Param jobid query string true "Job ID"
+// @Param jobid query string true "Job ID, 数组任务格式为 123_7"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/slurm/scheduling/job/steps?jobid=xxx [get]
 func HandlerGetStepsOfJob(c *gin.Context) {
-	client := slurmctl.Default()
+	client := ctlClient(c)
 	if client == nil {
-		c.JSON(http.StatusInternalServerError, response.Response{Detail: "slurmclt client not initialized"})
 		return
 	}
 
@@ -205,6 +276,10 @@ func HandlerGetStepsOfJob(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.Response{Detail: "missing jobid parameter"})
 		return
 	}
+	if _, err := jobidpkg.Parse(jobid); err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid jobid parameter"})
+		return
+	}
 
 	steps, err := client.GetStepsOfJob(c.Request.Context(), jobid)
 	if err != nil {
@@ -221,24 +296,35 @@ func HandlerGetStepsOfJob(c *gin.Context) {
 // @Description 通过 scontrol show partition 获取所有分区信息；支持分页返回
 // @Tags slurm-scheduling, partition
 // @Produce json
+// @Param fresh query bool false "是否绕过快照缓存实时获取" default(false)
 // @Param paging query bool false "是否开启分页" default(true)
 // @Param page query int false "页号(从1开始)" example("1") default(1) minimum(1)
 // @Param page_size query int false "每页数量" example("20") default(20) minimum(1)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
 // @Success 200 {object} response.Response
+// @Header 200 {number} X-Snapshot-Age "快照年龄(秒), 仅启用快照缓存时返回"
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/slurm/scheduling/partition/all?paging=xxx&page=xxx&page_size=xxx [get]
 func HandlerGetAllPartitions(c *gin.Context) {
-	client := slurmctl.Default()
+	client := ctlClient(c)
 	if client == nil {
-		c.JSON(http.StatusInternalServerError, response.Response{Detail: "slurmclt client not initialized"})
 		return
 	}
 
-	parts, err := client.GetPartitions(c.Request.Context())
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
-		return
+	var parts slurmctlmodels.Partitions
+	var err error
+	if snap, ok := getSnapshot(c); ok {
+		if snap == nil {
+			return
+		}
+		parts = snap.Partitions
+	} else {
+		parts, err = client.GetPartitions(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+			return
+		}
 	}
 
 	total := len(parts)
@@ -285,14 +371,14 @@ func HandlerGetAllPartitions(c *gin.Context) {
 // @Tags slurm-scheduling, partition
 // @Produce json
 // @Param name query string true "分区名称"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/slurm/scheduling/partition?name=xxx [get]
 func HandlerGetPartition(c *gin.Context) {
-	client := slurmctl.Default()
+	client := ctlClient(c)
 	if client == nil {
-		c.JSON(http.StatusInternalServerError, response.Response{Detail: "slurmclt client not initialized"})
 		return
 	}
 
@@ -310,3 +396,102 @@ func HandlerGetPartition(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response.Response{Results: part})
 }
+
+// NodeStateRequest 节点状态修改请求体.
+type NodeStateRequest struct {
+	Nodes     string `json:"nodes"`                       // hostlist 表达式, 仅批量接口使用
+	State     string `json:"state" binding:"required"`    // DRAIN, RESUME, DOWN, UNDRAIN, REBOOT
+	Reason    string `json:"reason" binding:"required"`   // 原因(必填)
+	Operator  string `json:"operator" binding:"required"` // 操作人
+	ASAP      bool   `json:"asap"`                        // 仅 REBOOT 生效, 是否尽快重启
+	NextState string `json:"next_state"`                  // 仅 REBOOT 生效, 重启完成后状态(RESUME 或 DOWN)
+}
+
+// HandlerUpdateNodeState 修改指定节点的状态。
+//
+// @Summary 修改节点状态
+// @Description 通过 scontrol update/reboot 修改节点状态, 支持 DRAIN, RESUME, DOWN, UNDRAIN, REBOOT; reason 与 operator 必填
+// @Tags slurm-scheduling, node
+// @Accept json
+// @Produce json
+// @Param name path string true "节点名称"
+// @Param body body NodeStateRequest true "状态修改请求"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/scheduling/node/:name/state [post]
+func HandlerUpdateNodeState(c *gin.Context) {
+	var req NodeStateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: fmt.Sprintf("invalid json: %s", err)})
+		return
+	}
+	req.Nodes = strings.TrimSpace(c.Param("name"))
+	updateNodeState(c, req)
+}
+
+// HandlerUpdateNodesState 批量修改节点状态。
+//
+// @Summary 批量修改节点状态
+// @Description 请求体中 nodes 为 hostlist 表达式(如 cn[1-10]), 其余同 /node/:name/state
+// @Tags slurm-scheduling, node
+// @Accept json
+// @Produce json
+// @Param body body NodeStateRequest true "状态修改请求"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/scheduling/node/state [post]
+func HandlerUpdateNodesState(c *gin.Context) {
+	var req NodeStateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: fmt.Sprintf("invalid json: %s", err)})
+		return
+	}
+	updateNodeState(c, req)
+}
+
+func updateNodeState(c *gin.Context, req NodeStateRequest) {
+	client := ctlClient(c)
+	if client == nil {
+		return
+	}
+	if strings.TrimSpace(req.Nodes) == "" {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: "missing nodes parameter"})
+		return
+	}
+	if _, err := hostlist.Expand(strings.TrimSpace(req.Nodes)); err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: fmt.Sprintf("invalid nodes parameter: %s", err)})
+		return
+	}
+
+	var err error
+	switch strings.ToUpper(strings.TrimSpace(req.State)) {
+	case "REBOOT":
+		err = client.RebootNodes(c.Request.Context(), req.Nodes, req.ASAP, req.NextState, req.Reason, req.Operator)
+	case slurmctl.NodeStateDrain, slurmctl.NodeStateResume, slurmctl.NodeStateDown, slurmctl.NodeStateUndrain:
+		err = client.UpdateNodeState(c.Request.Context(), req.Nodes, req.State, req.Reason, req.Operator)
+	default:
+		c.JSON(http.StatusBadRequest, response.Response{Detail: fmt.Sprintf("unsupported state: %s", req.State)})
+		return
+	}
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, slurmctl.ErrInvalidArgument) {
+			code = http.StatusBadRequest
+		}
+		c.JSON(code, response.Response{Detail: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{Results: req})
+}
+
+// expandJobNodes 填充每个作业展开后的节点列表.
+func expandJobNodes(jobs slurmctlmodels.Jobs) {
+	for i := range jobs {
+		jobs[i].Nodes, _ = hostlist.Expand(jobs[i].Nodelist)
+	}
+}
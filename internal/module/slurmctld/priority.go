@@ -0,0 +1,81 @@
+package slurmctld
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"solid/internal/pkg/common/response"
+)
+
+// HandlerGetJobPriority 获取排队作业的优先级构成。
+//
+// @Summary 获取作业优先级构成
+// @Description 通过 sprio 获取排队作业的各优先级因子(age, fairshare, jobsize, partition, qos, tres), 包含加权值与归一化值; 作业提交到多个分区时每个分区一条记录
+// @Tags slurm-scheduling, job
+// @Produce json
+// @Param id path string true "作业ID"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/scheduling/job/:id/priority [get]
+func HandlerGetJobPriority(c *gin.Context) {
+	client := ctlClient(c)
+	if client == nil {
+		return
+	}
+
+	jobid := strings.TrimSpace(c.Param("id"))
+	if jobid == "" {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: "missing jobid parameter"})
+		return
+	}
+
+	rows, err := client.GetJobPriority(c.Request.Context(), jobid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+	if len(rows) == 0 {
+		c.JSON(http.StatusNotFound, response.Response{Detail: fmt.Sprintf("job %s is not pending", jobid)})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{Count: len(rows), Results: rows})
+}
+
+// HandlerGetFairshare 获取公平共享树。
+//
+// @Summary 获取公平共享信息
+// @Description 通过 sshare -a -l -P 获取各账户与用户的份额、使用量与公平共享因子, 按账户层级返回树结构
+// @Tags slurm-scheduling, fairshare
+// @Produce json
+// @Param account query string false "账户名称, 仅返回以该账户为根的子树"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/fairshare [get]
+func HandlerGetFairshare(c *gin.Context) {
+	client := ctlClient(c)
+	if client == nil {
+		return
+	}
+
+	account := strings.TrimSpace(c.Query("account"))
+	tree, err := client.GetFairshare(c.Request.Context(), account)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+	if tree == nil {
+		c.JSON(http.StatusNotFound, response.Response{Detail: fmt.Sprintf("account %s not found", account)})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{Results: tree})
+}
@@ -0,0 +1,289 @@
+package slurmctld
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"solid/internal/pkg/client/slurmctl"
+	slurmdbc "solid/internal/pkg/client/slurmdb"
+	"solid/internal/pkg/common/response"
+	"solid/internal/pkg/model"
+)
+
+// ReservationRequest 创建或修改预约的请求体.
+type ReservationRequest struct {
+	slurmctl.ReservationSpec
+	Operator string `json:"operator" binding:"required"` // 操作人
+}
+
+// HandlerGetAllReservations 获取所有预约（可分页）。
+//
+// @Summary 获取预约列表
+// @Description 通过 scontrol show reservation 获取所有预约信息；支持分页返回
+// @Tags slurm-scheduling, reservation
+// @Produce json
+// @Param paging query bool false "是否开启分页" default(true)
+// @Param page query int false "页号(从1开始)" example("1") default(1) minimum(1)
+// @Param page_size query int false "每页数量" example("20") default(20) minimum(1)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/scheduling/reservation/all?paging=xxx&page=xxx&page_size=xxx [get]
+func HandlerGetAllReservations(c *gin.Context) {
+	client := ctlClient(c)
+	if client == nil {
+		return
+	}
+
+	resvs, err := client.GetReservations(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+	total := len(resvs)
+
+	// 分页开关，默认 true
+	var pagingFlag struct {
+		Paging *bool `form:"paging"`
+	}
+	_ = c.ShouldBindQuery(&pagingFlag)
+	paging := true
+	if pagingFlag.Paging != nil {
+		paging = *pagingFlag.Paging
+	}
+
+	if paging {
+		var pq model.PagingQuery
+		_ = c.ShouldBindQuery(&pq)
+		pq.SetDefaults(1, 20, 100)
+		if err := pq.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid paging parameters"})
+			return
+		}
+		start := pq.Offset()
+		if start > total {
+			start = total
+		}
+		end := start + pq.Limit()
+		if end > total {
+			end = total
+		}
+		prevURL, nextURL := response.BuildPageLinks(c.Request.URL, pq.Page, pq.PageSize, total)
+		c.JSON(http.StatusOK, response.Response{Count: total, Previous: prevURL, Next: nextURL, Results: resvs[start:end]})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{Count: total, Results: resvs})
+}
+
+// HandlerGetReservation 获取指定名称的预约。
+//
+// @Summary 获取预约详情
+// @Description 通过 scontrol show reservation <name> 获取预约信息; 名称不合法时返回 400
+// @Tags slurm-scheduling, reservation
+// @Produce json
+// @Param name query string true "预约名称"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/scheduling/reservation?name=xxx [get]
+func HandlerGetReservation(c *gin.Context) {
+	client := ctlClient(c)
+	if client == nil {
+		return
+	}
+
+	name := strings.TrimSpace(c.Query("name"))
+	if name == "" {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: "missing name parameter"})
+		return
+	}
+
+	resv, err := client.GetReservation(c.Request.Context(), name)
+	if err != nil {
+		c.JSON(reservationErrorStatus(err), response.Response{Detail: err.Error()})
+		return
+	}
+	if resv == nil {
+		c.JSON(http.StatusNotFound, response.Response{Detail: fmt.Sprintf("reservation %s not found", name)})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{Results: resv})
+}
+
+// HandlerCreateReservation 创建预约。
+//
+// @Summary 创建预约
+// @Description 通过 scontrol create reservation 创建预约; start_time, duration/end_time, nodes/node_count, users/accounts 与 operator 必填; 节点按 sinfo、用户与账户按 slurmdb 检查是否存在, 不存在时返回 400
+// @Tags slurm-scheduling, reservation
+// @Accept json
+// @Produce json
+// @Param body body ReservationRequest true "预约参数"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/scheduling/reservation [post]
+func HandlerCreateReservation(c *gin.Context) {
+	client := ctlClient(c)
+	if client == nil {
+		return
+	}
+
+	var req ReservationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: fmt.Sprintf("invalid json: %s", err)})
+		return
+	}
+	if err := req.ValidateCreate(); err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: err.Error()})
+		return
+	}
+	if err := checkReservationEntities(c.Request.Context(), c.Query("cluster"), req.ReservationSpec); err != nil {
+		c.JSON(reservationErrorStatus(err), response.Response{Detail: err.Error()})
+		return
+	}
+
+	name, err := client.CreateReservation(c.Request.Context(), req.ReservationSpec, req.Operator)
+	if err != nil {
+		c.JSON(reservationErrorStatus(err), response.Response{Detail: err.Error()})
+		return
+	}
+	req.Name = name
+
+	c.JSON(http.StatusCreated, response.Response{Results: req})
+}
+
+// HandlerUpdateReservation 修改预约。
+//
+// @Summary 修改预约
+// @Description 通过 scontrol update ReservationName=<name> 修改预约; flags 中 "-" 前缀表示清除该标志; 节点、用户与账户的检查同创建预约
+// @Tags slurm-scheduling, reservation
+// @Accept json
+// @Produce json
+// @Param name path string true "预约名称"
+// @Param body body ReservationRequest true "预约参数"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/scheduling/reservation/:name [put]
+func HandlerUpdateReservation(c *gin.Context) {
+	client := ctlClient(c)
+	if client == nil {
+		return
+	}
+
+	var req ReservationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: fmt.Sprintf("invalid json: %s", err)})
+		return
+	}
+	req.Name = strings.TrimSpace(c.Param("name"))
+	if err := req.ValidateUpdate(); err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: err.Error()})
+		return
+	}
+	if err := checkReservationEntities(c.Request.Context(), c.Query("cluster"), req.ReservationSpec); err != nil {
+		c.JSON(reservationErrorStatus(err), response.Response{Detail: err.Error()})
+		return
+	}
+
+	if err := client.UpdateReservation(c.Request.Context(), req.ReservationSpec, req.Operator); err != nil {
+		c.JSON(reservationErrorStatus(err), response.Response{Detail: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{Results: req})
+}
+
+// HandlerDeleteReservation 删除预约。
+//
+// @Summary 删除预约
+// @Description 通过 scontrol delete ReservationName=<name> 删除预约; 名称不合法时返回 400, 预约不存在时返回 404
+// @Tags slurm-scheduling, reservation
+// @Produce json
+// @Param name path string true "预约名称"
+// @Param operator query string true "操作人"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/scheduling/reservation/:name [delete]
+func HandlerDeleteReservation(c *gin.Context) {
+	client := ctlClient(c)
+	if client == nil {
+		return
+	}
+
+	name := strings.TrimSpace(c.Param("name"))
+	operator := strings.TrimSpace(c.Query("operator"))
+	if operator == "" {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: "missing operator parameter"})
+		return
+	}
+
+	if err := client.DeleteReservation(c.Request.Context(), name, operator); err != nil {
+		c.JSON(reservationErrorStatus(err), response.Response{Detail: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{Results: gin.H{"name": name, "operator": operator}})
+}
+
+// checkReservationEntities 检查预约中的用户与账户(去掉 "-" 前缀)在 slurmdb 中存在, 存在未知项时返回 slurmctl.ErrInvalidArgument.
+// slurmdb 客户端未初始化时不检查, 由 scontrol 拒绝未知的用户与账户. 节点由 slurmctl 客户端按 sinfo 检查.
+func checkReservationEntities(ctx context.Context, cluster string, spec slurmctl.ReservationSpec) error {
+	db := slurmdbc.Default()
+	if db == nil {
+		return nil
+	}
+	db, err := db.WithCluster(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	for _, t := range []struct {
+		field   string
+		names   []string
+		missing func(context.Context, []string) ([]string, error)
+	}{
+		{"users", spec.Users, db.MissingUsers},
+		{"accounts", spec.Accounts, db.MissingAccounts},
+	} {
+		names := make([]string, 0, len(t.names))
+		for _, n := range t.names {
+			if n = strings.TrimPrefix(strings.TrimSpace(n), "-"); n != "" {
+				names = append(names, n)
+			}
+		}
+		missing, err := t.missing(ctx, names)
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("%w: unknown %s: %s", slurmctl.ErrInvalidArgument, t.field, strings.Join(missing, ","))
+		}
+	}
+	return nil
+}
+
+// reservationErrorStatus 参数或引用对象不合法时返回 400, 预约不存在时返回 404, 否则返回 500.
+func reservationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, slurmctl.ErrInvalidArgument):
+		return http.StatusBadRequest
+	case errors.Is(err, slurmctl.ErrNotFound):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
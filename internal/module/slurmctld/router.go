@@ -9,11 +9,29 @@ type Router struct{}
 func (rt Router) Register(r *gin.Engine) {
 	v1 := r.Group("/api/v1/slurm/scheduling")
 	{
-		v1.GET("/node/all", HandlerGetAllNodes)           // GET /api/v1/slurm/scheduling/node/all?paging=xxx&page=xxx&page_size=xxx
-		v1.GET("/job/all", HandlerGetAllJobs)             // GET /api/v1/slurm/scheduling/job/all?paging=xxx&page=xxx&page_size=xxx
-		v1.GET("/job", HandlerGetJob)                     // ✅GET /api/v1/slurm/scheduling/job?jobid=xxx
-		v1.GET("/job/steps", HandlerGetStepsOfJob)        // GET /api/v1/slurm/scheduling/job/steps?jobid=xxx
-		v1.GET("/partition/all", HandlerGetAllPartitions) // ✅GET /api/v1/slurm/scheduling/partition/all?paging=xxx&page=xxx&page_size=xxx
-		v1.GET("/partition", HandlerGetPartition)         // ✅GET // GET /api/v1/slurm/scheduling/partition?name=xxx
+		v1.GET("/node/all", HandlerGetAllNodes)                   // GET /api/v1/slurm/scheduling/node/all?paging=xxx&page=xxx&page_size=xxx
+		v1.POST("/node/state", HandlerUpdateNodesState)           // POST /api/v1/slurm/scheduling/node/state
+		v1.POST("/node/:name/state", HandlerUpdateNodeState)      // POST /api/v1/slurm/scheduling/node/:name/state
+		v1.GET("/job/all", HandlerGetAllJobs)                     // GET /api/v1/slurm/scheduling/job/all?paging=xxx&page=xxx&page_size=xxx
+		v1.GET("/job", HandlerGetJob)                             // ✅GET /api/v1/slurm/scheduling/job?jobid=xxx
+		v1.GET("/job/:id/priority", HandlerGetJobPriority)        // GET /api/v1/slurm/scheduling/job/:id/priority
+		v1.GET("/job/:id/diagnosis", HandlerDiagnosePendingJob)   // GET /api/v1/slurm/scheduling/job/:id/diagnosis
+		v1.GET("/job/:id/array", HandlerGetArraySummary)          // GET /api/v1/slurm/scheduling/job/:id/array
+		v1.GET("/job/:id/array/tasks", HandlerGetArrayTasks)      // GET /api/v1/slurm/scheduling/job/:id/array/tasks?page=xxx&page_size=xxx
+		v1.GET("/job/:id/het", HandlerGetHetJob)                  // GET /api/v1/slurm/scheduling/job/:id/het
+		v1.GET("/job/steps", HandlerGetStepsOfJob)                // GET /api/v1/slurm/scheduling/job/steps?jobid=xxx
+		v1.GET("/partition/all", HandlerGetAllPartitions)         // ✅GET /api/v1/slurm/scheduling/partition/all?paging=xxx&page=xxx&page_size=xxx
+		v1.GET("/events", HandlerStreamEvents)                    // GET /api/v1/slurm/scheduling/events?user=xxx&account=xxx&partition=xxx
+		v1.GET("/diag", HandlerGetDiag)                           // GET /api/v1/slurm/scheduling/diag
+		v1.POST("/diag/reset", HandlerResetDiag)                  // POST /api/v1/slurm/scheduling/diag/reset
+		v1.GET("/cache/stats", HandlerGetCacheStats)              // GET /api/v1/slurm/scheduling/cache/stats
+		v1.GET("/partition", HandlerGetPartition)                 // ✅GET // GET /api/v1/slurm/scheduling/partition?name=xxx
+		v1.GET("/reservation/all", HandlerGetAllReservations)     // GET /api/v1/slurm/scheduling/reservation/all?paging=xxx&page=xxx&page_size=xxx
+		v1.GET("/reservation", HandlerGetReservation)             // GET /api/v1/slurm/scheduling/reservation?name=xxx
+		v1.POST("/reservation", HandlerCreateReservation)         // POST /api/v1/slurm/scheduling/reservation
+		v1.PUT("/reservation/:name", HandlerUpdateReservation)    // PUT /api/v1/slurm/scheduling/reservation/:name
+		v1.DELETE("/reservation/:name", HandlerDeleteReservation) // DELETE /api/v1/slurm/scheduling/reservation/:name?operator=xxx
 	}
+
+	r.GET("/api/v1/slurm/fairshare", HandlerGetFairshare) // GET /api/v1/slurm/fairshare?account=xxx
 }
@@ -0,0 +1,78 @@
+package slurmctld
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"solid/internal/pkg/client/slurmctl"
+	slurmctlmodels "solid/internal/pkg/client/slurmctl/models"
+	"solid/internal/pkg/common/response"
+)
+
+// getSnapshot 从快照缓存中获取集群快照, 并在响应头中写入快照年龄与时间.
+// 第二个返回值表示是否启用了快照缓存; 启用但获取失败时已写入错误响应, 返回的快照为 nil.
+// 请求参数 fresh=true 时绕过缓存同步刷新; 指定了非默认集群时不使用缓存.
+func getSnapshot(c *gin.Context) (*slurmctl.Snapshot, bool) {
+	cache := slurmctl.DefaultCache()
+	if cache == nil || !defaultCluster(c) {
+		return nil, false
+	}
+	snap, err := cache.Get(c.Query("fresh") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return nil, true
+	}
+	c.Header("X-Snapshot-Age", strconv.FormatFloat(snap.Age().Seconds(), 'f', 3, 64))
+	c.Header("X-Snapshot-Time", snap.Time.Format(time.RFC3339))
+	return snap, true
+}
+
+// filterNodesByPartition 返回属于 partitions(逗号分隔) 中任一分区的节点, 节点的分区列表只保留匹配项,
+// 与 sinfo -p 的输出一致. partitions 为空时原样返回.
+func filterNodesByPartition(nodes slurmctlmodels.Nodes, partitions string) slurmctlmodels.Nodes {
+	if strings.TrimSpace(partitions) == "" {
+		return nodes
+	}
+	want := make(map[string]struct{})
+	for _, p := range strings.Split(partitions, ",") {
+		want[strings.TrimSpace(p)] = struct{}{}
+	}
+	out := make(slurmctlmodels.Nodes)
+	for name, n := range nodes {
+		parts := make([]string, 0)
+		for _, p := range n.Partition {
+			if _, ok := want[strings.TrimSuffix(p, "*")]; ok {
+				parts = append(parts, p)
+			}
+		}
+		if len(parts) == 0 {
+			continue
+		}
+		cp := *n
+		cp.Partition = parts
+		out[name] = &cp
+	}
+	return out
+}
+
+// HandlerGetCacheStats 获取快照缓存统计信息。
+//
+// @Summary 获取快照缓存统计
+// @Description 返回快照缓存的命中次数、命中率、刷新次数与当前快照年龄
+// @Tags slurm-scheduling, cache
+// @Produce json
+// @Success 200 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/scheduling/cache/stats [get]
+func HandlerGetCacheStats(c *gin.Context) {
+	cache := slurmctl.DefaultCache()
+	if cache == nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: "snapshot cache not enabled"})
+		return
+	}
+	c.JSON(http.StatusOK, response.Response{Results: cache.Stats()})
+}
@@ -0,0 +1,634 @@
+package slurmdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+
+	"solid/internal/pkg/client/sacctmgr"
+	slurmdbc "solid/internal/pkg/client/slurmdb"
+	"solid/internal/pkg/common/response"
+)
+
+// AccountRequest 创建或修改账户的请求体.
+type AccountRequest struct {
+	sacctmgr.AccountSpec
+	Operator string `json:"operator" binding:"required"` // 操作人
+}
+
+// UserRequest 创建或修改用户的请求体.
+type UserRequest struct {
+	sacctmgr.UserSpec
+	Operator string `json:"operator" binding:"required"` // 操作人
+}
+
+// AssociationRequest 创建或修改关联的请求体.
+type AssociationRequest struct {
+	sacctmgr.AssociationSpec
+	Operator string `json:"operator" binding:"required"` // 操作人
+}
+
+// QosRequest 创建或修改 QoS 的请求体.
+type QosRequest struct {
+	sacctmgr.QosSpec
+	Operator string `json:"operator" binding:"required"` // 操作人
+}
+
+// CoordinatorRequest 添加协调员的请求体.
+type CoordinatorRequest struct {
+	Account  string   `json:"account" binding:"required"`  // 账户
+	Users    []string `json:"users" binding:"required"`    // 用户
+	Operator string   `json:"operator" binding:"required"` // 操作人
+}
+
+// adminClient 返回 sacctmgr 客户端, dry_run=true 时返回只生成命令的副本; 客户端未初始化时写入错误响应并返回 nil.
+func adminClient(c *gin.Context) *sacctmgr.Client {
+	client := sacctmgr.Default()
+	if client == nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: "sacctmgr client not initialized"})
+		return nil
+	}
+	if c.Query("dry_run") == "true" {
+		return client.DryRun()
+	}
+	return client
+}
+
+// clusterAdminClient 返回操作 ?cluster= 指定集群的 sacctmgr 客户端, 未指定时为配置的默认集群; 出错时写入响应并返回 nil.
+func clusterAdminClient(c *gin.Context) *sacctmgr.Client {
+	admin := adminClient(c)
+	if admin == nil {
+		return nil
+	}
+	client := clusterClient(c)
+	if client == nil {
+		return nil
+	}
+	return admin.WithCluster(client.ClusterName)
+}
+
+// bindAdminJSON 解析请求体, 失败时写入 400 响应.
+func bindAdminJSON(c *gin.Context, req any) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: fmt.Sprintf("invalid json: %s", err)})
+		return false
+	}
+	return true
+}
+
+// queryOperator 读取 DELETE 请求的 operator 参数, 缺失时写入 400 响应.
+func queryOperator(c *gin.Context) (string, bool) {
+	operator := strings.TrimSpace(c.Query("operator"))
+	if operator == "" {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: "missing operator parameter"})
+		return "", false
+	}
+	return operator, true
+}
+
+// respondAdmin 写入 sacctmgr 调用结果; 参数错误返回 400, 操作受保护账户返回 403, 波及其他关联返回 409, 执行失败返回 500, dry-run 总是返回 200.
+func respondAdmin(c *gin.Context, status int, res *sacctmgr.Result, err error) {
+	if err != nil {
+		code := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, sacctmgr.ErrInvalidSpec):
+			code = http.StatusBadRequest
+		case errors.Is(err, sacctmgr.ErrProtected):
+			code = http.StatusForbidden
+		case errors.Is(err, sacctmgr.ErrConflict):
+			code = http.StatusConflict
+		}
+		c.JSON(code, response.Response{Detail: err.Error()})
+		return
+	}
+	if res.DryRun {
+		status = http.StatusOK
+	}
+	c.JSON(status, response.Response{Results: res})
+}
+
+// HandlerAddAccount 创建账户。
+//
+// @Summary 创建账户
+// @Description 通过 sacctmgr -i -P add account 创建账户; name 与 operator 必填, dry_run=true 时只返回将要执行的命令
+// @Tags slurm-accounting, admin
+// @Accept json
+// @Produce json
+// @Param dry_run query bool false "只生成命令不执行" default(false)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Param body body AccountRequest true "账户参数"
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/admin/account [post]
+func HandlerAddAccount(c *gin.Context) {
+	client := clusterAdminClient(c)
+	if client == nil {
+		return
+	}
+	var req AccountRequest
+	if !bindAdminJSON(c, &req) {
+		return
+	}
+	res, err := client.AddAccount(c.Request.Context(), req.AccountSpec, req.Operator)
+	respondAdmin(c, http.StatusCreated, res, err)
+}
+
+// HandlerModifyAccount 修改账户。
+//
+// @Summary 修改账户
+// @Description 通过 sacctmgr -i -P modify account where Name=<name> set ... 修改账户属性与账户关联
+// @Tags slurm-accounting, admin
+// @Accept json
+// @Produce json
+// @Param name path string true "账户名称"
+// @Param dry_run query bool false "只生成命令不执行" default(false)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Param body body AccountRequest true "账户参数"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/admin/account/:name [put]
+func HandlerModifyAccount(c *gin.Context) {
+	client := clusterAdminClient(c)
+	if client == nil {
+		return
+	}
+	var req AccountRequest
+	if !bindAdminJSON(c, &req) {
+		return
+	}
+	req.Name = strings.TrimSpace(c.Param("name"))
+	res, err := client.ModifyAccount(c.Request.Context(), req.AccountSpec, req.Operator)
+	respondAdmin(c, http.StatusOK, res, err)
+}
+
+// HandlerDeleteAccount 删除账户。
+//
+// @Summary 删除账户
+// @Description 通过 sacctmgr -i -P delete account where Name=<name> 删除账户; root 与配置中的受保护账户不能删除
+// @Tags slurm-accounting, admin
+// @Produce json
+// @Param name path string true "账户名称"
+// @Param operator query string true "操作人"
+// @Param dry_run query bool false "只生成命令不执行" default(false)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/admin/account/:name [delete]
+func HandlerDeleteAccount(c *gin.Context) {
+	client := clusterAdminClient(c)
+	if client == nil {
+		return
+	}
+	operator, ok := queryOperator(c)
+	if !ok {
+		return
+	}
+	res, err := client.DeleteAccount(c.Request.Context(), c.Param("name"), operator)
+	respondAdmin(c, http.StatusOK, res, err)
+}
+
+// HandlerAddUser 创建用户。
+//
+// @Summary 创建用户
+// @Description 通过 sacctmgr -i -P add user 创建用户及其在 account(与 partition)上的关联; name、account 与 operator 必填
+// @Tags slurm-accounting, admin
+// @Accept json
+// @Produce json
+// @Param dry_run query bool false "只生成命令不执行" default(false)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Param body body UserRequest true "用户参数"
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/admin/user [post]
+func HandlerAddUser(c *gin.Context) {
+	client := clusterAdminClient(c)
+	if client == nil {
+		return
+	}
+	var req UserRequest
+	if !bindAdminJSON(c, &req) {
+		return
+	}
+	res, err := client.AddUser(c.Request.Context(), req.UserSpec, req.Operator)
+	respondAdmin(c, http.StatusCreated, res, err)
+}
+
+// HandlerModifyUser 修改用户。
+//
+// @Summary 修改用户
+// @Description 通过 sacctmgr -i -P modify user where Name=<name> set ... 修改用户; account/partition 非空时只修改匹配的关联
+// @Tags slurm-accounting, admin
+// @Accept json
+// @Produce json
+// @Param name path string true "用户名"
+// @Param dry_run query bool false "只生成命令不执行" default(false)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Param body body UserRequest true "用户参数"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/admin/user/:name [put]
+func HandlerModifyUser(c *gin.Context) {
+	client := clusterAdminClient(c)
+	if client == nil {
+		return
+	}
+	var req UserRequest
+	if !bindAdminJSON(c, &req) {
+		return
+	}
+	req.Name = strings.TrimSpace(c.Param("name"))
+	res, err := client.ModifyUser(c.Request.Context(), req.UserSpec, req.Operator)
+	respondAdmin(c, http.StatusOK, res, err)
+}
+
+// HandlerDeleteUser 删除用户。
+//
+// @Summary 删除用户
+// @Description 通过 sacctmgr -i -P delete user where Name=<name> 删除用户及其全部关联
+// @Tags slurm-accounting, admin
+// @Produce json
+// @Param name path string true "用户名"
+// @Param operator query string true "操作人"
+// @Param dry_run query bool false "只生成命令不执行" default(false)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/admin/user/:name [delete]
+func HandlerDeleteUser(c *gin.Context) {
+	client := clusterAdminClient(c)
+	if client == nil {
+		return
+	}
+	operator, ok := queryOperator(c)
+	if !ok {
+		return
+	}
+	res, err := client.DeleteUser(c.Request.Context(), c.Param("name"), operator)
+	respondAdmin(c, http.StatusOK, res, err)
+}
+
+// HandlerAddAssociation 添加用户关联。
+//
+// @Summary 添加关联
+// @Description 通过 sacctmgr -i -P add user <user> Account=<account> Partition=<partition> 为已有用户添加关联
+// @Tags slurm-accounting, admin
+// @Accept json
+// @Produce json
+// @Param dry_run query bool false "只生成命令不执行" default(false)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Param body body AssociationRequest true "关联参数"
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/admin/association [post]
+func HandlerAddAssociation(c *gin.Context) {
+	client := clusterAdminClient(c)
+	if client == nil {
+		return
+	}
+	var req AssociationRequest
+	if !bindAdminJSON(c, &req) {
+		return
+	}
+	res, err := client.AddAssociation(c.Request.Context(), req.AssociationSpec, req.Operator)
+	respondAdmin(c, http.StatusCreated, res, err)
+}
+
+// HandlerModifyAssociation 修改关联。
+//
+// @Summary 修改关联
+// @Description 修改关联的 fairshare、QoS 与限制; user 为空时修改账户自身的关联; partition 为空而该用户在账户下有分区关联时返回 409
+// @Tags slurm-accounting, admin
+// @Accept json
+// @Produce json
+// @Param dry_run query bool false "只生成命令不执行" default(false)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Param body body AssociationRequest true "关联参数"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/admin/association [put]
+func HandlerModifyAssociation(c *gin.Context) {
+	client := clusterAdminClient(c)
+	if client == nil {
+		return
+	}
+	var req AssociationRequest
+	if !bindAdminJSON(c, &req) {
+		return
+	}
+	if !checkPartitionAssocs(c, req.AssociationSpec, "modify") {
+		return
+	}
+	res, err := client.ModifyAssociation(c.Request.Context(), req.AssociationSpec, req.Operator)
+	respondAdmin(c, http.StatusOK, res, err)
+}
+
+// HandlerDeleteAssociation 删除用户关联。
+//
+// @Summary 删除关联
+// @Description 通过 sacctmgr -i -P delete user where Name=<user> Account=<account> Partition=<partition> 删除关联, 用户本身保留; partition 为空而该用户在账户下有分区关联时返回 409
+// @Tags slurm-accounting, admin
+// @Produce json
+// @Param account query string true "账户"
+// @Param user query string true "用户"
+// @Param partition query string false "分区"
+// @Param operator query string true "操作人"
+// @Param dry_run query bool false "只生成命令不执行" default(false)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/admin/association [delete]
+func HandlerDeleteAssociation(c *gin.Context) {
+	client := clusterAdminClient(c)
+	if client == nil {
+		return
+	}
+	operator, ok := queryOperator(c)
+	if !ok {
+		return
+	}
+	spec := sacctmgr.AssociationSpec{Account: c.Query("account"), User: c.Query("user"), Partition: c.Query("partition")}
+	if !checkPartitionAssocs(c, spec, "delete") {
+		return
+	}
+	res, err := client.DeleteAssociation(c.Request.Context(), spec, operator)
+	respondAdmin(c, http.StatusOK, res, err)
+}
+
+// checkPartitionAssocs 修改或删除用户不区分分区的关联前, 检查该用户在账户下没有分区关联: sacctmgr 的条件无法排除分区关联,
+// 操作会波及它们. 存在分区关联时返回 409; 出错时写入响应并返回 false.
+func checkPartitionAssocs(c *gin.Context, spec sacctmgr.AssociationSpec, verb string) bool {
+	account, user := strings.TrimSpace(spec.Account), strings.TrimSpace(spec.User)
+	if account == "" || user == "" || strings.TrimSpace(spec.Partition) != "" {
+		return true
+	}
+	client := clusterClient(c)
+	if client == nil {
+		return false
+	}
+	parts, err := client.GetAssociationPartitions(c.Request.Context(), account, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return false
+	}
+	if len(parts) > 0 {
+		err = fmt.Errorf("%w: cannot %s association of user %s in account %s without affecting its partition associations %s",
+			sacctmgr.ErrConflict, verb, user, account, strings.Join(parts, ","))
+		respondAdmin(c, http.StatusOK, nil, err)
+		return false
+	}
+	return true
+}
+
+// HandlerAddQos 创建 QoS。
+//
+// @Summary 创建 QoS
+// @Description 通过 sacctmgr -i -P add qos 创建 QoS; name 与 operator 必填
+// @Tags slurm-accounting, admin
+// @Accept json
+// @Produce json
+// @Param dry_run query bool false "只生成命令不执行" default(false)
+// @Param body body QosRequest true "QoS 参数"
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/admin/qos [post]
+func HandlerAddQos(c *gin.Context) {
+	client := adminClient(c)
+	if client == nil {
+		return
+	}
+	var req QosRequest
+	if !bindAdminJSON(c, &req) {
+		return
+	}
+	res, err := client.AddQos(c.Request.Context(), req.QosSpec, req.Operator)
+	respondAdmin(c, http.StatusCreated, res, err)
+}
+
+// HandlerModifyQos 修改 QoS。
+//
+// @Summary 修改 QoS
+// @Description 通过 sacctmgr -i -P modify qos where Name=<name> set ... 修改 QoS
+// @Tags slurm-accounting, admin
+// @Accept json
+// @Produce json
+// @Param name path string true "QoS 名称"
+// @Param dry_run query bool false "只生成命令不执行" default(false)
+// @Param body body QosRequest true "QoS 参数"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/admin/qos/:name [put]
+func HandlerModifyQos(c *gin.Context) {
+	client := adminClient(c)
+	if client == nil {
+		return
+	}
+	var req QosRequest
+	if !bindAdminJSON(c, &req) {
+		return
+	}
+	req.Name = strings.TrimSpace(c.Param("name"))
+	res, err := client.ModifyQos(c.Request.Context(), req.QosSpec, req.Operator)
+	respondAdmin(c, http.StatusOK, res, err)
+}
+
+// HandlerDeleteQos 删除 QoS。
+//
+// @Summary 删除 QoS
+// @Description 通过 sacctmgr -i -P delete qos where Name=<name> 删除 QoS
+// @Tags slurm-accounting, admin
+// @Produce json
+// @Param name path string true "QoS 名称"
+// @Param operator query string true "操作人"
+// @Param dry_run query bool false "只生成命令不执行" default(false)
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/admin/qos/:name [delete]
+func HandlerDeleteQos(c *gin.Context) {
+	client := adminClient(c)
+	if client == nil {
+		return
+	}
+	operator, ok := queryOperator(c)
+	if !ok {
+		return
+	}
+	res, err := client.DeleteQos(c.Request.Context(), c.Param("name"), operator)
+	respondAdmin(c, http.StatusOK, res, err)
+}
+
+// HandlerAddCoordinator 添加账户协调员。
+//
+// @Summary 添加协调员
+// @Description 通过 sacctmgr -i -P add coordinator Account=<account> Names=<users> 添加账户协调员
+// @Tags slurm-accounting, admin
+// @Accept json
+// @Produce json
+// @Param dry_run query bool false "只生成命令不执行" default(false)
+// @Param body body CoordinatorRequest true "协调员参数"
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/admin/coordinator [post]
+func HandlerAddCoordinator(c *gin.Context) {
+	client := adminClient(c)
+	if client == nil {
+		return
+	}
+	var req CoordinatorRequest
+	if !bindAdminJSON(c, &req) {
+		return
+	}
+	res, err := client.AddCoordinator(c.Request.Context(), req.Account, req.Users, req.Operator)
+	respondAdmin(c, http.StatusCreated, res, err)
+}
+
+// HandlerRemoveCoordinator 移除账户协调员。
+//
+// @Summary 移除协调员
+// @Description 通过 sacctmgr -i -P delete coordinator Account=<account> Names=<users> 移除账户协调员
+// @Tags slurm-accounting, admin
+// @Produce json
+// @Param account query string true "账户"
+// @Param users query string true "用户, 逗号分隔"
+// @Param operator query string true "操作人"
+// @Param dry_run query bool false "只生成命令不执行" default(false)
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/admin/coordinator [delete]
+func HandlerRemoveCoordinator(c *gin.Context) {
+	client := adminClient(c)
+	if client == nil {
+		return
+	}
+	operator, ok := queryOperator(c)
+	if !ok {
+		return
+	}
+	res, err := client.RemoveCoordinator(c.Request.Context(), c.Query("account"), splitQuery(c, "users"), operator)
+	respondAdmin(c, http.StatusOK, res, err)
+}
+
+// loadLiveState 从 slurmdb 读取现有的账户/关联树.
+func loadLiveState(ctx context.Context, client *slurmdbc.Client) (*sacctmgr.LiveState, error) {
+	tree, err := client.GetAssocTree(ctx, "root", 0)
+	if err != nil {
+		return nil, err
+	}
+	accts, _, err := client.GetAccounts(ctx, false, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	tres, err := client.GetTresTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	qos, _, err := client.GetQosAll(ctx, false, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return sacctmgr.NewLiveState(tree, accts, tres, qos), nil
+}
+
+// HandlerSyncAssociationTree 按期望状态同步账户/关联树。
+//
+// @Summary 同步账户/关联树
+// @Description 请求体为期望的账户/关联树(Content-Type 为 application/yaml 时按 YAML 解析, 否则按 JSON), 与 <cluster>_assoc_table 中的现有树对比后生成限定在该集群上(Cluster=<cluster>)的 sacctmgr 操作计划;
+// @Description apply=true 时依次执行, 遇到失败即停止. root、配置中的受保护账户与请求体 protected 中的账户及其子树不会被删除;
+// @Description 计划需要修改或删除不区分分区的用户关联而同一用户在该账户下有保留的分区关联时返回 409.
+// @Tags slurm-accounting, admin
+// @Accept json
+// @Accept application/yaml
+// @Produce json
+// @Param apply query bool false "是否执行计划" default(false)
+// @Param operator query string false "操作人, apply=true 时必填"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Param body body sacctmgr.TreeSpec true "期望的账户/关联树"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/admin/sync [post]
+func HandlerSyncAssociationTree(c *gin.Context) {
+	admin := sacctmgr.Default()
+	if admin == nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: "sacctmgr client not initialized"})
+		return
+	}
+	client := clusterClient(c)
+	if client == nil {
+		return
+	}
+	admin = admin.WithCluster(client.ClusterName)
+	apply := c.Query("apply") == "true"
+	operator := strings.TrimSpace(c.Query("operator"))
+	if apply && operator == "" {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: "missing operator parameter"})
+		return
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: fmt.Sprintf("unable to read body: %s", err)})
+		return
+	}
+	var spec sacctmgr.TreeSpec
+	if strings.Contains(c.ContentType(), "yaml") {
+		err = yaml.Unmarshal(body, &spec)
+	} else {
+		err = json.Unmarshal(body, &spec)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: fmt.Sprintf("invalid spec: %s", err)})
+		return
+	}
+
+	live, err := loadLiveState(c.Request.Context(), client)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+	plan, err := admin.PlanSync(spec, live)
+	if err != nil {
+		respondAdmin(c, http.StatusOK, nil, err)
+		return
+	}
+	if apply {
+		if err := admin.ApplySync(c.Request.Context(), plan, operator); err != nil {
+			c.JSON(http.StatusInternalServerError, response.Response{Count: len(plan.Ops), Detail: err.Error(), Results: plan})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, response.Response{Count: len(plan.Ops), Results: plan})
+}
@@ -0,0 +1,66 @@
+package slurmdb
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"solid/internal/pkg/client/slurmctl"
+	slurmdbc "solid/internal/pkg/client/slurmdb"
+	"solid/internal/pkg/common/response"
+)
+
+// clusterClient 返回 ?cluster= 指定集群的 slurmdb 客户端, 未指定时为配置的默认集群; 出错时写入响应并返回 nil.
+func clusterClient(c *gin.Context) *slurmdbc.Client {
+	client := slurmdbc.Default()
+	if client == nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: "slurmdb client not initialized"})
+		return nil
+	}
+	cc, err := client.WithCluster(c.Request.Context(), c.Query("cluster"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, slurmdbc.ErrInvalidCluster):
+			status = http.StatusBadRequest
+		case errors.Is(err, slurmdbc.ErrUnknownCluster):
+			status = http.StatusNotFound
+		}
+		c.JSON(status, response.Response{Detail: err.Error()})
+		return nil
+	}
+	return cc
+}
+
+// HandlerGetClusters 获取 slurmdbd 管理的集群列表。
+//
+// @Summary 获取集群列表
+// @Description 查询 cluster_table 中未删除的集群(配置了 clusters 时只返回其中的集群), 并标记默认集群与是否配置了 slurmctl 后端
+// @Tags slurm-accounting, cluster
+// @Produce json
+// @Success 200 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/cluster/all [get]
+func HandlerGetClusters(c *gin.Context) {
+	client := slurmdbc.Default()
+	if client == nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: "slurmdb client not initialized"})
+		return
+	}
+	clusters, err := client.GetClusters(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+	for i := range clusters {
+		clusters[i].Backend = slurmctl.ForCluster(clusters[i].Name) != nil
+	}
+	c.JSON(http.StatusOK, response.Response{Count: len(clusters), Results: clusters})
+}
+
+// allClusters 是否请求跨集群查询(?cluster=all).
+func allClusters(c *gin.Context) bool {
+	return strings.EqualFold(strings.TrimSpace(c.Query("cluster")), "all")
+}
@@ -0,0 +1,128 @@
+package slurmdb
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	slurmdbc "solid/internal/pkg/client/slurmdb"
+	"solid/internal/pkg/common/jobid"
+	"solid/internal/pkg/common/response"
+)
+
+// HandlerGetJobEfficiency 获取作业的资源使用效率。
+//
+// @Summary 获取作业效率
+// @Description 按 seff 的方式根据作业及其全部作业步计算 CPU 效率(CPU 时间 / 核时)、内存效率(MaxRSS / 分配内存)、GPU 利用率(需要采集 gres/gpuutil)与运行时长占时间限制的比例; id 为数组作业 ID 时返回每个已开始任务的效率
+// @Tags slurm-accounting, job
+// @Produce json
+// @Param id path string true "作业ID, 数组任务格式为 123_7"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/job/:id/efficiency [get]
+func HandlerGetJobEfficiency(c *gin.Context) {
+	client := clusterClient(c)
+	if client == nil {
+		return
+	}
+	id, err := jobid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid jobid parameter"})
+		return
+	}
+
+	rows, err := client.GetJobEfficiency(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, response.Response{Detail: "job not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, response.Response{Count: len(rows), Results: rows})
+}
+
+// HandlerGetLeastEfficientJobs 获取效率最低的已结束作业。
+//
+// @Summary 获取效率最低的作业
+// @Description 在满足过滤条件且最近结束的作业(最多 2000 个)中, 按所选指标升序返回效率最低的作业; 过滤参数与 /job/all 相同
+// @Tags slurm-accounting, job
+// @Produce json
+// @Param user query string false "用户名或 UID, 逗号分隔" example("alice")
+// @Param account query string false "账户, 逗号分隔"
+// @Param partition query string false "分区, 逗号分隔"
+// @Param state query string false "作业状态名称或缩写, 逗号分隔" example("CD")
+// @Param end_after query int false "结束时间下界(Unix 秒)"
+// @Param end_before query int false "结束时间上界(Unix 秒)"
+// @Param sort query string false "排序指标" Enums(cpu, mem, gpu, wall) default(cpu)
+// @Param min_elapsed query int false "最短运行时长(秒)" default(60)
+// @Param limit query int false "返回数量，1-100" minimum(1) maximum(100) default(20)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/job/efficiency [get]
+func HandlerGetLeastEfficientJobs(c *gin.Context) {
+	client := clusterClient(c)
+	if client == nil {
+		return
+	}
+
+	filter, err := parseJobsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: err.Error()})
+		return
+	}
+	if users := splitQuery(c, "user"); len(users) > 0 {
+		uids, status, err := resolveUIDs(c, users)
+		if err != nil {
+			c.JSON(status, response.Response{Detail: err.Error()})
+			return
+		}
+		filter.Users = uids
+	}
+
+	query := slurmdbc.EfficiencyQuery{
+		Filter:     filter,
+		MinElapsed: 60,
+		SortBy:     strings.TrimSpace(c.Query("sort")),
+		Limit:      20,
+	}
+	if v := strings.TrimSpace(c.Query("min_elapsed")); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid min_elapsed parameter"})
+			return
+		}
+		query.MinElapsed = n
+	}
+	if v := strings.TrimSpace(c.Query("limit")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid limit parameter"})
+			return
+		}
+		query.Limit = n
+	}
+	switch query.SortBy {
+	case "", "cpu", "mem", "gpu", "wall":
+	default:
+		c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid sort parameter"})
+		return
+	}
+
+	rows, err := client.GetLeastEfficientJobs(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, response.Response{Count: len(rows), Results: rows})
+}
@@ -0,0 +1,266 @@
+package slurmdb
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	ldapc "solid/internal/pkg/client/ldap"
+	"solid/internal/pkg/client/slurmctl"
+	slurmctlmodels "solid/internal/pkg/client/slurmctl/models"
+	slurmdbc "solid/internal/pkg/client/slurmdb"
+	"solid/internal/pkg/common/hostlist"
+	"solid/internal/pkg/common/response"
+	"solid/internal/pkg/model"
+)
+
+// defaultAvailabilityWindow 可用性统计未指定 start 时的默认窗口.
+const defaultAvailabilityWindow = 30 * 24 * time.Hour
+
+// parseTimeRange 解析可选的 start 与 end 参数(Unix 秒), 未设置的参数为 0.
+func parseTimeRange(c *gin.Context) (start, end int64, err error) {
+	for _, p := range []struct {
+		key string
+		dst *int64
+	}{{"start", &start}, {"end", &end}} {
+		v := strings.TrimSpace(c.Query(p.key))
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("invalid %s parameter", p.key)
+		}
+		*p.dst = n
+	}
+	if start > 0 && end > 0 && end <= start {
+		return 0, 0, fmt.Errorf("end must be after start")
+	}
+	return start, end, nil
+}
+
+// parseNodes 展开 node 参数中的 hostlist 表达式.
+func parseNodes(c *gin.Context) ([]string, error) {
+	expr := strings.TrimSpace(c.Query("node"))
+	if expr == "" {
+		return nil, nil
+	}
+	nodes, err := hostlist.Expand(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid node parameter: %s", err)
+	}
+	return nodes, nil
+}
+
+// resolveReasonUsers 通过 LDAP 将事件的 reason_uid 换算为用户名, uid 0 为 root. 未初始化 LDAP 客户端时跳过.
+func resolveReasonUsers(c *gin.Context, events model.Events) error {
+	uids := make([]uint32, 0)
+	seen := make(map[uint32]struct{})
+	for i := range events {
+		switch uid := events[i].ReasonUID; uid {
+		case model.NoReasonUID:
+		case 0:
+			events[i].ReasonUser = "root"
+		default:
+			if _, ok := seen[uid]; !ok {
+				seen[uid] = struct{}{}
+				uids = append(uids, uid)
+			}
+		}
+	}
+	lcli := ldapc.Default()
+	if len(uids) == 0 || lcli == nil {
+		return nil
+	}
+	names, err := lcli.GetUserNamesByUIDNumber(c.Request.Context(), uids)
+	if err != nil {
+		return err
+	}
+	for i := range events {
+		if name, ok := names[events[i].ReasonUID]; ok {
+			events[i].ReasonUser = name
+		}
+	}
+	return nil
+}
+
+// HandlerGetNodeEvents 获取节点停机历史（分页）。
+//
+// @Summary 获取节点停机历史
+// @Description 从 <cluster>_event_table 查询节点进入 DOWN、DRAIN、FAIL 等状态的记录, 返回状态、原因、设置原因的用户(reason_uid 经 LDAP 换算)、起止时间与持续时长; 按开始时间降序分页返回. failed_jobs=true 时附带期间因该节点失败的作业(failed_node)
+// @Tags slurm-accounting, node
+// @Produce json
+// @Param node query string false "节点 hostlist 表达式" example("cn[1-10]")
+// @Param start query int false "仅返回结束时间不早于该时间或尚未结束的事件(Unix 秒)"
+// @Param end query int false "仅返回开始时间早于该时间的事件(Unix 秒)"
+// @Param failed_jobs query bool false "是否关联因节点故障失败的作业" default(false)
+// @Param page query int false "页码，从 1 开始" minimum(1) default(1)
+// @Param page_size query int false "每页数量，1-100" minimum(1) maximum(100) default(20)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/node/events [get]
+func HandlerGetNodeEvents(c *gin.Context) {
+	client := clusterClient(c)
+	if client == nil {
+		return
+	}
+
+	var pq model.PagingQuery
+	_ = c.ShouldBindQuery(&pq)
+	pq.SetDefaults(1, 20, 100)
+	if err := pq.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid paging parameters"})
+		return
+	}
+	nodes, err := parseNodes(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: err.Error()})
+		return
+	}
+	start, end, err := parseTimeRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	filter := slurmdbc.NodeEventsFilter{Nodes: nodes, Start: start, End: end}
+	rows, total, err := client.GetNodeEvents(ctx, filter, pq.Page, pq.PageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+	if err := resolveReasonUsers(c, rows); err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+	if c.Query("failed_jobs") == "true" {
+		if err := client.AttachFailedJobs(ctx, rows); err != nil {
+			c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+			return
+		}
+	}
+	prevURL, nextURL := response.BuildPageLinks(c.Request.URL, pq.Page, pq.PageSize, int(total))
+	c.JSON(http.StatusOK, response.Response{
+		Count:    int(total),
+		Previous: prevURL,
+		Next:     nextURL,
+		Results:  rows,
+	})
+}
+
+// HandlerGetNodeAvailability 获取节点与分区的可用性统计。
+//
+// @Summary 节点可用性统计
+// @Description 根据 <cluster>_event_table 统计时间窗口内每个节点的停机次数、停机时长、可用率、MTBF 与 MTTR, 并按分区汇总; 分区与节点的对应关系来自该集群的 slurmctl 后端, 未配置后端时不返回分区统计. 未指定 node 与 partition 时统计所有分区的节点
+// @Tags slurm-accounting, node
+// @Produce json
+// @Param node query string false "节点 hostlist 表达式" example("cn[1-10]")
+// @Param partition query string false "分区, 逗号分隔"
+// @Param start query int false "开始时间(Unix 秒), 含; 默认为 end 前 30 天"
+// @Param end query int false "结束时间(Unix 秒), 不含; 默认为当前时间"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/node/availability [get]
+func HandlerGetNodeAvailability(c *gin.Context) {
+	client := clusterClient(c)
+	if client == nil {
+		return
+	}
+	nodes, err := parseNodes(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: err.Error()})
+		return
+	}
+	start, end, err := parseTimeRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: err.Error()})
+		return
+	}
+	if end == 0 {
+		end = time.Now().Unix()
+	}
+	if start == 0 {
+		start = end - int64(defaultAvailabilityWindow/time.Second)
+	}
+	if end <= start {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: "end must be after start"})
+		return
+	}
+	ctx := c.Request.Context()
+
+	// 分区节点来自 slurmctl, 反映的是当前配置
+	var parts slurmctlmodels.Partitions
+	wantParts := slices.Compact(slices.Sorted(slices.Values(splitQuery(c, "partition"))))
+	if ctl := slurmctl.ForCluster(client.ClusterName); ctl != nil {
+		all, err := ctl.GetPartitions(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+			return
+		}
+		found := make(map[string]bool, len(all))
+		for _, p := range all {
+			found[p.Name] = true
+			if len(wantParts) == 0 || slices.Contains(wantParts, p.Name) {
+				parts = append(parts, p)
+			}
+		}
+		var missing []string
+		for _, name := range wantParts {
+			if !found[name] {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			c.JSON(http.StatusNotFound, response.Response{Detail: fmt.Sprintf("partition not found: %s", strings.Join(missing, ","))})
+			return
+		}
+	} else if len(wantParts) > 0 {
+		c.JSON(http.StatusNotFound, response.Response{Detail: fmt.Sprintf("no slurmctl backend for cluster %s", client.ClusterName)})
+		return
+	}
+	if len(nodes) == 0 && len(parts) > 0 {
+		seen := make(map[string]struct{})
+		for _, p := range parts {
+			for _, n := range p.Nodes {
+				if _, ok := seen[n]; !ok {
+					seen[n] = struct{}{}
+					nodes = append(nodes, n)
+				}
+			}
+		}
+		sort.Strings(nodes)
+	}
+
+	stats, err := client.GetNodeAvailability(ctx, nodes, start, end)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+	byNode := make(map[string]model.NodeAvailability, len(stats))
+	for _, s := range stats {
+		byNode[s.Node] = s
+	}
+	report := model.AvailabilityReport{Start: start, End: end, Nodes: stats, Partitions: make([]model.PartitionAvailability, 0, len(parts))}
+	for _, p := range parts {
+		members := make([]model.NodeAvailability, 0, len(p.Nodes))
+		for _, n := range p.Nodes {
+			if s, ok := byNode[n]; ok {
+				members = append(members, s)
+			}
+		}
+		report.Partitions = append(report.Partitions, model.AggregateAvailability(p.Name, members))
+	}
+	c.JSON(http.StatusOK, response.Response{Count: len(stats), Results: report})
+}
@@ -2,6 +2,7 @@ package slurmdb
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -9,7 +10,11 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	ldapc "solid/internal/pkg/client/ldap"
+	"solid/internal/pkg/client/slurmctl"
 	slurmdbc "solid/internal/pkg/client/slurmdb"
+	"solid/internal/pkg/common/hostlist"
+	"solid/internal/pkg/common/jobid"
 	"solid/internal/pkg/common/response"
 	"solid/internal/pkg/model"
 )
@@ -21,14 +26,14 @@ import (
 // @Tags slurm-accounting, user
 // @Produce json
 // @Param name path string true "用户名"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/slurm/accountting/user/:name [get]
 func HandlerGetUserByName(c *gin.Context) {
-	client := slurmdbc.Default()
+	client := clusterClient(c)
 	if client == nil {
-		c.JSON(http.StatusInternalServerError, response.Response{Detail: "slurmdb client not initialized"})
 		return
 	}
 	name := c.Param("name")
@@ -57,14 +62,14 @@ func HandlerGetUserByName(c *gin.Context) {
 // @Param paging query bool false "是否开启分页" default(true)
 // @Param page query int false "页码，从 1 开始（仅当 paging=true 生效）" minimum(1) default(1)
 // @Param page_size query int false "每页数量，1-100（仅当 paging=true 生效）" minimum(1) maximum(100) default(20)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/slurm/accountting/user/all [get]
 func HandlerGetUserAll(c *gin.Context) {
-	client := slurmdbc.Default()
+	client := clusterClient(c)
 	if client == nil {
-		c.JSON(http.StatusInternalServerError, response.Response{Detail: "slurmdb client not initialized"})
 		return
 	}
 
@@ -111,14 +116,14 @@ func HandlerGetUserAll(c *gin.Context) {
 // @Tags slurm-accounting, qos
 // @Produce json
 // @Param id query int true "QoS ID"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/slurm/accounting/qos [get]
 func HandlerGetQoS(c *gin.Context) {
-	client := slurmdbc.Default()
+	client := clusterClient(c)
 	if client == nil {
-		c.JSON(http.StatusInternalServerError, response.Response{Detail: "slurmdb client not initialized"})
 		return
 	}
 	idStr := c.Query("id")
@@ -140,26 +145,29 @@ func HandlerGetQoS(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
 		return
 	}
+	if !decodeTres(c, client, row) {
+		return
+	}
 	c.JSON(http.StatusOK, response.Response{Results: row})
 }
 
 // HandlerGetQoSAll 获取 QoS 列表（分页）。
 //
 // @Summary 获取 QoS 列表
-// @Description 从 qos_table 查询 deleted=0 的 QoS，按 id 降序排序并分页返回
+// @Description 从 qos_table 查询 deleted=0 的 QoS，按 id 降序排序并分页返回；标志、抢占模式与 TRES 限制已解码, 原始取值见 raw
 // @Tags slurm-accounting, qos
 // @Produce json
 // @Param paging query bool false "是否开启分页" default(true)
 // @Param page query int false "页码，从 1 开始（仅当 paging=true 生效）" minimum(1) default(1)
 // @Param page_size query int false "每页数量，1-100（仅当 paging=true 生效）" minimum(1) maximum(100) default(20)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/slurm/accounting/qos/all [get]
 func HandlerGetQoSAll(c *gin.Context) {
-	client := slurmdbc.Default()
+	client := clusterClient(c)
 	if client == nil {
-		c.JSON(http.StatusInternalServerError, response.Response{Detail: "slurmdb client not initialized"})
 		return
 	}
 
@@ -186,6 +194,9 @@ func HandlerGetQoSAll(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
 			return
 		}
+		if !decodeTres(c, client, rows) {
+			return
+		}
 		prevURL, nextURL := response.BuildPageLinks(c.Request.URL, pq.Page, pq.PageSize, int(total))
 		c.JSON(http.StatusOK, response.Response{Count: int(total), Previous: prevURL, Next: nextURL, Results: rows})
 		return
@@ -197,6 +208,9 @@ func HandlerGetQoSAll(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
 		return
 	}
+	if !decodeTres(c, client, rows) {
+		return
+	}
 	c.JSON(http.StatusOK, response.Response{Count: int(total), Results: rows})
 }
 
@@ -207,14 +221,15 @@ func HandlerGetQoSAll(c *gin.Context) {
 // @Tags slurm-accounting, account
 // @Produce json
 // @Param name path string true "账户名称"
+// @Param history query int false "返回 txn_table 中涉及该账户及其关联的最近变更条数, 0 表示不返回" minimum(0) maximum(100) default(0)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/slurm/accounting/account/:name [get]
 func HandlerGetAccountByName(c *gin.Context) {
-	client := slurmdbc.Default()
+	client := clusterClient(c)
 	if client == nil {
-		c.JSON(http.StatusInternalServerError, response.Response{Detail: "slurmdb client not initialized"})
 		return
 	}
 	name := c.Param("name")
@@ -231,7 +246,22 @@ func HandlerGetAccountByName(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, response.Response{Results: acct})
+	detail := model.AccountDetail{Account: *acct}
+	if v := strings.TrimSpace(c.Query("history")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > 100 {
+			c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid history parameter"})
+			return
+		}
+		if n > 0 {
+			filter := slurmdbc.TxnFilter{Accounts: []string{name}}
+			if detail.History, _, err = client.GetTxns(c.Request.Context(), filter, 1, n); err != nil {
+				c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+				return
+			}
+		}
+	}
+	c.JSON(http.StatusOK, response.Response{Results: detail})
 }
 
 // HandlerGetAccountAll 获取账户列表（分页）。
@@ -243,14 +273,14 @@ func HandlerGetAccountByName(c *gin.Context) {
 // @Param paging query bool false "是否开启分页" default(true)
 // @Param page query int false "页码，从 1 开始（仅当 paging=true 生效）" minimum(1) default(1)
 // @Param page_size query int false "每页数量，1-100（仅当 paging=true 生效）" minimum(1) maximum(100) default(20)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/slurm/accounting/account/all [get]
 func HandlerGetAccountAll(c *gin.Context) {
-	client := slurmdbc.Default()
+	client := clusterClient(c)
 	if client == nil {
-		c.JSON(http.StatusInternalServerError, response.Response{Detail: "slurmdb client not initialized"})
 		return
 	}
 
@@ -307,14 +337,14 @@ func HandlerGetAccountAll(c *gin.Context) {
 // @Tags slurm-accounting, account
 // @Produce json
 // @Param name path string true "账户名称"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/slurm/accouting/account/:name/childnodes
 func HandlerChildNodesOfAccount(c *gin.Context) {
-	client := slurmdbc.Default()
+	client := clusterClient(c)
 	if client == nil {
-		c.JSON(http.StatusInternalServerError, response.Response{Detail: "slurmdb client not initialized"})
 		return
 	}
 
@@ -343,14 +373,14 @@ func HandlerChildNodesOfAccount(c *gin.Context) {
 // @Tags 用户管理
 // @Produce json
 // @Param account path string true "账户名称"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/slurm/accounting/association/:account/childnodes
 func HandlerGetAssociationChildNodesOfAccount(c *gin.Context) {
-	client := slurmdbc.Default()
+	client := clusterClient(c)
 	if client == nil {
-		c.JSON(http.StatusInternalServerError, response.Response{Detail: "slurmdb client not initialized"})
 		return
 	}
 
@@ -402,20 +432,20 @@ type AssociationDetail struct {
 // HandlerGetTreeAssociationDetail 获取某个关联信息的详情
 //
 // @Summary 获取某个关联信息的详情
-// @Description 获取某个关联信息的详情
+// @Description 获取某个关联信息的详情；TRES 限制已解码, 原始取值见 raw
 // @Tags 用户管理
 // @Produce json
 // @Param account query string true "账户名称"
 // @Param user query string false "用户名称"
 // @Param partition query string false "分区名称"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/slurm/accounting/associations/detail [get]
 func HandlerGetTreeAssociationsDetail(c *gin.Context) {
-	client := slurmdbc.Default()
+	client := clusterClient(c)
 	if client == nil {
-		c.JSON(http.StatusInternalServerError, response.Response{Detail: "slurmdb client not initialized"})
 		return
 	}
 
@@ -437,26 +467,161 @@ func HandlerGetTreeAssociationsDetail(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
 		return
 	}
+	if !decodeTres(c, client, row) {
+		return
+	}
 
 	c.JSON(http.StatusOK, response.Response{Results: row})
 }
 
+// HandlerGetAssociationTree 获取完整的关联树。
+//
+// @Summary 获取关联树
+// @Description 以嵌套集合(lft/rgt)一次查询 <cluster>_assoc_table 中 root 账户的整个子树, 每个节点包含自身限制与继承父关联后生效的限制(effective); 组限制(grp_*)作用于子树总和, 不参与继承；TRES 限制已解码, 原始取值见各限制的 raw
+// @Tags slurm-accounting, association
+// @Produce json
+// @Param root query string false "根账户" default(root)
+// @Param depth query int false "返回的最大深度, 0 表示不限制" minimum(0) default(0)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/association/tree [get]
+func HandlerGetAssociationTree(c *gin.Context) {
+	client := clusterClient(c)
+	if client == nil {
+		return
+	}
+	root := strings.TrimSpace(c.DefaultQuery("root", "root"))
+	depth := 0
+	if v := strings.TrimSpace(c.Query("depth")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid depth parameter"})
+			return
+		}
+		depth = n
+	}
+
+	tree, err := client.GetAssocTree(c.Request.Context(), root, depth)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, response.Response{Detail: "account not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+	if !decodeTres(c, client, tree) {
+		return
+	}
+	c.JSON(http.StatusOK, response.Response{Results: tree})
+}
+
+// HandlerGetEffectiveLimits 获取关联最终生效的限制。
+//
+// @Summary 获取生效限制
+// @Description 沿父关联链合并关联限制, 并按 Slurm 的优先级合并分区 QoS、作业 QoS(OverPartQOS 时作业 QoS 优先)与分区限制, 返回每项生效限制及其来源; 各级关联的组限制(grp_*)同时生效, 取最严格的取值并在 layers 中列出每一级; 指定 partition 且 slurmctld 可用时读取分区 QoS 与分区限制
+// @Tags slurm-accounting, association
+// @Produce json
+// @Param account query string true "账户"
+// @Param user query string false "用户, 为空表示账户关联"
+// @Param partition query string false "分区"
+// @Param qos query string false "作业 QoS, 为空时使用关联的默认 QoS"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/association/limits [get]
+func HandlerGetEffectiveLimits(c *gin.Context) {
+	client := clusterClient(c)
+	if client == nil {
+		return
+	}
+	account := strings.TrimSpace(c.Query("account"))
+	if account == "" {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: "missing account parameter"})
+		return
+	}
+	user := strings.TrimSpace(c.Query("user"))
+	partition := strings.TrimSpace(c.Query("partition"))
+
+	var part *slurmdbc.PartitionLimits
+	if ctl := slurmctl.ForCluster(client.ClusterName); ctl != nil && partition != "" {
+		p, err := ctl.GetPartition(c.Request.Context(), partition)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+			return
+		}
+		if p != nil {
+			part = &slurmdbc.PartitionLimits{
+				Name:           p.Name,
+				Qos:            p.QoS,
+				MaxNodes:       int64(p.MaxNodes),
+				MaxCPUsPerNode: int64(p.MaxCPUsPerNode),
+			}
+			if p.MaxTime > 0 {
+				part.MaxWall = p.MaxTime / 60
+			}
+		}
+	}
+
+	limits, err := client.GetEffectiveLimits(c.Request.Context(), account, user, partition, strings.TrimSpace(c.Query("qos")), part)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, response.Response{Detail: "association not found"})
+		case errors.Is(err, slurmdbc.ErrUnknownQos):
+			c.JSON(http.StatusBadRequest, response.Response{Detail: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, response.Response{Results: limits})
+}
+
 // HandlerGetAccountingJobs 获取作业列表（分页）。
 //
 // @Summary 获取作业列表
-// @Description 从 <cluster>_job_table 查询 deleted=0 的作业；按 jobid 降序排序并分页返回
+// @Description 从 <cluster>_job_table 查询 deleted=0 的作业；支持与 sacct 类似的过滤条件, 逗号分隔的多个取值之间为或关系；按 jobid 降序排序并分页返回(cluster=all 时按提交时间降序)；状态、退出码、标志与 TRES 已解码, 原始取值见 raw
 // @Tags slurm-accounting, job
 // @Produce json
+// @Param user query string false "用户名或 UID, 逗号分隔; 用户名通过 LDAP uidNumber 换算为 UID" example("alice,bob")
+// @Param account query string false "账户, 逗号分隔"
+// @Param partition query string false "分区, 逗号分隔"
+// @Param state query string false "作业状态名称或缩写, 逗号分隔" example("RUNNING,CD")
+// @Param qos query string false "QoS 名称, 逗号分隔"
+// @Param submit_after query int false "提交时间下界(Unix 秒)"
+// @Param submit_before query int false "提交时间上界(Unix 秒)"
+// @Param start_after query int false "开始时间下界(Unix 秒)"
+// @Param start_before query int false "开始时间上界(Unix 秒)"
+// @Param end_after query int false "结束时间下界(Unix 秒)"
+// @Param end_before query int false "结束时间上界(Unix 秒)"
+// @Param node query string false "节点名称或 hostlist 表达式, 仅返回运行在这些节点上的作业; 须同时给出 submit_after、start_after 或 end_after, count 只是下界" example("cn1858")
+// @Param name query string false "作业名称, 支持 * 通配符" example("train-*")
+// @Param exit_code query int false "退出码"
+// @Param array_job_id query int false "数组作业ID, 返回该数组作业的所有任务"
+// @Param reservation query string false "预约名称"
+// @Param wckey query string false "WCKey 名称, 逗号分隔"
+// @Param expand_nodes query bool false "是否返回展开后的节点列表" default(false)
 // @Param page query int false "页码，从 1 开始" minimum(1) default(1)
 // @Param page_size query int false "每页数量，1-100" minimum(1) maximum(100) default(20)
+// @Param cluster query string false "集群名称, 默认为配置的集群; all 表示查询所有集群"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
-// @Router /api/v1/slurm/accounting/jobs [get]
+// @Router /api/v1/slurm/accounting/job/all [get]
 func HandlerGetAccountingJobs(c *gin.Context) {
-	client := slurmdbc.Default()
-	if client == nil {
-		c.JSON(http.StatusInternalServerError, response.Response{Detail: "slurmdb client not initialized"})
+	var client *slurmdbc.Client
+	if allClusters(c) {
+		if client = slurmdbc.Default(); client == nil {
+			c.JSON(http.StatusInternalServerError, response.Response{Detail: "slurmdb client not initialized"})
+			return
+		}
+	} else if client = clusterClient(c); client == nil {
 		return
 	}
 
@@ -468,11 +633,45 @@ func HandlerGetAccountingJobs(c *gin.Context) {
 		return
 	}
 
-	rows, total, err := client.GetJobsDetail(c.Request.Context(), pq.Page, pq.PageSize)
+	filter, err := parseJobsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: err.Error()})
+		return
+	}
+	if users := splitQuery(c, "user"); len(users) > 0 {
+		uids, status, err := resolveUIDs(c, users)
+		if err != nil {
+			c.JSON(status, response.Response{Detail: err.Error()})
+			return
+		}
+		filter.Users = uids
+	}
+
+	var (
+		rows  model.Jobs
+		total int64
+	)
+	if allClusters(c) {
+		rows, total, err = client.GetJobsDetailAllClusters(c.Request.Context(), filter, pq.Page, pq.PageSize)
+	} else {
+		rows, total, err = client.GetJobsDetail(c.Request.Context(), filter, pq.Page, pq.PageSize)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
 		return
 	}
+	if !decodeTres(c, client, rows) {
+		return
+	}
+	if err := client.ResolveJobWckeys(c.Request.Context(), rows); err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+	if c.Query("expand_nodes") == "true" {
+		for i := range rows {
+			rows[i].Nodes, _ = hostlist.Expand(rows[i].Nodelist)
+		}
+	}
 	prevURL, nextURL := response.BuildPageLinks(c.Request.URL, pq.Page, pq.PageSize, int(total))
 	c.JSON(http.StatusOK, response.Response{
 		Count:    int(total),
@@ -482,20 +681,120 @@ func HandlerGetAccountingJobs(c *gin.Context) {
 	})
 }
 
+// parseJobsFilter 解析作业查询参数, user 需要访问 LDAP, 由调用方单独处理.
+func parseJobsFilter(c *gin.Context) (slurmdbc.JobsFilter, error) {
+	filter := slurmdbc.JobsFilter{
+		Accounts:    splitQuery(c, "account"),
+		Partitions:  splitQuery(c, "partition"),
+		Qos:         splitQuery(c, "qos"),
+		Node:        strings.TrimSpace(c.Query("node")),
+		Name:        strings.TrimSpace(c.Query("name")),
+		Reservation: strings.TrimSpace(c.Query("reservation")),
+		Wckeys:      splitQuery(c, "wckey"),
+	}
+	if filter.Node != "" {
+		if _, err := hostlist.Expand(filter.Node); err != nil {
+			return filter, fmt.Errorf("invalid node parameter: %s", err)
+		}
+	}
+	for _, name := range splitQuery(c, "state") {
+		v, ok := model.JobStateValue(name)
+		if !ok {
+			return filter, fmt.Errorf("invalid state parameter: %s", name)
+		}
+		filter.States = append(filter.States, v)
+	}
+	for _, p := range []struct {
+		key string
+		dst *int64
+	}{
+		{"submit_after", &filter.SubmitAfter}, {"submit_before", &filter.SubmitBefore},
+		{"start_after", &filter.StartAfter}, {"start_before", &filter.StartBefore},
+		{"end_after", &filter.EndAfter}, {"end_before", &filter.EndBefore},
+	} {
+		v := strings.TrimSpace(c.Query(p.key))
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			return filter, fmt.Errorf("invalid %s parameter", p.key)
+		}
+		*p.dst = n
+	}
+	// 按节点过滤需要逐批展开 nodelist, 必须给出时间下界以限制扫描范围
+	if filter.Node != "" && filter.SubmitAfter == 0 && filter.StartAfter == 0 && filter.EndAfter == 0 {
+		return filter, fmt.Errorf("node parameter requires submit_after, start_after or end_after")
+	}
+	if v := strings.TrimSpace(c.Query("exit_code")); v != "" {
+		n, err := strconv.ParseUint(v, 10, 8)
+		if err != nil {
+			return filter, fmt.Errorf("invalid exit_code parameter")
+		}
+		code := uint32(n)
+		filter.ExitCode = &code
+	}
+	if v := strings.TrimSpace(c.Query("array_job_id")); v != "" {
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || n == 0 {
+			return filter, fmt.Errorf("invalid array_job_id parameter")
+		}
+		filter.ArrayJobID = uint32(n)
+	}
+	return filter, nil
+}
+
+// splitQuery 返回逗号分隔的查询参数中的非空取值.
+func splitQuery(c *gin.Context, key string) []string {
+	var out []string
+	for _, v := range strings.Split(c.Query(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// resolveUIDs 将用户名换算为 UID, 纯数字视为 UID. 返回错误时同时返回对应的 HTTP 状态码.
+func resolveUIDs(c *gin.Context, users []string) ([]uint32, int, error) {
+	uids := make([]uint32, 0, len(users))
+	for _, u := range users {
+		if n, err := strconv.ParseUint(u, 10, 32); err == nil {
+			uids = append(uids, uint32(n))
+			continue
+		}
+		lcli := ldapc.Default()
+		if lcli == nil {
+			return nil, http.StatusInternalServerError, fmt.Errorf("ldap client not initialized")
+		}
+		attrs, err := lcli.GetUser(c.Request.Context(), u)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		n, err := strconv.ParseUint(attrs["uidNumber"], 10, 32)
+		if attrs == nil || err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("unknown user: %s", u)
+		}
+		uids = append(uids, uint32(n))
+	}
+	return uids, http.StatusOK, nil
+}
+
 // HandlerGetAccountingJobsSteps
 // @Summary 获取 Slurm 账户中作业列表
 // @Description 获取 Slurm 账户中作业列表
 // @Tags slurm-accounting, account
 // @Produce json
-// @Param jobid query int true "作业ID"
+// @Param jobid query string true "作业ID, 数组任务格式为 123_7"
+// @Param expand_nodes query bool false "是否返回展开后的节点列表" default(false)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/slurm/accounting/job/steps [get]
 func HandlerGetAccountingJobsSteps(c *gin.Context) {
-	client := slurmdbc.Default()
+	client := clusterClient(c)
 	if client == nil {
-		c.JSON(http.StatusInternalServerError, response.Response{Detail: "slurmdb client not initialized"})
 		return
 	}
 
@@ -504,35 +803,44 @@ func HandlerGetAccountingJobsSteps(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.Response{Detail: "missing jobid parameter"})
 		return
 	}
-	jobid, err := strconv.ParseInt(jobidStr, 10, 64)
-	if err != nil || jobid <= 0 {
+	id, err := jobid.Parse(jobidStr)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid jobid parameter"})
 		return
 	}
 
-	steps, err := client.GetJobSteps(c.Request.Context(), jobid)
+	steps, err := client.GetJobSteps(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
 		return
 	}
+	if !decodeTres(c, client, steps) {
+		return
+	}
+	if c.Query("expand_nodes") == "true" {
+		for i := range steps {
+			steps[i].Nodes, _ = hostlist.Expand(steps[i].Nodelist)
+		}
+	}
 	c.JSON(http.StatusOK, response.Response{Count: len(steps), Results: steps})
 }
 
 // HandlerGetAccountingJobDetail 获取指定作业的详细信息。
 //
 // @Summary 获取作业详情
-// @Description 通过 jobid 查询 <cluster>_job_table 中对应作业（deleted=0），返回作业详情
+// @Description 通过 jobid 查询 <cluster>_job_table 中对应作业（deleted=0），返回作业详情；状态、退出码、标志与 TRES 已解码, 原始取值见 raw
 // @Tags slurm-accounting, job
 // @Produce json
-// @Param jobid query int true "作业ID"
+// @Param jobid query string true "作业ID, 数组任务格式为 123_7"
+// @Param expand_nodes query bool false "是否返回展开后的节点列表" default(false)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/slurm/accounting/job [get]
 func HandlerGetJobFromAccounting(c *gin.Context) {
-	client := slurmdbc.Default()
+	client := clusterClient(c)
 	if client == nil {
-		c.JSON(http.StatusInternalServerError, response.Response{Detail: "slurmdb client not initialized"})
 		return
 	}
 	jobidStr := c.Query("jobid")
@@ -540,12 +848,12 @@ func HandlerGetJobFromAccounting(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.Response{Detail: "missing jobid parameter"})
 		return
 	}
-	jobid, err := strconv.ParseInt(jobidStr, 10, 64)
-	if err != nil || jobid <= 0 {
+	id, err := jobid.Parse(jobidStr)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid jobid parameter"})
 		return
 	}
-	row, err := client.GetJobDetail(c.Request.Context(), jobid)
+	row, err := client.GetJobDetail(c.Request.Context(), id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.JSON(http.StatusBadRequest, response.Response{Detail: "job not found"})
@@ -554,5 +862,229 @@ func HandlerGetJobFromAccounting(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
 		return
 	}
+	if !decodeTres(c, client, row) {
+		return
+	}
+	jobs := model.Jobs{*row}
+	if err := client.ResolveJobWckeys(c.Request.Context(), jobs); err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+	row.WckeyName = jobs[0].WckeyName
+	if c.Query("expand_nodes") == "true" {
+		row.Nodes, _ = hostlist.Expand(row.Nodelist)
+	}
 	c.JSON(http.StatusOK, response.Response{Results: row})
 }
+
+// HandlerGetReservations 获取历史预约及其使用情况（分页）。
+//
+// @Summary 获取历史预约
+// @Description 从 <cluster>_resv_table 查询 deleted=0 的预约, 关联 <cluster>_job_table 统计预约期间运行的作业数与运行时长; 按开始时间降序分页返回
+// @Tags slurm-accounting, reservation
+// @Produce json
+// @Param name query string false "预约名称"
+// @Param since query int false "仅返回结束时间不早于该时间的预约(Unix 秒)"
+// @Param until query int false "仅返回开始时间不晚于该时间的预约(Unix 秒)"
+// @Param page query int false "页码，从 1 开始" minimum(1) default(1)
+// @Param page_size query int false "每页数量，1-100" minimum(1) maximum(100) default(20)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/reservation/all [get]
+func HandlerGetReservations(c *gin.Context) {
+	client := clusterClient(c)
+	if client == nil {
+		return
+	}
+
+	var pq model.PagingQuery
+	_ = c.ShouldBindQuery(&pq)
+	pq.SetDefaults(1, 20, 100)
+	if err := pq.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid paging parameters"})
+		return
+	}
+
+	filter := slurmdbc.ReservationsFilter{Name: strings.TrimSpace(c.Query("name"))}
+	for _, p := range []struct {
+		key string
+		dst *int64
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		v := strings.TrimSpace(c.Query(p.key))
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, response.Response{Detail: fmt.Sprintf("invalid %s parameter", p.key)})
+			return
+		}
+		*p.dst = n
+	}
+
+	rows, total, err := client.GetReservations(c.Request.Context(), filter, pq.Page, pq.PageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+	prevURL, nextURL := response.BuildPageLinks(c.Request.URL, pq.Page, pq.PageSize, int(total))
+	c.JSON(http.StatusOK, response.Response{
+		Count:    int(total),
+		Previous: prevURL,
+		Next:     nextURL,
+		Results:  rows,
+	})
+}
+
+// HandlerGetAccountingArraySummary 获取数组作业的任务状态统计。
+//
+// @Summary 获取数组作业统计
+// @Description 从 <cluster>_job_table 按 id_array_job 统计各状态的任务数量, 同一任务重新排队时只统计最新记录, 尚未开始的任务计入 PENDING
+// @Tags slurm-accounting, job
+// @Produce json
+// @Param id path int true "数组作业ID"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/job/:id/array [get]
+func HandlerGetAccountingArraySummary(c *gin.Context) {
+	client := clusterClient(c)
+	if client == nil {
+		return
+	}
+	id, err := jobid.Parse(c.Param("id"))
+	if err != nil || id.IsArrayTask || id.IsHetComponent {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid array job id"})
+		return
+	}
+
+	sum, err := client.GetArraySummary(c.Request.Context(), id.JobID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, response.Response{Detail: "array job not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, response.Response{Results: sum})
+}
+
+// HandlerGetAccountingArrayTasks 获取数组作业的任务列表（分页）。
+//
+// @Summary 获取数组作业任务列表
+// @Description 从 <cluster>_job_table 查询 id_array_job 对应的任务, 同一任务重新排队时只返回最新记录; 按任务ID升序分页返回
+// @Tags slurm-accounting, job
+// @Produce json
+// @Param id path int true "数组作业ID"
+// @Param page query int false "页码，从 1 开始" minimum(1) default(1)
+// @Param page_size query int false "每页数量，1-100" minimum(1) maximum(100) default(20)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/job/:id/array/tasks [get]
+func HandlerGetAccountingArrayTasks(c *gin.Context) {
+	client := clusterClient(c)
+	if client == nil {
+		return
+	}
+	id, err := jobid.Parse(c.Param("id"))
+	if err != nil || id.IsArrayTask || id.IsHetComponent {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid array job id"})
+		return
+	}
+
+	var pq model.PagingQuery
+	_ = c.ShouldBindQuery(&pq)
+	pq.SetDefaults(1, 20, 100)
+	if err := pq.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid paging parameters"})
+		return
+	}
+
+	rows, total, err := client.GetArrayTasks(c.Request.Context(), id.JobID, pq.Page, pq.PageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+	if !decodeTres(c, client, rows) {
+		return
+	}
+	prevURL, nextURL := response.BuildPageLinks(c.Request.URL, pq.Page, pq.PageSize, int(total))
+	c.JSON(http.StatusOK, response.Response{
+		Count:    int(total),
+		Previous: prevURL,
+		Next:     nextURL,
+		Results:  rows,
+	})
+}
+
+// HandlerGetAccountingHetJob 获取异构作业的各组件。
+//
+// @Summary 获取异构作业
+// @Description 从 <cluster>_job_table 按 het_job_id 查询异构作业的各组件及其作业步, 同一组件重新排队时只返回最新记录; id 可为 leader ID 或 1234+1 形式的组件ID; 非异构作业作为只有一个组件返回
+// @Tags slurm-accounting, job
+// @Produce json
+// @Param id path string true "作业ID, 如 1234 或 1234+1"
+// @Param expand_nodes query bool false "是否返回展开后的节点列表" default(false)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/job/:id/het [get]
+func HandlerGetAccountingHetJob(c *gin.Context) {
+	client := clusterClient(c)
+	if client == nil {
+		return
+	}
+	id, err := jobid.Parse(c.Param("id"))
+	if err != nil || id.IsArrayTask {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid het job id"})
+		return
+	}
+
+	het, err := client.GetHetJob(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, response.Response{Detail: "job not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+	if !decodeTres(c, client, het) {
+		return
+	}
+	if c.Query("expand_nodes") == "true" {
+		for i := range het.Components {
+			comp := &het.Components[i]
+			comp.Nodes, _ = hostlist.Expand(comp.Nodelist)
+			for j := range comp.Steps {
+				comp.Steps[j].Nodes, _ = hostlist.Expand(comp.Steps[j].Nodelist)
+			}
+		}
+	}
+	c.JSON(http.StatusOK, response.Response{Count: len(het.Components), Results: het})
+}
+
+// tresDecoder 可使用 tres_table 解码的结果, 如 model.Job、model.Jobs、model.Qos、model.UserAssociation.
+type tresDecoder interface {
+	Decode(tres model.TresTable)
+}
+
+// decodeTres 查询 tres_table 并解码 v 中的枚举与 TRES 字段, 失败时写入错误响应并返回 false.
+func decodeTres(c *gin.Context, client *slurmdbc.Client, v tresDecoder) bool {
+	tres, err := client.GetTresTable(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return false
+	}
+	v.Decode(tres)
+	return true
+}
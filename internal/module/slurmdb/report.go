@@ -0,0 +1,175 @@
+package slurmdb
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	slurmdbc "solid/internal/pkg/client/slurmdb"
+	"solid/internal/pkg/common/response"
+)
+
+// parseUsageQuery 解析报表的公共参数 start、end、granularity、tres 与 total.
+func parseUsageQuery(c *gin.Context) (slurmdbc.UsageQuery, error) {
+	q := slurmdbc.UsageQuery{
+		Granularity: strings.TrimSpace(c.DefaultQuery("granularity", "day")),
+		Tres:        splitQuery(c, "tres"),
+		Total:       c.Query("total") == "true",
+	}
+	for _, p := range []struct {
+		key string
+		dst *int64
+	}{{"start", &q.Start}, {"end", &q.End}} {
+		v := strings.TrimSpace(c.Query(p.key))
+		if v == "" {
+			return q, fmt.Errorf("missing %s parameter", p.key)
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			return q, fmt.Errorf("invalid %s parameter", p.key)
+		}
+		*p.dst = n
+	}
+	switch q.Granularity {
+	case "hour", "day", "month":
+	default:
+		return q, fmt.Errorf("invalid granularity parameter")
+	}
+	return q, nil
+}
+
+// usageErrorStatus 返回报表查询错误对应的 HTTP 状态码.
+func usageErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, slurmdbc.ErrInvalidUsageQuery):
+		return http.StatusBadRequest
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// HandlerGetClusterUsage 获取集群使用量报表。
+//
+// @Summary 集群使用量报表
+// @Description 与 sreport cluster utilization 类似, 从 <cluster>_usage_<granularity>_table 查询各周期各 TRES 的分配、宕机、计划停机、空闲、预留与超额使用量(TRES 秒), 并计算利用率与分配小时数
+// @Tags slurm-accounting, report
+// @Produce json
+// @Param start query int true "开始时间(Unix 秒), 含"
+// @Param end query int true "结束时间(Unix 秒), 不含"
+// @Param granularity query string false "统计粒度" Enums(hour, day, month) default(day)
+// @Param tres query string false "TRES 名称, 逗号分隔, 为空表示全部" example("cpu,gres/gpu")
+// @Param total query bool false "是否汇总整个时间范围" default(false)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/report/cluster [get]
+func HandlerGetClusterUsage(c *gin.Context) {
+	client := clusterClient(c)
+	if client == nil {
+		return
+	}
+	q, err := parseUsageQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: err.Error()})
+		return
+	}
+
+	rows, err := client.GetClusterUsage(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(usageErrorStatus(err), response.Response{Detail: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, response.Response{Count: len(rows), Results: rows})
+}
+
+// HandlerGetAccountUsage 获取账户树使用量报表。
+//
+// @Summary 账户使用量报表
+// @Description 与 sreport cluster AccountUtilizationByUser 类似, 返回以 account 为根的账户树, 每个节点包含直属用户的使用量及汇总了所有子账户与用户的使用量
+// @Tags slurm-accounting, report
+// @Produce json
+// @Param account query string false "根账户" default(root)
+// @Param start query int true "开始时间(Unix 秒), 含"
+// @Param end query int true "结束时间(Unix 秒), 不含"
+// @Param granularity query string false "统计粒度" Enums(hour, day, month) default(day)
+// @Param tres query string false "TRES 名称, 逗号分隔, 为空表示全部" example("cpu,gres/gpu")
+// @Param total query bool false "是否汇总整个时间范围" default(false)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/report/account [get]
+func HandlerGetAccountUsage(c *gin.Context) {
+	client := clusterClient(c)
+	if client == nil {
+		return
+	}
+	q, err := parseUsageQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: err.Error()})
+		return
+	}
+
+	account := strings.TrimSpace(c.DefaultQuery("account", "root"))
+	tree, err := client.GetAccountUsageTree(c.Request.Context(), account, q)
+	if err != nil {
+		c.JSON(usageErrorStatus(err), response.Response{Detail: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, response.Response{Results: tree})
+}
+
+// HandlerGetTopUsers 获取使用量最高的用户。
+//
+// @Summary 用户使用量排行
+// @Description 与 sreport user TopUsage 类似, 汇总 account 子树内各用户在时间范围内的使用量, 按 tres 中第一项降序返回前 top 个用户
+// @Tags slurm-accounting, report
+// @Produce json
+// @Param account query string false "根账户" default(root)
+// @Param start query int true "开始时间(Unix 秒), 含"
+// @Param end query int true "结束时间(Unix 秒), 不含"
+// @Param granularity query string false "统计粒度, 决定查询的汇总表" Enums(hour, day, month) default(day)
+// @Param tres query string false "TRES 名称, 逗号分隔, 按第一项排序" default(cpu)
+// @Param top query int false "返回数量，1-100" minimum(1) maximum(100) default(10)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/report/user/top [get]
+func HandlerGetTopUsers(c *gin.Context) {
+	client := clusterClient(c)
+	if client == nil {
+		return
+	}
+	q, err := parseUsageQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: err.Error()})
+		return
+	}
+	top := 10
+	if v := strings.TrimSpace(c.Query("top")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid top parameter"})
+			return
+		}
+		top = n
+	}
+
+	account := strings.TrimSpace(c.DefaultQuery("account", "root"))
+	rows, err := client.GetTopUsers(c.Request.Context(), account, q, top)
+	if err != nil {
+		c.JSON(usageErrorStatus(err), response.Response{Detail: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, response.Response{Count: len(rows), Results: rows})
+}
@@ -9,6 +9,7 @@ type Router struct{}
 func (rt Router) Register(r *gin.Engine) {
 	v1 := r.Group("/api/v1/slurm/accounting")
 	{
+		v1.GET("/cluster/all", HandlerGetClusters)                                           // GET /api/v1/slurm/accounting/cluster/all
 		v1.GET("/user/:name", HandlerGetUserByName)                                          // GET /api/v1/slurm/accountting/user/:name
 		v1.GET("/user/all", HandlerGetUserAll)                                               // GET /api/v1/slurm/accountting/user/all
 		v1.GET("/qos", HandlerGetQoS)                                                        // GET /api/v1/slurm/accountting/qos
@@ -18,8 +19,39 @@ func (rt Router) Register(r *gin.Engine) {
 		v1.GET("/account/:name/childnodes", HandlerChildNodesOfAccount)                      // GET /api/v1/slurm/accouting/account/:name/childnodes
 		v1.GET("/association/:account/childnodes", HandlerGetAssociationChildNodesOfAccount) // GET /api/v1/slurm/accouting/associations/:account/childnodes
 		v1.GET("/association/detail", HandlerGetTreeAssociationsDetail)                      // GET /api/v1/slurm/accounting/tree/association/detail
+		v1.GET("/association/tree", HandlerGetAssociationTree)                               // GET /api/v1/slurm/accounting/association/tree?root=xxx&depth=xxx
+		v1.GET("/association/limits", HandlerGetEffectiveLimits)                             // GET /api/v1/slurm/accounting/association/limits?account=xxx&user=xxx&partition=xxx&qos=xxx
 		v1.GET("/job/all", HandlerGetAccountingJobs)                                         // GET /api/v1/slurm/accounting/job/all
 		v1.GET("/job/steps", HandlerGetAccountingJobsSteps)                                  // GET /api/v1/slurm/accounting/job/steps?jobid=xxx
 		v1.GET("/job", HandlerGetJobFromAccounting)                                          // GET /api/v1/slurm/accouting/job?jobid=xxx
+		v1.GET("/job/:id/array", HandlerGetAccountingArraySummary)                           // GET /api/v1/slurm/accounting/job/:id/array
+		v1.GET("/job/:id/array/tasks", HandlerGetAccountingArrayTasks)                       // GET /api/v1/slurm/accounting/job/:id/array/tasks?page=xxx&page_size=xxx
+		v1.GET("/job/:id/efficiency", HandlerGetJobEfficiency)                               // GET /api/v1/slurm/accounting/job/:id/efficiency
+		v1.GET("/job/efficiency", HandlerGetLeastEfficientJobs)                              // GET /api/v1/slurm/accounting/job/efficiency?user=xxx&sort=cpu&limit=xxx
+		v1.GET("/job/:id/het", HandlerGetAccountingHetJob)                                   // GET /api/v1/slurm/accounting/job/:id/het
+		v1.GET("/report/cluster", HandlerGetClusterUsage)                                    // GET /api/v1/slurm/accounting/report/cluster?start=xxx&end=xxx&granularity=xxx&tres=xxx
+		v1.GET("/report/account", HandlerGetAccountUsage)                                    // GET /api/v1/slurm/accounting/report/account?account=xxx&start=xxx&end=xxx&granularity=xxx&tres=xxx
+		v1.GET("/report/user/top", HandlerGetTopUsers)                                       // GET /api/v1/slurm/accounting/report/user/top?account=xxx&start=xxx&end=xxx&tres=xxx&top=xxx
+		v1.GET("/report/wckey", HandlerGetWckeyUsage)                                        // GET /api/v1/slurm/accounting/report/wckey?wckey=xxx&start=xxx&end=xxx&granularity=xxx&tres=xxx
+		v1.GET("/wckey/all", HandlerGetWckeys)                                               // GET /api/v1/slurm/accounting/wckey/all?user=xxx
+		v1.GET("/reservation/all", HandlerGetReservations)                                   // GET /api/v1/slurm/accounting/reservation/all?name=xxx&since=xxx&until=xxx
+		v1.GET("/node/events", HandlerGetNodeEvents)                                         // GET /api/v1/slurm/accounting/node/events?node=xxx&start=xxx&end=xxx&failed_jobs=xxx
+		v1.GET("/node/availability", HandlerGetNodeAvailability)                             // GET /api/v1/slurm/accounting/node/availability?node=xxx&partition=xxx&start=xxx&end=xxx
+		v1.GET("/transactions", HandlerGetTxns)                                              // GET /api/v1/slurm/accounting/transactions?actor=xxx&action=xxx&account=xxx&user=xxx&start=xxx&end=xxx
+		v1.POST("/admin/account", HandlerAddAccount)                                         // POST /api/v1/slurm/accounting/admin/account?dry_run=xxx
+		v1.PUT("/admin/account/:name", HandlerModifyAccount)                                 // PUT /api/v1/slurm/accounting/admin/account/:name?dry_run=xxx
+		v1.DELETE("/admin/account/:name", HandlerDeleteAccount)                              // DELETE /api/v1/slurm/accounting/admin/account/:name?operator=xxx&dry_run=xxx
+		v1.POST("/admin/user", HandlerAddUser)                                               // POST /api/v1/slurm/accounting/admin/user?dry_run=xxx
+		v1.PUT("/admin/user/:name", HandlerModifyUser)                                       // PUT /api/v1/slurm/accounting/admin/user/:name?dry_run=xxx
+		v1.DELETE("/admin/user/:name", HandlerDeleteUser)                                    // DELETE /api/v1/slurm/accounting/admin/user/:name?operator=xxx&dry_run=xxx
+		v1.POST("/admin/association", HandlerAddAssociation)                                 // POST /api/v1/slurm/accounting/admin/association?dry_run=xxx
+		v1.PUT("/admin/association", HandlerModifyAssociation)                               // PUT /api/v1/slurm/accounting/admin/association?dry_run=xxx
+		v1.DELETE("/admin/association", HandlerDeleteAssociation)                            // DELETE /api/v1/slurm/accounting/admin/association?account=xxx&user=xxx&partition=xxx&operator=xxx
+		v1.POST("/admin/qos", HandlerAddQos)                                                 // POST /api/v1/slurm/accounting/admin/qos?dry_run=xxx
+		v1.PUT("/admin/qos/:name", HandlerModifyQos)                                         // PUT /api/v1/slurm/accounting/admin/qos/:name?dry_run=xxx
+		v1.DELETE("/admin/qos/:name", HandlerDeleteQos)                                      // DELETE /api/v1/slurm/accounting/admin/qos/:name?operator=xxx&dry_run=xxx
+		v1.POST("/admin/coordinator", HandlerAddCoordinator)                                 // POST /api/v1/slurm/accounting/admin/coordinator?dry_run=xxx
+		v1.DELETE("/admin/coordinator", HandlerRemoveCoordinator)                            // DELETE /api/v1/slurm/accounting/admin/coordinator?account=xxx&users=xxx&operator=xxx
+		v1.POST("/admin/sync", HandlerSyncAssociationTree)                                   // POST /api/v1/slurm/accounting/admin/sync?apply=xxx&operator=xxx
 	}
 }
@@ -0,0 +1,86 @@
+package slurmdb
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	slurmdbc "solid/internal/pkg/client/slurmdb"
+	"solid/internal/pkg/common/response"
+	"solid/internal/pkg/model"
+)
+
+// parseTxnFilter 解析变更记录的过滤参数.
+func parseTxnFilter(c *gin.Context) (slurmdbc.TxnFilter, error) {
+	filter := slurmdbc.TxnFilter{
+		Actors:   splitQuery(c, "actor"),
+		Accounts: splitQuery(c, "account"),
+		Users:    splitQuery(c, "user"),
+	}
+	for _, name := range splitQuery(c, "action") {
+		v, ok := model.TxnActionValue(name)
+		if !ok {
+			return filter, fmt.Errorf("invalid action parameter: %s", name)
+		}
+		filter.Actions = append(filter.Actions, v)
+	}
+	start, end, err := parseTimeRange(c)
+	if err != nil {
+		return filter, err
+	}
+	filter.Start, filter.End = start, end
+	return filter, nil
+}
+
+// HandlerGetTxns 获取账务变更记录（分页）。
+//
+// @Summary 获取账务变更记录
+// @Description 从 txn_table 查询 sacctmgr 对账户、用户、关联、QoS 等的变更记录, 只包含全局对象与该集群的变更; 解码 action 并拆分变更对象与变更项, 生成可读描述; 按变更时间降序分页返回
+// @Tags slurm-accounting, transaction
+// @Produce json
+// @Param actor query string false "执行变更的用户, 逗号分隔"
+// @Param action query string false "变更类型, 逗号分隔" example("modify_associations,add_users")
+// @Param account query string false "变更涉及的账户, 逗号分隔, 包括账户下的关联"
+// @Param user query string false "变更涉及的用户, 逗号分隔, 包括用户的关联"
+// @Param start query int false "变更时间下界(Unix 秒), 含"
+// @Param end query int false "变更时间上界(Unix 秒), 不含"
+// @Param page query int false "页码，从 1 开始" minimum(1) default(1)
+// @Param page_size query int false "每页数量，1-100" minimum(1) maximum(100) default(20)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/transactions [get]
+func HandlerGetTxns(c *gin.Context) {
+	client := clusterClient(c)
+	if client == nil {
+		return
+	}
+
+	var pq model.PagingQuery
+	_ = c.ShouldBindQuery(&pq)
+	pq.SetDefaults(1, 20, 100)
+	if err := pq.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: "invalid paging parameters"})
+		return
+	}
+	filter, err := parseTxnFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: err.Error()})
+		return
+	}
+
+	rows, total, err := client.GetTxns(c.Request.Context(), filter, pq.Page, pq.PageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+	prevURL, nextURL := response.BuildPageLinks(c.Request.URL, pq.Page, pq.PageSize, int(total))
+	c.JSON(http.StatusOK, response.Response{
+		Count:    int(total),
+		Previous: prevURL,
+		Next:     nextURL,
+		Results:  rows,
+	})
+}
@@ -0,0 +1,70 @@
+package slurmdb
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"solid/internal/pkg/common/response"
+)
+
+// HandlerGetWckeys 获取 WCKey 列表。
+//
+// @Summary 获取 WCKey 列表
+// @Description 从 <cluster>_wckey_table 查询未删除的 WCKey 及其所属用户, is_def 表示该用户的默认 WCKey; 按用户与名称排序
+// @Tags slurm-accounting, wckey
+// @Produce json
+// @Param user query string false "用户名, 逗号分隔"
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/wckey/all [get]
+func HandlerGetWckeys(c *gin.Context) {
+	client := clusterClient(c)
+	if client == nil {
+		return
+	}
+	rows, err := client.GetWckeys(c.Request.Context(), splitQuery(c, "user"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Response{Detail: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, response.Response{Count: len(rows), Results: rows})
+}
+
+// HandlerGetWckeyUsage 获取 WCKey 使用量报表。
+//
+// @Summary WCKey 使用量报表
+// @Description 与 sreport cluster WCKeyUtilizationByUser 类似, 从 <cluster>_wckey_usage_<granularity>_table 统计各 WCKey 及其用户在各周期的使用量(TRES 秒), 已删除的 WCKey 同样统计
+// @Tags slurm-accounting, report, wckey
+// @Produce json
+// @Param wckey query string false "WCKey 名称, 逗号分隔, 为空表示全部"
+// @Param start query int true "开始时间(Unix 秒), 含"
+// @Param end query int true "结束时间(Unix 秒), 不含"
+// @Param granularity query string false "统计粒度" Enums(hour, day, month) default(day)
+// @Param tres query string false "TRES 名称, 逗号分隔, 为空表示全部" example("cpu,gres/gpu")
+// @Param total query bool false "是否汇总整个时间范围" default(false)
+// @Param cluster query string false "集群名称, 默认为配置的集群"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/slurm/accounting/report/wckey [get]
+func HandlerGetWckeyUsage(c *gin.Context) {
+	client := clusterClient(c)
+	if client == nil {
+		return
+	}
+	q, err := parseUsageQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Response{Detail: err.Error()})
+		return
+	}
+
+	rows, err := client.GetWckeyUsage(c.Request.Context(), q, splitQuery(c, "wckey"))
+	if err != nil {
+		c.JSON(usageErrorStatus(err), response.Response{Detail: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, response.Response{Count: len(rows), Results: rows})
+}
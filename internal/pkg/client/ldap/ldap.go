@@ -306,6 +306,48 @@ func (c *Client) GetUser(ctx context.Context, uid string) (Attribute, error) {
 	return attrs, nil
 }
 
+// GetUserNamesByUIDNumber 一次查询 ou=Peoples,<c.BaseDN> 下 uidNumber 为 uidNumbers 的用户, 返回 uidNumber 到 uid 的映射.
+// 不存在的 uidNumber 不出现在结果中.
+func (c *Client) GetUserNamesByUIDNumber(ctx context.Context, uidNumbers []uint32) (map[uint32]string, error) {
+	if c == nil || c.Conn == nil {
+		return nil, fmt.Errorf("nil ldap client or connection")
+	}
+	out := make(map[uint32]string, len(uidNumbers))
+	if len(uidNumbers) == 0 {
+		return out, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("(&(uid=*)(|")
+	for _, n := range uidNumbers {
+		fmt.Fprintf(&sb, "(uidNumber=%d)", n)
+	}
+	sb.WriteString("))")
+	req := gldap.NewSearchRequest(
+		fmt.Sprintf("ou=Peoples,%s", c.BaseDN),
+		gldap.ScopeSingleLevel,
+		gldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		sb.String(),
+		[]string{"uid", "uidNumber"},
+		nil,
+	)
+	res, err := c.Conn.SearchWithPaging(req, 500)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range res.Entries {
+		n, err := strconv.ParseUint(e.GetAttributeValue("uidNumber"), 10, 32)
+		if err != nil {
+			continue
+		}
+		out[uint32(n)] = e.GetAttributeValue("uid")
+	}
+	return out, nil
+}
+
 // DelUser 删除ou=Peoples,<c.BaseDN> 下 uid 条目(用户).
 func (c *Client) DelUser(ctx context.Context, uid string) error {
 	if c == nil || c.Conn == nil {
@@ -0,0 +1,214 @@
+package sacctmgr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// modifyArgs 组装 modify <entity> where ... set ... 参数, set 为空时返回错误.
+func modifyArgs(entity string, where, set []string) ([]string, error) {
+	if len(set) == 0 {
+		return nil, fmt.Errorf("%w: nothing to modify", ErrInvalidSpec)
+	}
+	args := append([]string{"modify", entity, "where"}, where...)
+	args = append(args, "set")
+	return append(args, set...), nil
+}
+
+func addAccountArgs(spec AccountSpec) ([]string, error) {
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+	return append([]string{"add", "account", strings.TrimSpace(spec.Name)}, spec.args()...), nil
+}
+
+func modifyAccountArgs(spec AccountSpec) ([]string, error) {
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+	return modifyArgs("account", []string{"Name=" + strings.TrimSpace(spec.Name)}, spec.args())
+}
+
+func deleteArgs(entity, name string) ([]string, error) {
+	if err := validateName("name", name, true); err != nil {
+		return nil, err
+	}
+	return []string{"delete", entity, "where", "Name=" + strings.TrimSpace(name)}, nil
+}
+
+func addUserArgs(spec UserSpec) ([]string, error) {
+	if err := spec.validate(true); err != nil {
+		return nil, err
+	}
+	var a argList
+	a.add("Account", spec.Account)
+	a.add("Partition", spec.Partition)
+	args := append([]string{"add", "user", strings.TrimSpace(spec.Name)}, a...)
+	return append(args, spec.args()...), nil
+}
+
+func modifyUserArgs(spec UserSpec) ([]string, error) {
+	if err := spec.validate(false); err != nil {
+		return nil, err
+	}
+	var where argList
+	where.add("Name", spec.Name)
+	where.add("Account", spec.Account)
+	where.add("Partition", spec.Partition)
+	return modifyArgs("user", where, spec.args())
+}
+
+func addAssociationArgs(spec AssociationSpec) ([]string, error) {
+	if err := spec.validate(true); err != nil {
+		return nil, err
+	}
+	var a argList
+	a.add("Account", spec.Account)
+	a.add("Partition", spec.Partition)
+	args := append([]string{"add", "user", strings.TrimSpace(spec.User)}, a...)
+	return append(args, spec.AssocSettings.args()...), nil
+}
+
+func modifyAssociationArgs(spec AssociationSpec) ([]string, error) {
+	if err := spec.validate(false); err != nil {
+		return nil, err
+	}
+	entity := "user"
+	if strings.TrimSpace(spec.User) == "" {
+		entity = "account"
+	}
+	return modifyArgs(entity, spec.where(), spec.AssocSettings.args())
+}
+
+func deleteAssociationArgs(spec AssociationSpec) ([]string, error) {
+	if err := spec.validate(true); err != nil {
+		return nil, err
+	}
+	return append([]string{"delete", "user", "where"}, spec.where()...), nil
+}
+
+func coordinatorArgs(verb, account string, users []string) ([]string, error) {
+	if err := validateName("account", account, true); err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("%w: users is required", ErrInvalidSpec)
+	}
+	if err := validateNames("users", users); err != nil {
+		return nil, err
+	}
+	return []string{verb, "coordinator", "Account=" + strings.TrimSpace(account), "Names=" + joinTrimmed(users)}, nil
+}
+
+// runArgs 在参数组装成功后执行命令.
+func (c *Client) runArgs(ctx context.Context, action string, args []string, err error, operator string) (*Result, error) {
+	if err != nil {
+		return nil, err
+	}
+	return c.run(ctx, action, args, operator)
+}
+
+// AddAccount 创建账户: sacctmgr add account <name> Parent=... Description=...
+func (c *Client) AddAccount(ctx context.Context, spec AccountSpec, operator string) (*Result, error) {
+	args, err := addAccountArgs(spec)
+	args = scoped(args, c.cluster)
+	return c.runArgs(ctx, "add account", args, err, operator)
+}
+
+// ModifyAccount 修改账户属性及账户关联: sacctmgr modify account where Name=<name> set ...
+func (c *Client) ModifyAccount(ctx context.Context, spec AccountSpec, operator string) (*Result, error) {
+	args, err := modifyAccountArgs(spec)
+	args = scoped(args, c.cluster)
+	return c.runArgs(ctx, "modify account", args, err, operator)
+}
+
+// DeleteAccount 删除账户, 账户下仍有子账户或用户时 sacctmgr 会拒绝. root 与 Protect 设置的受保护账户返回 ErrProtected.
+func (c *Client) DeleteAccount(ctx context.Context, name, operator string) (*Result, error) {
+	if c.isProtected(name) {
+		return nil, fmt.Errorf("%w: %s", ErrProtected, strings.TrimSpace(name))
+	}
+	args, err := deleteArgs("account", name)
+	args = scoped(args, c.cluster)
+	return c.runArgs(ctx, "delete account", args, err, operator)
+}
+
+// AddUser 创建用户及其在 spec.Account 上的关联.
+func (c *Client) AddUser(ctx context.Context, spec UserSpec, operator string) (*Result, error) {
+	args, err := addUserArgs(spec)
+	args = scoped(args, c.cluster)
+	return c.runArgs(ctx, "add user", args, err, operator)
+}
+
+// ModifyUser 修改用户属性; Account/Partition 非空时只修改匹配的关联.
+func (c *Client) ModifyUser(ctx context.Context, spec UserSpec, operator string) (*Result, error) {
+	args, err := modifyUserArgs(spec)
+	args = scoped(args, c.cluster)
+	return c.runArgs(ctx, "modify user", args, err, operator)
+}
+
+// DeleteUser 删除用户及其全部关联.
+func (c *Client) DeleteUser(ctx context.Context, name, operator string) (*Result, error) {
+	args, err := deleteArgs("user", name)
+	args = scoped(args, c.cluster)
+	return c.runArgs(ctx, "delete user", args, err, operator)
+}
+
+// AddAssociation 为已有用户添加 (账户, 分区) 关联.
+func (c *Client) AddAssociation(ctx context.Context, spec AssociationSpec, operator string) (*Result, error) {
+	args, err := addAssociationArgs(spec)
+	args = scoped(args, c.cluster)
+	return c.runArgs(ctx, "add association", args, err, operator)
+}
+
+// ModifyAssociation 修改关联的公平共享、QoS 与限制; User 为空时修改账户关联.
+// User 非空且 Partition 为空时同时修改该用户在账户下的分区关联, 调用方须先确认不存在分区关联.
+func (c *Client) ModifyAssociation(ctx context.Context, spec AssociationSpec, operator string) (*Result, error) {
+	args, err := modifyAssociationArgs(spec)
+	args = scoped(args, c.cluster)
+	return c.runArgs(ctx, "modify association", args, err, operator)
+}
+
+// DeleteAssociation 删除用户在 (账户, 分区) 上的关联, 用户本身保留.
+// Partition 为空时同时删除该用户在账户下的分区关联, 调用方须先确认不存在分区关联.
+func (c *Client) DeleteAssociation(ctx context.Context, spec AssociationSpec, operator string) (*Result, error) {
+	args, err := deleteAssociationArgs(spec)
+	args = scoped(args, c.cluster)
+	return c.runArgs(ctx, "delete association", args, err, operator)
+}
+
+// AddQos 创建 QoS.
+func (c *Client) AddQos(ctx context.Context, spec QosSpec, operator string) (*Result, error) {
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+	args := append([]string{"add", "qos", strings.TrimSpace(spec.Name)}, spec.args()...)
+	return c.run(ctx, "add qos", args, operator)
+}
+
+// ModifyQos 修改 QoS.
+func (c *Client) ModifyQos(ctx context.Context, spec QosSpec, operator string) (*Result, error) {
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+	args, err := modifyArgs("qos", []string{"Name=" + strings.TrimSpace(spec.Name)}, spec.args())
+	return c.runArgs(ctx, "modify qos", args, err, operator)
+}
+
+// DeleteQos 删除 QoS.
+func (c *Client) DeleteQos(ctx context.Context, name, operator string) (*Result, error) {
+	args, err := deleteArgs("qos", name)
+	return c.runArgs(ctx, "delete qos", args, err, operator)
+}
+
+// AddCoordinator 将用户设为账户的协调员.
+func (c *Client) AddCoordinator(ctx context.Context, account string, users []string, operator string) (*Result, error) {
+	args, err := coordinatorArgs("add", account, users)
+	return c.runArgs(ctx, "add coordinator", args, err, operator)
+}
+
+// RemoveCoordinator 取消用户在账户上的协调员身份.
+func (c *Client) RemoveCoordinator(ctx context.Context, account string, users []string, operator string) (*Result, error) {
+	args, err := coordinatorArgs("delete", account, users)
+	return c.runArgs(ctx, "remove coordinator", args, err, operator)
+}
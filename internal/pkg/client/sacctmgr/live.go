@@ -0,0 +1,134 @@
+package sacctmgr
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"solid/internal/pkg/model"
+)
+
+// sharesUseParent slurmdbd 中 Fairshare=parent 的存储值(SLURMDB_FS_USE_PARENT).
+const sharesUseParent = 0x7fffffff
+
+// LiveAccount 数据库中现有的账户及其账户关联.
+type LiveAccount struct {
+	Name         string
+	Parent       string
+	Description  string
+	Organization string
+	Depth        int // 相对 root 的深度
+	Settings     AssocSettings
+}
+
+// LiveAssoc 数据库中现有的用户关联.
+type LiveAssoc struct {
+	Account   string
+	User      string
+	Partition string
+	Default   bool // 是否为用户的默认账户
+	Settings  AssocSettings
+}
+
+// LiveState 现有的账户/关联树, 设置以 sacctmgr 的名称与取值表示, 便于与期望状态比较.
+type LiveState struct {
+	Accounts map[string]*LiveAccount
+	Assocs   []LiveAssoc // 按 lft 排序
+}
+
+// NewLiveState 由 root 关联树、acct_table、tres_table 与 qos_table 构建现有状态.
+func NewLiveState(root *model.AssocTreeNode, accts model.Accounts, tres model.TresTable, qos model.Qoses) *LiveState {
+	qosNames := make(map[uint32]string, len(qos))
+	for _, q := range qos {
+		qosNames[uint32(q.ID)] = q.Name
+	}
+	info := make(map[string]model.Account, len(accts))
+	for _, a := range accts {
+		info[a.Name] = a
+	}
+	st := &LiveState{Accounts: make(map[string]*LiveAccount)}
+	var walk func(n *model.AssocTreeNode)
+	walk = func(n *model.AssocTreeNode) {
+		settings := liveSettings(n, tres, qosNames)
+		switch {
+		case n.IsUser():
+			st.Assocs = append(st.Assocs, LiveAssoc{Account: n.Acct, User: n.User, Partition: n.Partition, Default: n.IsDef == 1, Settings: settings})
+		case n.Partition == "":
+			a := info[n.Acct]
+			st.Accounts[n.Acct] = &LiveAccount{
+				Name: n.Acct, Parent: n.ParentAcct, Description: a.Description, Organization: a.Organization,
+				Depth: n.Depth, Settings: settings,
+			}
+		}
+		for _, ch := range n.Children {
+			walk(ch)
+		}
+	}
+	if root != nil {
+		walk(root)
+	}
+	return st
+}
+
+// liveSettings 将关联自身设置的 fairshare、QoS 与限制转换为 sacctmgr 的表示.
+func liveSettings(n *model.AssocTreeNode, tres model.TresTable, qosNames map[uint32]string) AssocSettings {
+	s := AssocSettings{Fairshare: strconv.Itoa(int(n.Shares)), Limits: make(map[string]string)}
+	if n.Shares == sharesUseParent {
+		s.Fairshare = "parent"
+	}
+	for _, v := range strings.Split(n.Limits.QOS, ",") {
+		if id, err := strconv.ParseUint(v, 10, 32); err == nil && qosNames[uint32(id)] != "" {
+			s.QOS = append(s.QOS, qosNames[uint32(id)])
+		}
+	}
+	sort.Strings(s.QOS)
+	if id := n.Limits.DefQosID; id != nil && *id > 0 {
+		s.DefaultQOS = qosNames[uint32(*id)]
+	}
+
+	setInt := func(key string, v *int32) {
+		if v != nil && *v != -1 {
+			s.Limits[key] = strconv.Itoa(int(*v))
+		}
+	}
+	setTres := func(key, v string) {
+		if v = tresNames(v, tres); v != "" {
+			s.Limits[key] = v
+		}
+	}
+	l, g := n.Limits, n.GrpLimits
+	setInt("MaxJobs", l.MaxJobs)
+	setInt("MaxJobsAccrue", l.MaxJobsAccrue)
+	setInt("MaxSubmitJobs", l.MaxSubmitJobs)
+	setInt("MaxWall", l.MaxWallPJ)
+	if l.Priority != nil {
+		s.Limits["Priority"] = strconv.FormatUint(uint64(*l.Priority), 10)
+	}
+	setTres("MaxTRES", l.MaxTresPJ)
+	setTres("MaxTRESPerNode", l.MaxTresPN)
+	setTres("MaxTRESMins", l.MaxTresMinsPJ)
+	setTres("MaxTRESRunMins", l.MaxTresRunMins)
+	setInt("GrpJobs", g.GrpJobs)
+	setInt("GrpJobsAccrue", g.GrpJobsAccrue)
+	setInt("GrpSubmitJobs", g.GrpSubmitJobs)
+	setInt("GrpWall", g.GrpWall)
+	setTres("GrpTRES", g.GrpTres)
+	setTres("GrpTRESMins", g.GrpTresMins)
+	setTres("GrpTRESRunMins", g.GrpTresRunMins)
+	return s
+}
+
+// tresNames 将 "1=8,1001=2" 形式的 TRES 字符串转换为 sacctmgr 使用的 "cpu=8,gres/gpu=2".
+func tresNames(s string, tres model.TresTable) string {
+	m := model.ParseTresString(s)
+	parts := make([]string, 0, len(m))
+	for id, v := range m {
+		name := strconv.FormatUint(uint64(id), 10)
+		if t, ok := tres[id]; ok {
+			name = t.FullName()
+		}
+		parts = append(parts, name+"="+v)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
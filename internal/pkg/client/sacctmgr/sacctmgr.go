@@ -0,0 +1,163 @@
+package sacctmgr
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+)
+
+// Package-level default Client for convenience wiring.
+var defaultClient *Client
+
+// SetDefault sets the package-level default sacctmgr Client.
+func SetDefault(c *Client) { defaultClient = c }
+
+// Default returns the package-level default sacctmgr Client.
+func Default() *Client { return defaultClient }
+
+// ExecCommandFunc 定义 exec.CommandContext 的函数签名，方便 mock 测试.
+type ExecCommandFunc func(ctx context.Context, name string, args ...string) *exec.Cmd
+
+// ErrInvalidSpec 参数校验失败, 命令不会被执行.
+var ErrInvalidSpec = errors.New("invalid sacctmgr spec")
+
+// ErrProtected 操作的对象是受保护的账户, 命令不会被执行.
+var ErrProtected = errors.New("account is protected")
+
+// ErrConflict 操作会波及请求之外的关联, 命令不会被执行. sacctmgr 无法在条件中表示"不区分分区",
+// Partition 为空的条件会同时匹配该用户在账户下的分区关联.
+var ErrConflict = errors.New("association conflict")
+
+// Client 通过 sacctmgr -i -P 管理账户、用户、关联与 QoS.
+// slurmdb.Client 是只读的, 所有写操作都经由此客户端完成.
+type Client struct {
+	execCommand ExecCommandFunc
+	logger      *slog.Logger
+	dryRun      bool
+	protected   []string
+	cluster     string
+}
+
+func (c *Client) Set(exec ExecCommandFunc, logger *slog.Logger) *Client {
+	c.execCommand = exec
+	c.logger = logger
+	return c
+}
+
+// DryRun 返回一个只生成命令而不执行的客户端副本.
+func (c *Client) DryRun() *Client {
+	cp := *c
+	cp.dryRun = true
+	return &cp
+}
+
+// WithCluster 返回操作指定集群的客户端副本, 账户、用户与关联命令会加上 Cluster=<name>; name 为空时不限定集群.
+// QoS 与协调员不属于集群, 不受影响.
+func (c *Client) WithCluster(name string) *Client {
+	cp := *c
+	cp.cluster = strings.TrimSpace(name)
+	return &cp
+}
+
+// scoped 为账户、用户与关联命令加上 Cluster=<cluster>, modify 命令的条件须位于 set 之前.
+func scoped(args []string, cluster string) []string {
+	if cluster == "" || len(args) == 0 {
+		return args
+	}
+	out := make([]string, 0, len(args)+1)
+	if args[0] == "modify" {
+		for i, a := range args {
+			if a == "set" {
+				out = append(append(out, args[:i]...), "Cluster="+cluster)
+				return append(out, args[i:]...)
+			}
+		}
+	}
+	return append(append(out, args...), "Cluster="+cluster)
+}
+
+// Section sacctmgr 输出中的一段, 如 "Adding Account(s)" 及其下的条目.
+type Section struct {
+	Title   string   `json:"title"`
+	Entries []string `json:"entries"`
+}
+
+// Result 一次 sacctmgr 调用的结果.
+type Result struct {
+	Command  string    `json:"command"`            // 实际(或将要)执行的命令
+	DryRun   bool      `json:"dry_run"`            // 是否仅生成命令
+	Changed  bool      `json:"changed"`            // sacctmgr 是否报告了变更
+	Sections []Section `json:"sections,omitempty"` // 解析后的输出段落
+	Messages []string  `json:"messages,omitempty"` // 不属于任何段落的提示, 如 "Nothing new added."
+}
+
+// run 执行 sacctmgr -i -P <args>, 在 dry-run 模式下只返回命令.
+func (c *Client) run(ctx context.Context, action string, args []string, operator string) (*Result, error) {
+	args = append([]string{"-i", "-P"}, args...)
+	res := &Result{Command: commandLine("sacctmgr", args), DryRun: c.dryRun}
+	if c.dryRun {
+		c.logger.Info("sacctmgr dry run", "action", action, "cmd", res.Command, "operator", operator)
+		return res, nil
+	}
+	cmd := c.execCommand(ctx, "sacctmgr", args...)
+	out, err := cmd.CombinedOutput()
+	parseOutput(string(out), res)
+	if err != nil && (res.Changed || !nothingChanged(res)) {
+		c.logger.Error("unable to "+action, "output", string(out), "cmd", cmd.String(), "err", err)
+		return nil, fmt.Errorf("failed to exec %s: %s", res.Command, strings.TrimSpace(string(out)))
+	}
+	c.logger.Info(action+" done", "cmd", res.Command, "changed", res.Changed, "operator", operator)
+	return res, nil
+}
+
+// nothingChanged 判断输出是否为 "Nothing new added." 之类的无变更提示,
+// sacctmgr 在这种情况下也会返回非零退出码.
+func nothingChanged(res *Result) bool {
+	for _, m := range res.Messages {
+		if strings.HasPrefix(m, "Nothing") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseOutput 解析 sacctmgr 的输出. 以单个空格缩进的行是段落标题, 如 " Adding Account(s)";
+// 更深缩进的行是该段落的条目, 如 "  phys" 与 "  Description     = physics"; 其余行作为提示信息.
+func parseOutput(out string, res *Result) {
+	var cur *Section
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		text := strings.Join(strings.Fields(line), " ")
+		switch {
+		case text == "":
+			continue
+		case strings.HasPrefix(line, "  ") && cur != nil:
+			cur.Entries = append(cur.Entries, text)
+		case strings.HasPrefix(line, " ") && !strings.HasPrefix(text, "Nothing"):
+			res.Sections = append(res.Sections, Section{Title: text, Entries: []string{}})
+			cur = &res.Sections[len(res.Sections)-1]
+		default:
+			res.Messages = append(res.Messages, text)
+			cur = nil
+		}
+	}
+	res.Changed = len(res.Sections) > 0 && !nothingChanged(res)
+}
+
+// commandLine 渲染可直接粘贴到 shell 中执行的命令.
+func commandLine(name string, args []string) string {
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, name)
+	for _, a := range args {
+		if strings.ContainsAny(a, " \t'\"\\$`;&|<>()*?!#~") {
+			a = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+		}
+		parts = append(parts, a)
+	}
+	return strings.Join(parts, " ")
+}
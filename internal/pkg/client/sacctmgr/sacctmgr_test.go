@@ -0,0 +1,142 @@
+package sacctmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestParseOutput(t *testing.T) {
+	out := ` Adding Account(s)
+  phys
+ Settings
+  Description     = physics department
+  Organization    = sci
+ Associations
+  A = phys       C = cluster
+`
+	var res Result
+	parseOutput(out, &res)
+	if !res.Changed || len(res.Sections) != 3 || len(res.Messages) != 0 {
+		t.Fatalf("parseOutput() = %+v", res)
+	}
+	if s := res.Sections[0]; s.Title != "Adding Account(s)" || fmt.Sprint(s.Entries) != "[phys]" {
+		t.Errorf("parseOutput().Sections[0] = %+v", s)
+	}
+	if e := res.Sections[1].Entries[0]; e != "Description = physics department" {
+		t.Errorf("parseOutput().Sections[1].Entries[0] = %q", e)
+	}
+
+	res = Result{}
+	parseOutput(" Nothing new added.\n", &res)
+	if res.Changed || !nothingChanged(&res) {
+		t.Errorf("parseOutput(nothing) = %+v", res)
+	}
+
+	res = Result{}
+	parseOutput("sacctmgr: error: Parent account bogus doesn't exist.\n", &res)
+	if res.Changed || nothingChanged(&res) || len(res.Messages) != 1 {
+		t.Errorf("parseOutput(error) = %+v", res)
+	}
+}
+
+func TestDryRunCommands(t *testing.T) {
+	c := (&Client{}).Set(nil, slog.New(slog.NewTextHandler(io.Discard, nil))).DryRun()
+	ctx := context.Background()
+	prio := 10
+	cases := []struct {
+		run  func() (*Result, error)
+		want string
+	}{
+		{func() (*Result, error) {
+			return c.AddAccount(ctx, AccountSpec{Name: "phys", Parent: "sci", Description: "physics dept",
+				AssocSettings: AssocSettings{Fairshare: "10", Limits: map[string]string{"maxjobs": "50", "GrpTRES": "cpu=100"}}}, "admin")
+		}, "sacctmgr -i -P add account phys Parent=sci 'Description=physics dept' Fairshare=10 GrpTRES=cpu=100 MaxJobs=50"},
+		{func() (*Result, error) {
+			return c.ModifyUser(ctx, UserSpec{Name: "alice", Account: "phys", AdminLevel: "operator"}, "admin")
+		}, "sacctmgr -i -P modify user where Name=alice Account=phys set AdminLevel=Operator"},
+		{func() (*Result, error) {
+			return c.ModifyAssociation(ctx, AssociationSpec{Account: "phys", AssocSettings: AssocSettings{QOS: []string{"normal", "high"}}}, "admin")
+		}, "sacctmgr -i -P modify account where Name=phys set QOS=normal,high"},
+		{func() (*Result, error) {
+			return c.DeleteAssociation(ctx, AssociationSpec{Account: "phys", User: "alice", Partition: "gpu"}, "admin")
+		}, "sacctmgr -i -P delete user where Name=alice Account=phys Partition=gpu"},
+		{func() (*Result, error) {
+			return c.AddQos(ctx, QosSpec{Name: "high", Priority: &prio, Flags: []string{"denyonlimit"}, PreemptMode: []string{"requeue"}}, "admin")
+		}, "sacctmgr -i -P add qos high Priority=10 Flags=DenyOnLimit PreemptMode=REQUEUE"},
+		{func() (*Result, error) {
+			return c.AddCoordinator(ctx, "phys", []string{"alice", "bob"}, "admin")
+		}, "sacctmgr -i -P add coordinator Account=phys Names=alice,bob"},
+	}
+	for i, tc := range cases {
+		res, err := tc.run()
+		if err != nil {
+			t.Errorf("case %d: unexpected error %v", i, err)
+			continue
+		}
+		if !res.DryRun || res.Command != tc.want {
+			t.Errorf("case %d: command = %q, want %q", i, res.Command, tc.want)
+		}
+	}
+
+	// 指定集群时账户、用户与关联命令加上 Cluster=, QoS 与协调员不受影响
+	cc := c.WithCluster("th2")
+	scopedCases := []struct {
+		run  func() (*Result, error)
+		want string
+	}{
+		{func() (*Result, error) { return cc.AddAccount(ctx, AccountSpec{Name: "phys", Parent: "sci"}, "admin") },
+			"sacctmgr -i -P add account phys Parent=sci Cluster=th2"},
+		{func() (*Result, error) {
+			return cc.ModifyUser(ctx, UserSpec{Name: "alice", Account: "phys", AdminLevel: "operator"}, "admin")
+		}, "sacctmgr -i -P modify user where Name=alice Account=phys Cluster=th2 set AdminLevel=Operator"},
+		{func() (*Result, error) {
+			return cc.DeleteAssociation(ctx, AssociationSpec{Account: "phys", User: "alice", Partition: "gpu"}, "admin")
+		}, "sacctmgr -i -P delete user where Name=alice Account=phys Partition=gpu Cluster=th2"},
+		{func() (*Result, error) { return cc.AddCoordinator(ctx, "phys", []string{"alice"}, "admin") },
+			"sacctmgr -i -P add coordinator Account=phys Names=alice"},
+	}
+	for i, tc := range scopedCases {
+		res, err := tc.run()
+		if err != nil {
+			t.Errorf("scoped case %d: unexpected error %v", i, err)
+			continue
+		}
+		if res.Command != tc.want {
+			t.Errorf("scoped case %d: command = %q, want %q", i, res.Command, tc.want)
+		}
+	}
+
+	bad := []func() (*Result, error){
+		// 缺少账户
+		func() (*Result, error) { return c.AddUser(ctx, UserSpec{Name: "alice"}, "admin") },
+		// 无修改
+		func() (*Result, error) { return c.ModifyAccount(ctx, AccountSpec{Name: "phys"}, "admin") },
+		// 非法名称
+		func() (*Result, error) { return c.DeleteAccount(ctx, "phys where", "admin") },
+		// 不支持的 QoS 限制
+		func() (*Result, error) {
+			return c.ModifyQos(ctx, QosSpec{Name: "high", Limits: map[string]string{"MaxJobs": "1"}}, "admin")
+		},
+	}
+	for i, run := range bad {
+		if _, err := run(); !errors.Is(err, ErrInvalidSpec) {
+			t.Errorf("bad[%d]: err = %v, want ErrInvalidSpec", i, err)
+		}
+	}
+}
+
+func TestDeleteProtectedAccount(t *testing.T) {
+	c := (&Client{}).Set(nil, slog.New(slog.NewTextHandler(io.Discard, nil))).Protect("admin").DryRun()
+	for _, name := range []string{"root", "admin", " admin "} {
+		if _, err := c.DeleteAccount(context.Background(), name, "ops"); !errors.Is(err, ErrProtected) {
+			t.Errorf("DeleteAccount(%q) err = %v, want ErrProtected", name, err)
+		}
+	}
+	if _, err := c.DeleteAccount(context.Background(), "phys", "ops"); err != nil {
+		t.Errorf("DeleteAccount(phys) err = %v", err)
+	}
+}
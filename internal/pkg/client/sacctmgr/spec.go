@@ -0,0 +1,342 @@
+package sacctmgr
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// nameRe 账户、用户、分区与 QoS 名称, 不允许空白、逗号与等号.
+	nameRe = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.@-]*$`)
+	// limitValueRe 限制取值, 如 10, -1, cpu=100,gres/gpu=4, 1-00:00:00.
+	limitValueRe = regexp.MustCompile(`^[A-Za-z0-9=,/:._+-]+$`)
+)
+
+// assocLimitKeys 关联支持的限制名称, 键为小写形式.
+var assocLimitKeys = canonicalKeys(
+	"GrpJobs", "GrpJobsAccrue", "GrpSubmitJobs", "GrpTRES", "GrpTRESMins", "GrpTRESRunMins", "GrpWall",
+	"MaxJobs", "MaxJobsAccrue", "MaxSubmitJobs", "MaxTRES", "MaxTRESMins", "MaxTRESPerNode", "MaxTRESRunMins",
+	"MaxWall", "Priority",
+)
+
+// qosLimitKeys QoS 支持的限制名称, 键为小写形式.
+var qosLimitKeys = canonicalKeys(
+	"GrpJobs", "GrpJobsAccrue", "GrpSubmitJobs", "GrpTRES", "GrpTRESMins", "GrpTRESRunMins", "GrpWall",
+	"MaxJobsPerAccount", "MaxJobsPerUser", "MaxJobsAccruePerAccount", "MaxJobsAccruePerUser",
+	"MaxSubmitJobsPerAccount", "MaxSubmitJobsPerUser",
+	"MaxTRESPerAccount", "MaxTRESPerJob", "MaxTRESPerNode", "MaxTRESPerUser",
+	"MaxTRESMinsPerJob", "MaxTRESRunMinsPerAccount", "MaxTRESRunMinsPerUser",
+	"MaxWall", "MinTRESPerJob", "GraceTime", "UsageThreshold",
+)
+
+// qosFlags sacctmgr 支持的 QoS 标志.
+var qosFlags = canonicalKeys(
+	"DenyOnLimit", "EnforceUsageThreshold", "NoDecay", "NoReserve", "OverPartQOS",
+	"PartitionMaxNodes", "PartitionMinNodes", "PartitionTimeLimit", "RequiresReservation",
+	"UsageFactorSafe", "Relative",
+)
+
+// preemptModes sacctmgr 支持的 QoS 抢占模式.
+var preemptModes = canonicalKeys("OFF", "CANCEL", "REQUEUE", "SUSPEND", "GANG", "WITHIN")
+
+// adminLevels sacctmgr 支持的用户管理级别.
+var adminLevels = canonicalKeys("None", "Operator", "Administrator")
+
+func canonicalKeys(names ...string) map[string]string {
+	m := make(map[string]string, len(names))
+	for _, n := range names {
+		m[strings.ToLower(n)] = n
+	}
+	return m
+}
+
+// AssocSettings 关联上的公平共享、QoS 与资源限制, 零值字段表示不设置.
+type AssocSettings struct {
+	Fairshare  string            `json:"fairshare,omitempty" yaml:"fairshare,omitempty"`     // 共享值: 正整数或 parent, -1 恢复默认
+	QOS        []string          `json:"qos,omitempty" yaml:"qos,omitempty"`                 // 可用 QoS 列表
+	DefaultQOS string            `json:"default_qos,omitempty" yaml:"default_qos,omitempty"` // 默认 QoS
+	Limits     map[string]string `json:"limits,omitempty" yaml:"limits,omitempty"`           // 限制名称到取值, 如 GrpTRES: cpu=100, MaxWall: 1-00:00:00; -1 清除
+}
+
+func (s AssocSettings) validate() error {
+	if v := strings.TrimSpace(s.Fairshare); v != "" && !strings.EqualFold(v, "parent") {
+		if n, err := strconv.Atoi(v); err != nil || n < -1 {
+			return invalid("fairshare", v)
+		}
+	}
+	if err := validateNames("qos", s.QOS); err != nil {
+		return err
+	}
+	if v := strings.TrimSpace(s.DefaultQOS); v != "" && !nameRe.MatchString(v) {
+		return invalid("default_qos", v)
+	}
+	return validateLimits(s.Limits, assocLimitKeys)
+}
+
+func (s AssocSettings) args() []string {
+	var a argList
+	a.add("Fairshare", s.Fairshare)
+	a.add("QOS", joinTrimmed(s.QOS))
+	a.add("DefaultQOS", s.DefaultQOS)
+	a.addLimits(s.Limits, assocLimitKeys)
+	return a
+}
+
+// AccountSpec 创建或修改账户的参数.
+type AccountSpec struct {
+	Name         string `json:"name"`         // 账户名称
+	Parent       string `json:"parent"`       // 父账户, 创建时为空则挂在 root 下
+	Description  string `json:"description"`  // 描述, 创建时为空则与名称相同
+	Organization string `json:"organization"` // 组织, 创建时为空则与父账户相同
+	AssocSettings
+}
+
+func (s AccountSpec) validate() error {
+	if err := validateName("name", s.Name, true); err != nil {
+		return err
+	}
+	if err := validateName("parent", s.Parent, false); err != nil {
+		return err
+	}
+	if err := validateText("description", s.Description); err != nil {
+		return err
+	}
+	if err := validateText("organization", s.Organization); err != nil {
+		return err
+	}
+	return s.AssocSettings.validate()
+}
+
+func (s AccountSpec) args() []string {
+	var a argList
+	a.add("Parent", s.Parent)
+	a.add("Description", s.Description)
+	a.add("Organization", s.Organization)
+	return append(a, s.AssocSettings.args()...)
+}
+
+// UserSpec 创建或修改用户的参数.
+// 创建时 Account 必填, 同时创建该用户在 Account(及 Partition)上的关联;
+// 修改时 Account/Partition 用于限定修改哪些关联, 为空表示该用户的全部关联.
+type UserSpec struct {
+	Name           string `json:"name"`            // 用户名
+	Account        string `json:"account"`         // 账户
+	Partition      string `json:"partition"`       // 分区
+	DefaultAccount string `json:"default_account"` // 默认账户
+	AdminLevel     string `json:"admin_level"`     // 管理级别: None, Operator, Administrator
+	AssocSettings
+}
+
+func (s UserSpec) validate(create bool) error {
+	if err := validateName("name", s.Name, true); err != nil {
+		return err
+	}
+	if err := validateName("account", s.Account, create); err != nil {
+		return err
+	}
+	if err := validateName("partition", s.Partition, false); err != nil {
+		return err
+	}
+	if err := validateName("default_account", s.DefaultAccount, false); err != nil {
+		return err
+	}
+	if v := strings.TrimSpace(s.AdminLevel); v != "" {
+		if _, ok := adminLevels[strings.ToLower(v)]; !ok {
+			return invalid("admin_level", v)
+		}
+	}
+	return s.AssocSettings.validate()
+}
+
+func (s UserSpec) args() []string {
+	var a argList
+	a.add("DefaultAccount", s.DefaultAccount)
+	if v := strings.TrimSpace(s.AdminLevel); v != "" {
+		a.add("AdminLevel", adminLevels[strings.ToLower(v)])
+	}
+	return append(a, s.AssocSettings.args()...)
+}
+
+// AssociationSpec 用户关联 (用户, 账户, 分区) 的参数.
+// 修改时 User 为空表示修改账户自身的关联.
+type AssociationSpec struct {
+	Account   string `json:"account"`   // 账户
+	User      string `json:"user"`      // 用户
+	Partition string `json:"partition"` // 分区, 为空表示不区分分区的关联
+	AssocSettings
+}
+
+func (s AssociationSpec) validate(needUser bool) error {
+	if err := validateName("account", s.Account, true); err != nil {
+		return err
+	}
+	if err := validateName("user", s.User, needUser); err != nil {
+		return err
+	}
+	if err := validateName("partition", s.Partition, false); err != nil {
+		return err
+	}
+	return s.AssocSettings.validate()
+}
+
+// where 返回定位该关联的条件. Partition 为空时 sacctmgr 不限制分区, 条件同时匹配该用户在账户下的分区关联,
+// 调用方须先确认不存在分区关联, 否则操作会波及这些关联.
+func (s AssociationSpec) where() []string {
+	var a argList
+	if strings.TrimSpace(s.User) == "" {
+		a.add("Name", s.Account)
+	} else {
+		a.add("Name", s.User)
+		a.add("Account", s.Account)
+	}
+	a.add("Partition", s.Partition)
+	return a
+}
+
+// QosSpec 创建或修改 QoS 的参数.
+type QosSpec struct {
+	Name        string            `json:"name"`         // QoS 名称
+	Description string            `json:"description"`  // 描述
+	Priority    *int              `json:"priority"`     // 优先级
+	Flags       []string          `json:"flags"`        // QoS 标志, 修改时整体替换
+	PreemptMode []string          `json:"preempt_mode"` // 抢占模式
+	Preempt     []string          `json:"preempt"`      // 可抢占的 QoS
+	UsageFactor string            `json:"usage_factor"` // 用量系数
+	Limits      map[string]string `json:"limits"`       // 限制名称到取值, 如 MaxTRESPerUser: cpu=64; -1 清除
+}
+
+func (s QosSpec) validate() error {
+	if err := validateName("name", s.Name, true); err != nil {
+		return err
+	}
+	if err := validateText("description", s.Description); err != nil {
+		return err
+	}
+	if s.Priority != nil && *s.Priority < -1 {
+		return invalid("priority", strconv.Itoa(*s.Priority))
+	}
+	for _, f := range s.Flags {
+		if _, ok := qosFlags[strings.ToLower(strings.TrimSpace(f))]; !ok {
+			return invalid("flag", f)
+		}
+	}
+	for _, m := range s.PreemptMode {
+		if _, ok := preemptModes[strings.ToLower(strings.TrimSpace(m))]; !ok {
+			return invalid("preempt_mode", m)
+		}
+	}
+	if err := validateNames("preempt", s.Preempt); err != nil {
+		return err
+	}
+	if v := strings.TrimSpace(s.UsageFactor); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err != nil || (f < 0 && f != -1) {
+			return invalid("usage_factor", v)
+		}
+	}
+	return validateLimits(s.Limits, qosLimitKeys)
+}
+
+func (s QosSpec) args() []string {
+	var a argList
+	a.add("Description", s.Description)
+	if s.Priority != nil {
+		a.add("Priority", strconv.Itoa(*s.Priority))
+	}
+	a.add("Flags", joinCanonical(s.Flags, qosFlags))
+	a.add("PreemptMode", joinCanonical(s.PreemptMode, preemptModes))
+	a.add("Preempt", joinTrimmed(s.Preempt))
+	a.add("UsageFactor", s.UsageFactor)
+	a.addLimits(s.Limits, qosLimitKeys)
+	return a
+}
+
+// argList sacctmgr 的 key=value 参数列表.
+type argList []string
+
+func (a *argList) add(key, val string) {
+	if val = strings.TrimSpace(val); val != "" {
+		*a = append(*a, key+"="+val)
+	}
+}
+
+// addLimits 按名称排序添加限制, 保证生成的命令稳定.
+func (a *argList) addLimits(limits map[string]string, keys map[string]string) {
+	names := make([]string, 0, len(limits))
+	for k := range limits {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		a.add(keys[strings.ToLower(strings.TrimSpace(k))], limits[k])
+	}
+}
+
+func validateName(field, v string, required bool) error {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		if required {
+			return fmt.Errorf("%w: %s is required", ErrInvalidSpec, field)
+		}
+		return nil
+	}
+	if !nameRe.MatchString(v) {
+		return invalid(field, v)
+	}
+	return nil
+}
+
+func validateNames(field string, names []string) error {
+	for _, n := range names {
+		if !nameRe.MatchString(strings.TrimSpace(n)) {
+			return invalid(field, n)
+		}
+	}
+	return nil
+}
+
+// validateText 校验描述类自由文本, 命令不经过 shell, 只需排除引号与控制字符.
+func validateText(field, v string) error {
+	if len(v) > 256 || strings.ContainsAny(v, "\"'") || strings.ContainsFunc(v, func(r rune) bool { return r < 0x20 || r == 0x7f }) {
+		return invalid(field, v)
+	}
+	return nil
+}
+
+func validateLimits(limits map[string]string, keys map[string]string) error {
+	for k, v := range limits {
+		if _, ok := keys[strings.ToLower(strings.TrimSpace(k))]; !ok {
+			return fmt.Errorf("%w: unsupported limit %q", ErrInvalidSpec, k)
+		}
+		if !limitValueRe.MatchString(strings.TrimSpace(v)) {
+			return invalid(k, v)
+		}
+	}
+	return nil
+}
+
+func invalid(field, v string) error {
+	return fmt.Errorf("%w: invalid %s: %q", ErrInvalidSpec, field, v)
+}
+
+func joinTrimmed(vals []string) string {
+	out := make([]string, 0, len(vals))
+	for _, v := range vals {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return strings.Join(out, ",")
+}
+
+func joinCanonical(vals []string, keys map[string]string) string {
+	out := make([]string, 0, len(vals))
+	for _, v := range vals {
+		if c, ok := keys[strings.ToLower(strings.TrimSpace(v))]; ok {
+			out = append(out, c)
+		}
+	}
+	return strings.Join(out, ",")
+}
@@ -0,0 +1,543 @@
+package sacctmgr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"solid/internal/pkg/client/slurmctl"
+)
+
+// TreeSpec 期望的账户/关联树, 可由 YAML 或 JSON 描述. 设置中留空的字段不受管理;
+// limits 一旦给出即视为完整的限制集合, 现有但未列出的限制会被清除.
+type TreeSpec struct {
+	Protected []string      `json:"protected" yaml:"protected"` // 除 root 与客户端配置外额外受保护的账户
+	Accounts  []AccountNode `json:"accounts" yaml:"accounts"`   // 期望存在的账户, root 为隐式根节点
+}
+
+// AccountNode 期望存在的账户.
+type AccountNode struct {
+	Name          string `json:"name" yaml:"name"`                 // 账户名称
+	Parent        string `json:"parent" yaml:"parent"`             // 父账户, 为空表示 root
+	Description   string `json:"description" yaml:"description"`   // 描述
+	Organization  string `json:"organization" yaml:"organization"` // 组织
+	AssocSettings `yaml:",inline"`
+	Users         []UserNode `json:"users" yaml:"users"` // 账户下的用户关联
+}
+
+// UserNode 账户下期望存在的用户关联.
+type UserNode struct {
+	Name          string   `json:"name" yaml:"name"`             // 用户名
+	Partitions    []string `json:"partitions" yaml:"partitions"` // 分区, 为空表示不区分分区的关联
+	AssocSettings `yaml:",inline"`
+}
+
+// SyncOp 同步计划中的一个 sacctmgr 操作.
+type SyncOp struct {
+	Action  string   `json:"action"`            // add_account, modify_account, add_user, add_association, ...
+	Target  string   `json:"target"`            // 操作对象, 如 account phys, user alice@phys
+	Changes []string `json:"changes,omitempty"` // 修改前后的取值, 如 "fairshare: 1 -> 10"
+	Command string   `json:"command"`           // 将要执行的命令
+	Status  string   `json:"status"`            // planned, applied, failed, not_run
+	Result  *Result  `json:"result,omitempty"`  // 执行结果
+	Error   string   `json:"error,omitempty"`   // 执行错误
+
+	args []string
+}
+
+// SyncSkip 因保护而未执行的变更.
+type SyncSkip struct {
+	Target string `json:"target"`
+	Reason string `json:"reason"`
+}
+
+// SyncPlan 期望树与现有树的差异及对应的操作, 操作按执行顺序排列.
+type SyncPlan struct {
+	Ops     []*SyncOp  `json:"ops"`
+	Skipped []SyncSkip `json:"skipped,omitempty"`
+	Applied bool       `json:"applied"`
+}
+
+// Protect 将账户加入受保护列表, 受保护的账户及其子树不会因缺席于期望树而被删除. root 始终受保护.
+func (c *Client) Protect(accounts ...string) *Client {
+	for _, a := range accounts {
+		if a = strings.TrimSpace(a); a != "" {
+			c.protected = append(c.protected, a)
+		}
+	}
+	return c
+}
+
+// isProtected 判断账户是否为 root 或受保护账户.
+func (c *Client) isProtected(account string) bool {
+	account = strings.TrimSpace(account)
+	return account == "root" || contains(c.protected, account)
+}
+
+// PlanSync 对比期望树与现有树, 生成 sacctmgr 操作计划, 不执行任何命令. 现有树须来自客户端所操作的集群.
+func (c *Client) PlanSync(spec TreeSpec, live *LiveState) (*SyncPlan, error) {
+	protected := map[string]bool{"root": true}
+	for _, a := range append(append([]string{}, c.protected...), spec.Protected...) {
+		protected[strings.TrimSpace(a)] = true
+	}
+	return planSync(spec, live, protected, c.cluster)
+}
+
+// ApplySync 依次执行计划中的操作, 遇到第一个失败即停止, 其后的操作标记为 not_run.
+func (c *Client) ApplySync(ctx context.Context, plan *SyncPlan, operator string) error {
+	for i, op := range plan.Ops {
+		res, err := c.run(ctx, strings.ReplaceAll(op.Action, "_", " "), op.args, operator)
+		if err != nil {
+			op.Status, op.Error = "failed", err.Error()
+			for _, rest := range plan.Ops[i+1:] {
+				rest.Status = "not_run"
+			}
+			return fmt.Errorf("sync stopped at %s %s: %w", op.Action, op.Target, err)
+		}
+		op.Status, op.Result = "applied", res
+	}
+	plan.Applied = !c.dryRun
+	return nil
+}
+
+// assocKey 用户关联的唯一标识.
+type assocKey struct{ account, user, partition string }
+
+func (k assocKey) String() string {
+	s := "user " + k.user + "@" + k.account
+	if k.partition != "" {
+		s += "[" + k.partition + "]"
+	}
+	return s
+}
+
+type planner struct {
+	plan    *SyncPlan
+	cluster string
+	err     error
+}
+
+func (p *planner) add(action, target string, changes []string, args []string, err error) {
+	if err != nil {
+		if p.err == nil {
+			p.err = fmt.Errorf("%s: %w", target, err)
+		}
+		return
+	}
+	args = scoped(args, p.cluster)
+	p.plan.Ops = append(p.plan.Ops, &SyncOp{
+		Action: action, Target: target, Changes: changes, Status: "planned",
+		Command: commandLine("sacctmgr", append([]string{"-i", "-P"}, args...)), args: args,
+	})
+}
+
+// planSync 生成同步计划. 操作顺序: 新增账户(父账户在前)、修改账户、新增用户与关联、修改关联、
+// 调整默认账户、删除关联与用户、删除账户(子账户在前). 受保护账户的子树只在期望树中出现时才被管理.
+// cluster 非空时所有命令都限定在该集群上.
+func planSync(spec TreeSpec, live *LiveState, protected map[string]bool, cluster string) (*SyncPlan, error) {
+	want, order, err := spec.accounts(live, protected)
+	if err != nil {
+		return nil, err
+	}
+	p := &planner{plan: &SyncPlan{Ops: []*SyncOp{}}, cluster: cluster}
+
+	// 账户
+	for _, name := range order {
+		a := want[name]
+		parent := a.parent()
+		cur, ok := live.Accounts[name]
+		if !ok {
+			args, err := addAccountArgs(AccountSpec{Name: name, Parent: parent, Description: a.Description, Organization: a.Organization, AssocSettings: a.AssocSettings})
+			p.add("add_account", "account "+name, nil, args, err)
+			continue
+		}
+		mod := AccountSpec{Name: name}
+		var changes []string
+		if cur.Parent != parent {
+			mod.Parent = parent
+			changes = append(changes, change("parent", cur.Parent, parent))
+		}
+		if a.Description != "" && a.Description != cur.Description {
+			mod.Description = a.Description
+			changes = append(changes, change("description", cur.Description, a.Description))
+		}
+		if a.Organization != "" && a.Organization != cur.Organization {
+			mod.Organization = a.Organization
+			changes = append(changes, change("organization", cur.Organization, a.Organization))
+		}
+		var sc []string
+		mod.AssocSettings, sc = diffSettings(a.AssocSettings, cur.Settings)
+		if changes = append(changes, sc...); len(changes) > 0 {
+			args, err := modifyAccountArgs(mod)
+			p.add("modify_account", "account "+name, changes, args, err)
+		}
+	}
+
+	// 用户关联
+	have := make(map[assocKey]LiveAssoc, len(live.Assocs))
+	exists := make(map[string]bool)
+	parts := make(map[assocKey][]string) // 不区分分区的关联 -> 同一用户在该账户下的分区关联
+	for _, a := range live.Assocs {
+		have[assocKey{a.Account, a.User, a.Partition}] = a
+		exists[a.User] = true
+		if a.Partition != "" {
+			key := assocKey{a.Account, a.User, ""}
+			parts[key] = append(parts[key], a.Partition)
+		}
+	}
+	// sacctmgr 无法只选中不区分分区的关联, 修改或删除它会波及分区关联
+	conflict := func(key assocKey, verb string) error {
+		return fmt.Errorf("%w: cannot %s %s without affecting partition associations %s", ErrConflict, verb, key, strings.Join(parts[key], ","))
+	}
+	wanted := make(map[assocKey]bool)
+	for _, name := range order {
+		for _, u := range want[name].Users {
+			for _, part := range u.partitions() {
+				key := assocKey{name, u.Name, part}
+				wanted[key] = true
+				if cur, ok := have[key]; ok {
+					set, changes := diffSettings(u.AssocSettings, cur.Settings)
+					if len(changes) > 0 {
+						args, err := modifyAssociationArgs(AssociationSpec{Account: name, User: u.Name, Partition: part, AssocSettings: set})
+						if err == nil && part == "" && len(parts[key]) > 0 {
+							err = conflict(key, "modify")
+						}
+						p.add("modify_association", key.String(), changes, args, err)
+					}
+					continue
+				}
+				if !exists[u.Name] {
+					args, err := addUserArgs(UserSpec{Name: u.Name, Account: name, Partition: part, AssocSettings: u.AssocSettings})
+					p.add("add_user", key.String(), nil, args, err)
+					exists[u.Name] = true
+					continue
+				}
+				args, err := addAssociationArgs(AssociationSpec{Account: name, User: u.Name, Partition: part, AssocSettings: u.AssocSettings})
+				p.add("add_association", key.String(), nil, args, err)
+			}
+		}
+	}
+
+	// 受保护子树中的账户: 账户自身或其祖先(root 除外)受保护
+	frozen := func(acct string) bool {
+		for seen := 0; acct != "" && seen <= len(live.Accounts); seen++ {
+			if protected[acct] && acct != "root" {
+				return true
+			}
+			a, ok := live.Accounts[acct]
+			if !ok {
+				return false
+			}
+			acct = a.Parent
+		}
+		return false
+	}
+
+	// 删除多余的用户关联, 被删除的是默认账户时先切换默认账户, 用户的关联全部被删除时直接删除用户.
+	// root 账户下的用户关联(如 root@root)无法在 TreeSpec 中声明, 始终保留
+	removed := make(map[string][]LiveAssoc)
+	kept := make(map[string][]string)
+	for _, a := range live.Assocs {
+		key := assocKey{a.Account, a.User, a.Partition}
+		if wanted[key] || a.Account == "root" || (want[a.Account] == nil && frozen(a.Account)) {
+			kept[a.User] = append(kept[a.User], a.Account)
+			continue
+		}
+		removed[a.User] = append(removed[a.User], a)
+	}
+	for key := range wanted {
+		if _, ok := have[key]; !ok {
+			kept[key.user] = append(kept[key.user], key.account)
+		}
+	}
+	users := make([]string, 0, len(removed))
+	for u := range removed {
+		users = append(users, u)
+	}
+	sort.Strings(users)
+	for _, u := range users {
+		if len(kept[u]) == 0 {
+			args, err := deleteArgs("user", u)
+			p.add("delete_user", "user "+u, nil, args, err)
+			continue
+		}
+		for _, a := range removed[u] {
+			if a.Default && !contains(kept[u], a.Account) {
+				accts := append([]string{}, kept[u]...)
+				sort.Strings(accts)
+				args, err := modifyUserArgs(UserSpec{Name: u, DefaultAccount: accts[0]})
+				p.add("modify_user", "user "+u, []string{change("default_account", a.Account, accts[0])}, args, err)
+				break
+			}
+		}
+		// 删除不区分分区的关联会一并删除分区关联, 只有这些分区关联也要删除时才允许, 此时不再单独删除它们
+		gone := make(map[assocKey]bool, len(removed[u]))
+		for _, a := range removed[u] {
+			gone[assocKey{a.Account, a.User, a.Partition}] = true
+		}
+		for _, a := range removed[u] {
+			key := assocKey{a.Account, a.User, a.Partition}
+			if a.Partition != "" && gone[assocKey{a.Account, a.User, ""}] {
+				continue
+			}
+			args, err := deleteAssociationArgs(AssociationSpec{Account: a.Account, User: a.User, Partition: a.Partition})
+			for _, part := range parts[key] {
+				if err == nil && !gone[assocKey{a.Account, a.User, part}] {
+					err = conflict(key, "delete")
+				}
+			}
+			p.add("delete_association", key.String(), nil, args, err)
+		}
+	}
+
+	// 删除多余的账户, 子账户在前
+	hasProtected := make(map[string]string)
+	for name := range protected {
+		if _, ok := live.Accounts[name]; !ok || name == "root" {
+			continue
+		}
+		for a := live.Accounts[name].Parent; a != ""; {
+			if _, ok := hasProtected[a]; !ok {
+				hasProtected[a] = name
+			}
+			parent, ok := live.Accounts[a]
+			if !ok {
+				break
+			}
+			a = parent.Parent
+		}
+	}
+	var drop []*LiveAccount
+	for name, a := range live.Accounts {
+		switch {
+		case want[name] != nil || name == "root":
+		case protected[name]:
+			p.plan.Skipped = append(p.plan.Skipped, SyncSkip{Target: "account " + name, Reason: "protected"})
+		case frozen(name):
+		case hasProtected[name] != "":
+			p.plan.Skipped = append(p.plan.Skipped, SyncSkip{Target: "account " + name, Reason: "contains protected account " + hasProtected[name]})
+		default:
+			drop = append(drop, a)
+		}
+	}
+	sort.Slice(drop, func(i, j int) bool {
+		if drop[i].Depth != drop[j].Depth {
+			return drop[i].Depth > drop[j].Depth
+		}
+		return drop[i].Name < drop[j].Name
+	})
+	for _, a := range drop {
+		args, err := deleteArgs("account", a.Name)
+		p.add("delete_account", "account "+a.Name, nil, args, err)
+	}
+	sort.Slice(p.plan.Skipped, func(i, j int) bool { return p.plan.Skipped[i].Target < p.plan.Skipped[j].Target })
+
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.plan, nil
+}
+
+// accounts 校验期望树并返回账户表与父账户在前的顺序. 父账户必须是 root、期望树中的账户或现有的受保护账户.
+func (s TreeSpec) accounts(live *LiveState, protected map[string]bool) (map[string]*AccountNode, []string, error) {
+	want := make(map[string]*AccountNode, len(s.Accounts))
+	for i := range s.Accounts {
+		a := &s.Accounts[i]
+		a.Name = strings.TrimSpace(a.Name)
+		if err := validateName("account name", a.Name, true); err != nil {
+			return nil, nil, err
+		}
+		if a.Name == "root" {
+			return nil, nil, fmt.Errorf("%w: root is implicit and cannot be declared", ErrInvalidSpec)
+		}
+		if want[a.Name] != nil {
+			return nil, nil, fmt.Errorf("%w: duplicate account %q", ErrInvalidSpec, a.Name)
+		}
+		if err := (AccountSpec{Name: a.Name, Parent: a.Parent, Description: a.Description, Organization: a.Organization, AssocSettings: a.AssocSettings}).validate(); err != nil {
+			return nil, nil, err
+		}
+		seen := make(map[assocKey]bool)
+		for _, u := range a.Users {
+			for _, part := range u.partitions() {
+				spec := AssociationSpec{Account: a.Name, User: u.Name, Partition: part, AssocSettings: u.AssocSettings}
+				if err := spec.validate(true); err != nil {
+					return nil, nil, err
+				}
+				key := assocKey{a.Name, u.Name, part}
+				if seen[key] {
+					return nil, nil, fmt.Errorf("%w: duplicate %s", ErrInvalidSpec, key)
+				}
+				seen[key] = true
+			}
+		}
+		want[a.Name] = a
+	}
+
+	order := make([]string, 0, len(want))
+	state := make(map[string]int) // 1: 访问中, 2: 已完成
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case 1:
+			return fmt.Errorf("%w: account %q is part of a parent cycle", ErrInvalidSpec, name)
+		case 2:
+			return nil
+		}
+		state[name] = 1
+		parent := want[name].parent()
+		if want[parent] != nil {
+			if err := visit(parent); err != nil {
+				return err
+			}
+		} else if _, ok := live.Accounts[parent]; parent != "root" && (!protected[parent] || !ok) {
+			return fmt.Errorf("%w: parent %q of account %q must be root, a declared account or an existing protected account", ErrInvalidSpec, parent, name)
+		}
+		state[name] = 2
+		order = append(order, name)
+		return nil
+	}
+	for _, a := range s.Accounts {
+		if err := visit(a.Name); err != nil {
+			return nil, nil, err
+		}
+	}
+	return want, order, nil
+}
+
+func (a *AccountNode) parent() string {
+	if p := strings.TrimSpace(a.Parent); p != "" {
+		return p
+	}
+	return "root"
+}
+
+func (u UserNode) partitions() []string {
+	if len(u.Partitions) == 0 {
+		return []string{""}
+	}
+	return u.Partitions
+}
+
+// diffSettings 返回将 have 调整为 want 所需设置的字段与变更说明, want 中留空的字段不比较.
+func diffSettings(want, have AssocSettings) (AssocSettings, []string) {
+	var set AssocSettings
+	var changes []string
+	if v := strings.TrimSpace(want.Fairshare); v != "" && !strings.EqualFold(v, have.Fairshare) {
+		set.Fairshare = v
+		changes = append(changes, change("fairshare", have.Fairshare, v))
+	}
+	if len(want.QOS) > 0 {
+		w := append([]string{}, want.QOS...)
+		sort.Strings(w)
+		if strings.Join(w, ",") != strings.Join(have.QOS, ",") {
+			set.QOS = w
+			changes = append(changes, change("qos", strings.Join(have.QOS, ","), strings.Join(w, ",")))
+		}
+	}
+	if v := strings.TrimSpace(want.DefaultQOS); v != "" && v != have.DefaultQOS {
+		set.DefaultQOS = v
+		changes = append(changes, change("default_qos", have.DefaultQOS, v))
+	}
+	if want.Limits == nil {
+		return set, changes
+	}
+	wl := make(map[string]string, len(want.Limits))
+	for k, v := range want.Limits {
+		wl[assocLimitKeys[strings.ToLower(strings.TrimSpace(k))]] = strings.TrimSpace(v)
+	}
+	keys := make([]string, 0, len(wl)+len(have.Limits))
+	for k := range wl {
+		keys = append(keys, k)
+	}
+	for k := range have.Limits {
+		if _, ok := wl[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		w, h := normalizeLimit(k, wl[k]), normalizeLimit(k, have.Limits[k])
+		if w == h {
+			continue
+		}
+		if set.Limits == nil {
+			set.Limits = make(map[string]string)
+		}
+		v := wl[k]
+		if w == "" {
+			v = "-1"
+		}
+		set.Limits[k] = v
+		changes = append(changes, change(k, have.Limits[k], v))
+	}
+	return set, changes
+}
+
+// normalizeLimit 将限制取值规范化以便比较: 时长换算为分钟, TRES 按名称排序且内存类换算为 MB, -1 视为未设置.
+func normalizeLimit(key, v string) string {
+	v = strings.TrimSpace(v)
+	if v == "" || v == "-1" {
+		return ""
+	}
+	switch k := strings.ToLower(key); {
+	case strings.Contains(k, "tres"):
+		return normalizeTres(v)
+	case strings.HasSuffix(k, "wall"):
+		if secs, err := slurmctl.ParseDuration(v); err == nil {
+			if secs < 0 {
+				return ""
+			}
+			return strconv.FormatInt(secs/60, 10)
+		}
+	}
+	return v
+}
+
+// normalizeTres 规范化 "cpu=8,mem=1G" 形式的 TRES 限制, 忽略 -1 项.
+func normalizeTres(v string) string {
+	parts := make([]string, 0)
+	for _, kv := range strings.Split(v, ",") {
+		k, val, ok := strings.Cut(strings.TrimSpace(kv), "=")
+		if !ok || val == "" || val == "-1" {
+			continue
+		}
+		parts = append(parts, strings.ToLower(k)+"="+megabytes(val))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// megabytes 将带 K/M/G/T 后缀的取值换算为 MB, 不带后缀或无法解析时原样返回.
+func megabytes(v string) string {
+	mult := map[byte]float64{'K': 1.0 / 1024, 'M': 1, 'G': 1024, 'T': 1024 * 1024}
+	m, ok := mult[strings.ToUpper(v[len(v)-1:])[0]]
+	if !ok {
+		return v
+	}
+	n, err := strconv.ParseFloat(v[:len(v)-1], 64)
+	if err != nil {
+		return v
+	}
+	return strconv.FormatInt(int64(n*m+0.5), 10)
+}
+
+func change(field, from, to string) string {
+	if from == "" {
+		from = "(unset)"
+	}
+	if to == "-1" || to == "" {
+		to = "(unset)"
+	}
+	return field + ": " + from + " -> " + to
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package sacctmgr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPlanSync(t *testing.T) {
+	live := &LiveState{
+		Accounts: map[string]*LiveAccount{
+			"root":  {Name: "root", Settings: AssocSettings{Fairshare: "1"}},
+			"phys":  {Name: "phys", Parent: "root", Description: "physics", Depth: 1, Settings: AssocSettings{Fairshare: "1", Limits: map[string]string{"GrpTRES": "cpu=100", "MaxJobs": "10"}}},
+			"old":   {Name: "old", Parent: "root", Depth: 1, Settings: AssocSettings{Fairshare: "1"}},
+			"old2":  {Name: "old2", Parent: "old", Depth: 2, Settings: AssocSettings{Fairshare: "1"}},
+			"admin": {Name: "admin", Parent: "root", Depth: 1, Settings: AssocSettings{Fairshare: "1"}},
+			"ops":   {Name: "ops", Parent: "admin", Depth: 2, Settings: AssocSettings{Fairshare: "1"}},
+		},
+		Assocs: []LiveAssoc{
+			{Account: "root", User: "root", Default: true, Settings: AssocSettings{Fairshare: "1"}}, // 默认的 root@root 关联始终保留
+			{Account: "phys", User: "alice", Default: true, Settings: AssocSettings{Fairshare: "1"}},
+			{Account: "old", User: "alice", Settings: AssocSettings{Fairshare: "1"}},
+			{Account: "old2", User: "bob", Default: true, Settings: AssocSettings{Fairshare: "1"}},
+			{Account: "ops", User: "carol", Default: true, Settings: AssocSettings{Fairshare: "1"}},
+		},
+	}
+	spec := TreeSpec{
+		Protected: []string{"admin"},
+		Accounts: []AccountNode{
+			{Name: "hep", Parent: "phys", Users: []UserNode{{Name: "dave", Partitions: []string{"cpu", "gpu"}}}},
+			{Name: "phys", Description: "physics", AssocSettings: AssocSettings{Fairshare: "10", Limits: map[string]string{"grptres": "CPU=100"}},
+				Users: []UserNode{{Name: "alice", AssocSettings: AssocSettings{Fairshare: "5"}}}},
+		},
+	}
+	plan, err := (&Client{}).PlanSync(spec, live)
+	if err != nil {
+		t.Fatalf("PlanSync() error = %v", err)
+	}
+	want := []string{
+		"sacctmgr -i -P modify account where Name=phys set Fairshare=10 MaxJobs=-1",
+		"sacctmgr -i -P add account hep Parent=phys",
+		"sacctmgr -i -P modify user where Name=alice Account=phys set Fairshare=5",
+		"sacctmgr -i -P add user dave Account=hep Partition=cpu",
+		"sacctmgr -i -P add user dave Account=hep Partition=gpu",
+		"sacctmgr -i -P delete user where Name=alice Account=old",
+		"sacctmgr -i -P delete user where Name=bob",
+		"sacctmgr -i -P delete account where Name=old2",
+		"sacctmgr -i -P delete account where Name=old",
+	}
+	var got []string
+	for _, op := range plan.Ops {
+		got = append(got, op.Command)
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("PlanSync() commands =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if len(plan.Skipped) != 1 || plan.Skipped[0].Target != "account admin" {
+		t.Errorf("PlanSync().Skipped = %+v", plan.Skipped)
+	}
+	if c := plan.Ops[0].Changes; len(c) != 2 || c[1] != "MaxJobs: 10 -> (unset)" {
+		t.Errorf("PlanSync().Ops[0].Changes = %v", c)
+	}
+
+	// 删除默认账户时先切换默认账户
+	spec = TreeSpec{Accounts: []AccountNode{
+		{Name: "old"}, {Name: "old2", Parent: "old"},
+		{Name: "admin"}, {Name: "ops", Parent: "admin"},
+	}}
+	spec.Accounts[0].Users = []UserNode{{Name: "alice"}}
+	spec.Accounts[1].Users = []UserNode{{Name: "bob"}}
+	spec.Accounts[3].Users = []UserNode{{Name: "carol"}}
+	plan, err = (&Client{}).PlanSync(spec, live)
+	if err != nil {
+		t.Fatalf("PlanSync() error = %v", err)
+	}
+	if len(plan.Ops) != 3 || plan.Ops[0].Command != "sacctmgr -i -P modify user where Name=alice set DefaultAccount=old" ||
+		plan.Ops[1].Command != "sacctmgr -i -P delete user where Name=alice Account=phys" ||
+		plan.Ops[2].Command != "sacctmgr -i -P delete account where Name=phys" {
+		for _, op := range plan.Ops {
+			t.Logf("%s", op.Command)
+		}
+		t.Errorf("PlanSync() default account switch not planned")
+	}
+
+	bad := []TreeSpec{
+		{Accounts: []AccountNode{{Name: "a", Parent: "b"}, {Name: "b", Parent: "a"}}}, // 父账户成环
+		{Accounts: []AccountNode{{Name: "a", Parent: "old"}}},                         // 父账户将被删除
+		{Accounts: []AccountNode{{Name: "a"}, {Name: "a"}}},                           // 重复账户
+		{Accounts: []AccountNode{{Name: "root"}}},                                     // 声明 root
+	}
+	for i, s := range bad {
+		if _, err := (&Client{}).PlanSync(s, live); !errors.Is(err, ErrInvalidSpec) {
+			t.Errorf("PlanSync(bad[%d]) err = %v, want ErrInvalidSpec", i, err)
+		}
+	}
+}
+
+func TestNormalizeLimit(t *testing.T) {
+	cases := []struct{ key, v, want string }{
+		{"GrpTRES", "mem=2G,CPU=8", "cpu=8,mem=2048"},
+		{"GrpTRES", "cpu=-1", ""},
+		{"MaxWall", "1-00:00:00", "1440"},
+		{"MaxWall", "90", "90"},
+		{"MaxJobs", "-1", ""},
+	}
+	for _, tc := range cases {
+		if got := normalizeLimit(tc.key, tc.v); got != tc.want {
+			t.Errorf("normalizeLimit(%q, %q) = %q, want %q", tc.key, tc.v, got, tc.want)
+		}
+	}
+}
+
+func TestPlanSyncPartitionAssocs(t *testing.T) {
+	live := &LiveState{
+		Accounts: map[string]*LiveAccount{
+			"root": {Name: "root", Settings: AssocSettings{Fairshare: "1"}},
+			"phys": {Name: "phys", Parent: "root", Depth: 1, Settings: AssocSettings{Fairshare: "1"}},
+			"chem": {Name: "chem", Parent: "root", Depth: 1, Settings: AssocSettings{Fairshare: "1"}},
+		},
+		Assocs: []LiveAssoc{
+			{Account: "chem", User: "alice", Default: true, Settings: AssocSettings{Fairshare: "1"}},
+			{Account: "phys", User: "alice", Settings: AssocSettings{Fairshare: "1"}},
+			{Account: "phys", User: "alice", Partition: "gpu", Settings: AssocSettings{Fairshare: "1"}},
+		},
+	}
+	tree := func(phys ...UserNode) TreeSpec {
+		return TreeSpec{Accounts: []AccountNode{
+			{Name: "chem", Users: []UserNode{{Name: "alice"}}},
+			{Name: "phys", Users: phys},
+		}}
+	}
+	commands := func(plan *SyncPlan) string {
+		var got []string
+		for _, op := range plan.Ops {
+			got = append(got, op.Command)
+		}
+		return strings.Join(got, "\n")
+	}
+
+	// 分区关联可以单独修改
+	plan, err := (&Client{}).PlanSync(tree(
+		UserNode{Name: "alice"},
+		UserNode{Name: "alice", Partitions: []string{"gpu"}, AssocSettings: AssocSettings{Fairshare: "3"}},
+	), live)
+	if err != nil {
+		t.Fatalf("PlanSync(modify gpu) error = %v", err)
+	}
+	if got, want := commands(plan), "sacctmgr -i -P modify user where Name=alice Account=phys Partition=gpu set Fairshare=3"; got != want {
+		t.Errorf("PlanSync(modify gpu) commands = %q, want %q", got, want)
+	}
+
+	// 不区分分区的关联与分区关联一起删除时只需一条命令
+	plan, err = (&Client{}).PlanSync(tree(), live)
+	if err != nil {
+		t.Fatalf("PlanSync(delete both) error = %v", err)
+	}
+	if got, want := commands(plan), "sacctmgr -i -P delete user where Name=alice Account=phys"; got != want {
+		t.Errorf("PlanSync(delete both) commands = %q, want %q", got, want)
+	}
+
+	// 指定集群时命令限定在该集群上
+	plan, err = (&Client{}).WithCluster("th2").PlanSync(tree(), live)
+	if err != nil {
+		t.Fatalf("PlanSync(cluster) error = %v", err)
+	}
+	if got, want := commands(plan), "sacctmgr -i -P delete user where Name=alice Account=phys Cluster=th2"; got != want {
+		t.Errorf("PlanSync(cluster) commands = %q, want %q", got, want)
+	}
+
+	// 修改或删除不区分分区的关联会波及保留的分区关联, 拒绝生成计划
+	for name, spec := range map[string]TreeSpec{
+		"modify": tree(
+			UserNode{Name: "alice", AssocSettings: AssocSettings{Fairshare: "5"}},
+			UserNode{Name: "alice", Partitions: []string{"gpu"}},
+		),
+		"delete": tree(UserNode{Name: "alice", Partitions: []string{"gpu"}}),
+	} {
+		if _, err := (&Client{}).PlanSync(spec, live); !errors.Is(err, ErrConflict) {
+			t.Errorf("PlanSync(%s non-partition) err = %v, want ErrConflict", name, err)
+		}
+	}
+}
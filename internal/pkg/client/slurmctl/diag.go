@@ -0,0 +1,244 @@
+package slurmctl
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"solid/internal/pkg/client/slurmctl/models"
+)
+
+var (
+	// sdiagTimeRe 匹配 "sdiag output at ... (1700000000)" 中的时间戳.
+	sdiagTimeRe = regexp.MustCompile(`\((\d+)\)\s*$`)
+	// sdiagRPCRe 匹配 RPC 统计行, 如 "REQUEST_PARTITION_INFO ( 2009) count:10 ave_time:200 total_time:2000".
+	sdiagRPCRe = regexp.MustCompile(`^(\S+)\s*\(\s*(\d+)\)\s+count:(\d+)\s+ave_time:(\d+)\s+total_time:(\d+)`)
+	// sdiagKeyRe 字段名中的非字母数字字符.
+	sdiagKeyRe = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// GetDiag 通过 sdiag 获取 slurmctld 运行统计.
+func (c *Client) GetDiag(ctx context.Context) (*models.SchedulerDiag, error) {
+	cmd := c.execCommand(ctx, "sdiag")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		c.logger.Error("unable to get scheduler diagnostics", "output", string(out), "cmd", cmd.String(), "err", err)
+		return nil, fmt.Errorf("failed to exec %s", cmd.String())
+	}
+	return parseSdiag(string(out)), nil
+}
+
+// ResetDiag 通过 sdiag --reset 清零 slurmctld 统计, 需要 SlurmUser 或 root 权限.
+func (c *Client) ResetDiag(ctx context.Context, operator string) error {
+	if strings.TrimSpace(operator) == "" {
+		return fmt.Errorf("operator is required")
+	}
+	cmd := c.execCommand(ctx, "sdiag", "--reset")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		c.logger.Error("unable to reset scheduler diagnostics", "output", string(out), "cmd", cmd.String(), "err", err)
+		return fmt.Errorf("failed to exec %s: %s", cmd.String(), strings.TrimSpace(string(out)))
+	}
+	c.logger.Info("scheduler diagnostics reset", "operator", operator)
+	return nil
+}
+
+// parseSdiag 解析 sdiag 输出. 输出按段落组织, 段落标题决定后续 "key: value" 行归属的统计.
+func parseSdiag(content string) *models.SchedulerDiag {
+	d := &models.SchedulerDiag{
+		Jobs:      make(map[string]int64),
+		Main:      make(map[string]int64),
+		Backfill:  make(map[string]int64),
+		RPCByType: make([]models.RPCStat, 0),
+		RPCByUser: make([]models.RPCStat, 0),
+	}
+
+	section := ""
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "****") {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "sdiag output at"):
+			d.Time = sdiagTimestamp(trimmed)
+			continue
+		case strings.HasPrefix(trimmed, "Data since"):
+			d.DataSince = sdiagTimestamp(trimmed)
+			continue
+		case strings.HasPrefix(trimmed, "Main schedule statistics"):
+			section = "main"
+			continue
+		case strings.HasPrefix(trimmed, "Main scheduler exit"):
+			section = "main_exit"
+			continue
+		case strings.HasPrefix(trimmed, "Backfilling stats"):
+			section = "backfill"
+			continue
+		case strings.HasPrefix(trimmed, "Backfill exit"):
+			section = "backfill_exit"
+			continue
+		case strings.HasPrefix(trimmed, "Remote Procedure Call statistics by message type"):
+			section = "rpc_type"
+			continue
+		case strings.HasPrefix(trimmed, "Remote Procedure Call statistics by user"):
+			section = "rpc_user"
+			continue
+		case strings.HasPrefix(trimmed, "Pending RPC statistics"),
+			strings.HasPrefix(trimmed, "Remote Procedure Call statistics by"):
+			section = "ignore"
+			continue
+		}
+
+		if section == "rpc_type" || section == "rpc_user" {
+			m := sdiagRPCRe.FindStringSubmatch(trimmed)
+			if m == nil {
+				continue
+			}
+			st := models.RPCStat{Name: m[1]}
+			st.ID, _ = strconv.ParseInt(m[2], 10, 64)
+			st.Count, _ = strconv.ParseInt(m[3], 10, 64)
+			st.AveTime, _ = strconv.ParseInt(m[4], 10, 64)
+			st.TotalTime, _ = strconv.ParseInt(m[5], 10, 64)
+			if section == "rpc_type" {
+				d.RPCByType = append(d.RPCByType, st)
+			} else {
+				d.RPCByUser = append(d.RPCByUser, st)
+			}
+			continue
+		}
+
+		key, val, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(strings.Fields(val + " x")[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		key = strings.Trim(sdiagKeyRe.ReplaceAllString(strings.ToLower(key), "_"), "_")
+
+		switch section {
+		case "main":
+			d.Main[key] = n
+		case "backfill":
+			d.Backfill[key] = n
+		case "":
+			switch key {
+			case "server_thread_count":
+				d.ServerThreadCount = n
+			case "agent_queue_size":
+				d.AgentQueueSize = n
+			case "agent_count":
+				d.AgentCount = n
+			case "agent_thread_count":
+				d.AgentThreadCount = n
+			case "dbd_agent_queue_size":
+				d.DBDAgentQueueSize = n
+			default:
+				if strings.HasPrefix(key, "jobs_") {
+					d.Jobs[strings.TrimPrefix(key, "jobs_")] = n
+				}
+			}
+		}
+	}
+	return d
+}
+
+// sdiagTimestamp 取出行尾括号中的 Unix 时间戳.
+func sdiagTimestamp(line string) int64 {
+	m := sdiagTimeRe.FindStringSubmatch(line)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.ParseInt(m[1], 10, 64)
+	return n
+}
+
+// diagTimeout 单次抓取中 sdiag 的超时时间.
+const diagTimeout = 30 * time.Second
+
+// DiagCollector 在每次抓取时执行 sdiag 并以 Prometheus gauge 形式导出 slurmctld 统计.
+type DiagCollector struct {
+	client *Client
+	logger *slog.Logger
+
+	up           *prometheus.Desc
+	threads      *prometheus.Desc
+	agentQueue   *prometheus.Desc
+	dbdQueue     *prometheus.Desc
+	jobs         *prometheus.Desc
+	cycle        *prometheus.Desc
+	rpcCount     *prometheus.Desc
+	rpcTime      *prometheus.Desc
+	rpcUserCount *prometheus.Desc
+	rpcUserTime  *prometheus.Desc
+}
+
+// NewDiagCollector 创建 sdiag 指标采集器.
+func NewDiagCollector(client *Client, logger *slog.Logger) *DiagCollector {
+	return &DiagCollector{
+		client:       client,
+		logger:       logger,
+		up:           prometheus.NewDesc("slurm_sdiag_up", "Whether the last sdiag call succeeded.", nil, nil),
+		threads:      prometheus.NewDesc("slurm_scheduler_server_threads", "slurmctld server thread count.", nil, nil),
+		agentQueue:   prometheus.NewDesc("slurm_scheduler_agent_queue_size", "slurmctld agent queue size.", nil, nil),
+		dbdQueue:     prometheus.NewDesc("slurm_scheduler_dbd_agent_queue_size", "slurmctld DBD agent queue size.", nil, nil),
+		jobs:         prometheus.NewDesc("slurm_scheduler_jobs", "Job counters reported by sdiag.", []string{"state"}, nil),
+		cycle:        prometheus.NewDesc("slurm_scheduler_cycle_stat", "Main and backfill scheduler cycle statistics reported by sdiag.", []string{"scheduler", "stat"}, nil),
+		rpcCount:     prometheus.NewDesc("slurm_rpc_message_type_count", "RPC count by message type.", []string{"type"}, nil),
+		rpcTime:      prometheus.NewDesc("slurm_rpc_message_type_total_time_microseconds", "RPC total time by message type.", []string{"type"}, nil),
+		rpcUserCount: prometheus.NewDesc("slurm_rpc_user_count", "RPC count by user.", []string{"user"}, nil),
+		rpcUserTime:  prometheus.NewDesc("slurm_rpc_user_total_time_microseconds", "RPC total time by user.", []string{"user"}, nil),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (dc *DiagCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{dc.up, dc.threads, dc.agentQueue, dc.dbdQueue, dc.jobs, dc.cycle, dc.rpcCount, dc.rpcTime, dc.rpcUserCount, dc.rpcUserTime} {
+		ch <- d
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (dc *DiagCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), diagTimeout)
+	defer cancel()
+	d, err := dc.client.GetDiag(ctx)
+	if err != nil {
+		dc.logger.Warn("unable to collect sdiag metrics", "err", err)
+		ch <- prometheus.MustNewConstMetric(dc.up, prometheus.GaugeValue, 0)
+		return
+	}
+	gauge := func(desc *prometheus.Desc, v int64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(v), labels...)
+	}
+	gauge(dc.up, 1)
+	gauge(dc.threads, d.ServerThreadCount)
+	gauge(dc.agentQueue, d.AgentQueueSize)
+	gauge(dc.dbdQueue, d.DBDAgentQueueSize)
+	for state, v := range d.Jobs {
+		gauge(dc.jobs, v, state)
+	}
+	for stat, v := range d.Main {
+		gauge(dc.cycle, v, "main", stat)
+	}
+	for stat, v := range d.Backfill {
+		gauge(dc.cycle, v, "backfill", stat)
+	}
+	for _, r := range d.RPCByType {
+		gauge(dc.rpcCount, r.Count, r.Name)
+		gauge(dc.rpcTime, r.TotalTime, r.Name)
+	}
+	for _, r := range d.RPCByUser {
+		gauge(dc.rpcUserCount, r.Count, r.Name)
+		gauge(dc.rpcUserTime, r.TotalTime, r.Name)
+	}
+}
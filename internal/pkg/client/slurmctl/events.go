@@ -0,0 +1,272 @@
+package slurmctl
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"solid/internal/pkg/client/slurmctl/models"
+)
+
+// 事件类型.
+const (
+	EventJobSubmitted          = "job_submitted"
+	EventJobStarted            = "job_started"
+	EventJobCompleted          = "job_completed"
+	EventJobFailed             = "job_failed"
+	EventJobCancelled          = "job_cancelled"
+	EventJobStateChanged       = "job_state_changed"
+	EventNodeDrained           = "node_drained"
+	EventNodeDown              = "node_down"
+	EventNodeResumed           = "node_resumed"
+	EventNodeStateChanged      = "node_state_changed"
+	EventPartitionStateChanged = "partition_state_changed"
+)
+
+const (
+	defaultEventHistory         = 1024 // 默认保留的历史事件数量
+	defaultSubscriberBufferSize = 256  // 每个订阅者的事件缓冲区大小
+)
+
+// Event 两次快照之间的状态变化.
+type Event struct {
+	ID        uint64            `json:"id"`                  // 单调递增的事件序号, 用于 Last-Event-ID 续传
+	Type      string            `json:"type"`                // 事件类型
+	Time      time.Time         `json:"time"`                // 检测到变化的快照时间
+	PrevState string            `json:"prev_state"`          // 变化前状态, 新出现的对象为空
+	State     string            `json:"state"`               // 变化后状态, 消失的对象为空
+	Job       *models.Job       `json:"job,omitempty"`       // 作业事件的作业信息
+	Node      *models.Node      `json:"node,omitempty"`      // 节点事件的节点信息
+	Partition *models.Partition `json:"partition,omitempty"` // 分区事件的分区信息
+}
+
+// failedJobStates 视为失败的作业短状态码.
+var failedJobStates = map[string]bool{"F": true, "NF": true, "TO": true, "OOM": true, "BF": true, "DL": true}
+
+// jobEventType 根据作业状态变化返回事件类型, prev 为空表示新作业, next 为空表示作业已离开队列.
+// 快照包含所有状态的作业, 作业结束时先以最终状态出现并产生事件, 在 slurmctld 清除(MinJobAge)后离开队列;
+// 以最终状态离开队列时返回空字符串, 表示不产生事件.
+func jobEventType(prev, next string) string {
+	if next == "" && finishedJobStates[prev] {
+		return ""
+	}
+	switch {
+	case prev == "" && next == "PD":
+		return EventJobSubmitted
+	case next == "R" && prev != "R":
+		return EventJobStarted
+	case next == "CD":
+		return EventJobCompleted
+	case next == "CA":
+		return EventJobCancelled
+	case failedJobStates[next]:
+		return EventJobFailed
+	case next == "":
+		// 两次快照之间结束并被清除, 未看到最终状态, 视为正常完成
+		return EventJobCompleted
+	case prev == "":
+		return EventJobSubmitted
+	default:
+		return EventJobStateChanged
+	}
+}
+
+// nodeEventType 根据 sinfo %t 节点状态变化返回事件类型.
+func nodeEventType(prev, next string) string {
+	isDrain := func(s string) bool { return strings.HasPrefix(s, "drain") || strings.HasPrefix(s, "drng") }
+	isDown := func(s string) bool { return strings.HasPrefix(s, "down") || strings.HasPrefix(s, "fail") }
+	switch {
+	case isDrain(next) && !isDrain(prev):
+		return EventNodeDrained
+	case isDown(next) && !isDown(prev):
+		return EventNodeDown
+	case (isDrain(prev) || isDown(prev)) && !isDrain(next) && !isDown(next):
+		return EventNodeResumed
+	default:
+		return EventNodeStateChanged
+	}
+}
+
+// DiffSnapshots 比较两次快照, 返回作业、节点与分区的状态变化事件(尚未分配 ID).
+func DiffSnapshots(prev, next *Snapshot) []Event {
+	events := make([]Event, 0)
+	if prev == nil || next == nil {
+		return events
+	}
+
+	// 作业
+	prevJobs := make(map[string]models.Job, len(prev.Jobs))
+	for _, j := range prev.Jobs {
+		prevJobs[j.Jobid] = j
+	}
+	for _, j := range next.Jobs {
+		job := j
+		old, ok := prevJobs[j.Jobid]
+		delete(prevJobs, j.Jobid)
+		if ok && old.State == j.State {
+			continue
+		}
+		events = append(events, Event{Type: jobEventType(old.State, j.State), Time: next.Time, PrevState: old.State, State: j.State, Job: &job})
+	}
+	for _, j := range prev.Jobs {
+		if _, gone := prevJobs[j.Jobid]; !gone {
+			continue
+		}
+		typ := jobEventType(j.State, "")
+		if typ == "" {
+			continue
+		}
+		job := j
+		events = append(events, Event{Type: typ, Time: next.Time, PrevState: j.State, Job: &job})
+	}
+
+	// 节点
+	for name, n := range next.Nodes {
+		old, ok := prev.Nodes[name]
+		if !ok || old.State == n.State {
+			continue
+		}
+		node := *n
+		events = append(events, Event{Type: nodeEventType(old.State, n.State), Time: next.Time, PrevState: old.State, State: n.State, Node: &node})
+	}
+
+	// 分区
+	prevParts := make(map[string]string, len(prev.Partitions))
+	for _, p := range prev.Partitions {
+		prevParts[p.Name] = p.State
+	}
+	for _, p := range next.Partitions {
+		old, ok := prevParts[p.Name]
+		if ok && old == p.State {
+			continue
+		}
+		part := p
+		events = append(events, Event{Type: EventPartitionStateChanged, Time: next.Time, PrevState: old, State: p.State, Partition: &part})
+	}
+
+	return events
+}
+
+// EventFilter 事件订阅过滤条件, 各字段为逗号分隔的列表, 为空表示不过滤.
+// 设置 User 或 Account 时只推送作业事件.
+type EventFilter struct {
+	User      string
+	Account   string
+	Partition string
+	Types     string
+}
+
+// Match 判断事件是否满足过滤条件.
+func (f EventFilter) Match(e Event) bool {
+	if !matchList(f.Types, e.Type) {
+		return false
+	}
+	switch {
+	case e.Job != nil:
+		if !matchList(f.User, e.Job.User) || !matchList(f.Account, e.Job.Account) {
+			return false
+		}
+		return matchAny(f.Partition, strings.Split(e.Job.Partition, ","))
+	case strings.TrimSpace(f.User) != "" || strings.TrimSpace(f.Account) != "":
+		return false
+	case e.Node != nil:
+		parts := make([]string, 0, len(e.Node.Partition))
+		for _, p := range e.Node.Partition {
+			parts = append(parts, strings.TrimSuffix(p, "*"))
+		}
+		return matchAny(f.Partition, parts)
+	case e.Partition != nil:
+		return matchList(f.Partition, e.Partition.Name)
+	default:
+		return true
+	}
+}
+
+// matchAny 判断 vals 中是否有任一值在逗号分隔的 list 中, list 为空表示不过滤.
+func matchAny(list string, vals []string) bool {
+	if strings.TrimSpace(list) == "" {
+		return true
+	}
+	for _, v := range vals {
+		if matchList(list, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// eventEpochShift 事件 ID 中启动时间(Unix 秒)左移的位数, 每秒运行时间可分配 2^20 个 ID.
+const eventEpochShift = 20
+
+// EventHub 保存最近的事件并向订阅者广播, 支持按事件 ID 续传.
+// 事件 ID 以进程启动时间为前缀, 重启后的 ID 总是大于重启前的 ID, 携带重启前 Last-Event-ID 的客户端会收到全部历史事件.
+type EventHub struct {
+	mu      sync.Mutex
+	firstID uint64 // 本次启动分配的第一个 ID
+	nextID  uint64
+	history []Event
+	limit   int
+	subs    map[chan Event]struct{}
+}
+
+// NewEventHub 创建事件中心, history 为保留的历史事件数量.
+func NewEventHub(history int) *EventHub {
+	if history <= 0 {
+		history = defaultEventHistory
+	}
+	first := uint64(time.Now().Unix()) << eventEpochShift
+	return &EventHub{firstID: first, nextID: first, limit: history, subs: make(map[chan Event]struct{})}
+}
+
+// Publish 为事件分配 ID, 记录到历史并广播给订阅者. 订阅者缓冲区已满时断开该订阅者,
+// 客户端可携带 Last-Event-ID 重新连接续传.
+func (h *EventHub) Publish(events []Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, e := range events {
+		e.ID = h.nextID
+		h.nextID++
+		h.history = append(h.history, e)
+		for ch := range h.subs {
+			select {
+			case ch <- e:
+			default:
+				delete(h.subs, ch)
+				close(ch)
+			}
+		}
+	}
+	if over := len(h.history) - h.limit; over > 0 {
+		h.history = append([]Event(nil), h.history[over:]...)
+	}
+}
+
+// Subscribe 订阅事件, 返回 ID 大于 lastID 的历史事件与后续事件通道. lastID 为 0 表示客户端未携带 Last-Event-ID,
+// 只接收后续事件; lastID 不是本次启动分配的 ID(来自重启前或尚未分配)时视为未知, 返回全部历史事件.
+// 调用方结束时须调用 cancel.
+func (h *EventHub) Subscribe(lastID uint64) (replay []Event, ch <-chan Event, cancel func()) {
+	c := make(chan Event, defaultSubscriberBufferSize)
+	h.mu.Lock()
+	if lastID != 0 {
+		if lastID < h.firstID || lastID >= h.nextID {
+			lastID = 0
+		}
+		for _, e := range h.history {
+			if e.ID > lastID {
+				replay = append(replay, e)
+			}
+		}
+	}
+	h.subs[c] = struct{}{}
+	h.mu.Unlock()
+
+	cancel = func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subs[c]; ok {
+			delete(h.subs, c)
+			close(c)
+		}
+	}
+	return replay, c, cancel
+}
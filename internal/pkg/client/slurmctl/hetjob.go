@@ -0,0 +1,154 @@
+package slurmctl
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"solid/internal/pkg/client/slurmctl/models"
+	"solid/internal/pkg/common/hostlist"
+	jobidpkg "solid/internal/pkg/common/jobid"
+)
+
+// GetJobDetails 通过 scontrol show job 获取作业信息. 异构作业的 leader ID 会返回所有组件,
+// 数组作业 ID 会返回所有任务.
+func (c *Client) GetJobDetails(ctx context.Context, jobid string) ([]models.JobDetail, error) {
+	id, err := jobidpkg.Parse(jobid)
+	if err != nil {
+		return nil, err
+	}
+	cmd := c.execCommand(ctx, "scontrol", "show", "job", id.String())
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(out), "Invalid job id") {
+			return []models.JobDetail{}, nil
+		}
+		c.logger.Error("unable to get job information", "output", string(out), "cmd", cmd.String(), "err", err)
+		return nil, fmt.Errorf("failed to exec %s", cmd.String())
+	}
+	return parseJobDetails(string(out)), nil
+}
+
+// GetHetJob 获取异构作业及其各组件的作业步. jobid 为 leader ID 时返回所有组件,
+// 为 1234+1 形式时只返回该组件; 非异构作业作为只有一个组件的异构作业返回. 作业不存在时返回 nil.
+func (c *Client) GetHetJob(ctx context.Context, jobid string) (*models.HetJob, error) {
+	id, err := jobidpkg.Parse(jobid)
+	if err != nil {
+		return nil, err
+	}
+	if id.IsArrayTask {
+		return nil, fmt.Errorf("array task %s is not a heterogeneous job", id)
+	}
+	// 查询 leader 以获取全部组件, 再按偏移过滤
+	comps, err := c.GetJobDetails(ctx, strconv.FormatUint(uint64(id.JobID), 10))
+	if err != nil {
+		return nil, err
+	}
+	if len(comps) == 0 {
+		return nil, nil
+	}
+	if id.IsHetComponent {
+		offset := strconv.FormatUint(uint64(id.HetOffset), 10)
+		filtered := comps[:0]
+		for _, comp := range comps {
+			if comp.HetJobOffset == offset {
+				filtered = append(filtered, comp)
+			}
+		}
+		if len(filtered) == 0 {
+			return nil, nil
+		}
+		comps = filtered
+	}
+
+	steps, err := c.GetStepsOfJob(ctx, strconv.FormatUint(uint64(id.JobID), 10))
+	if err != nil {
+		return nil, err
+	}
+	assignHetSteps(comps, steps)
+
+	return &models.HetJob{HetJobID: strconv.FormatUint(uint64(id.JobID), 10), Components: comps}, nil
+}
+
+// assignHetSteps 将作业步分配到所属组件. 异构作业的作业步 ID 形如 1234+1.0, 普通作业形如 1234.0.
+func assignHetSteps(comps []models.JobDetail, steps models.Steps) {
+	for i := range comps {
+		comps[i].Steps = make(models.Steps, 0)
+		prefix := comps[i].DisplayID + "."
+		for _, st := range steps {
+			if strings.HasPrefix(st.ID, prefix) {
+				comps[i].Steps = append(comps[i].Steps, st)
+			}
+		}
+	}
+}
+
+// parseJobDetails 解析 scontrol show job 的输出, scontrol 按偏移顺序输出异构作业的各组件.
+func parseJobDetails(content string) []models.JobDetail {
+	jobs := make([]models.JobDetail, 0)
+	for _, raw := range parseRecords(content, "JobId") {
+		if raw["JobId"] == "" {
+			continue
+		}
+		jobs = append(jobs, newJobDetail(raw))
+	}
+	return jobs
+}
+
+// newJobDetail 将 scontrol 原始字段转换为作业模型.
+func newJobDetail(raw map[string]string) models.JobDetail {
+	j := models.JobDetail{
+		JobID:        raw["JobId"],
+		Name:         raw["JobName"],
+		User:         stripID(raw["UserId"]),
+		Account:      raw["Account"],
+		Partition:    raw["Partition"],
+		QoS:          raw["QOS"],
+		State:        raw["JobState"],
+		Reason:       raw["Reason"],
+		Nodelist:     noneToEmpty(raw["NodeList"]),
+		NumNodes:     parseCount(strings.Split(raw["NumNodes"], "-")[0]),
+		NumCPUs:      parseCount(strings.Split(raw["NumCPUs"], "-")[0]),
+		SubmitTime:   raw["SubmitTime"],
+		StartTime:    raw["StartTime"],
+		EndTime:      raw["EndTime"],
+		TRES:         parseKeyValues(raw["TRES"]),
+		HetJobID:     raw["HetJobId"],
+		HetJobOffset: raw["HetJobOffset"],
+		ArrayJobID:   raw["ArrayJobId"],
+		ArrayTaskID:  raw["ArrayTaskId"],
+		Steps:        models.Steps{},
+		Raw:          raw,
+	}
+	j.TimeLimit, _ = ParseDuration(raw["TimeLimit"])
+	switch {
+	case j.HetJobID != "":
+		j.DisplayID = j.HetJobID + "+" + j.HetJobOffset
+	case j.ArrayJobID != "" && isDigits(j.ArrayTaskID):
+		j.DisplayID = j.ArrayJobID + "_" + j.ArrayTaskID
+	case j.ArrayJobID != "":
+		// 尚未拆分的排队任务, 如 ArrayTaskId=5-100
+		j.DisplayID = j.ArrayJobID + "_[" + j.ArrayTaskID + "]"
+	default:
+		j.DisplayID = j.JobID
+	}
+	if nodes, err := hostlist.Expand(j.Nodelist); err == nil {
+		j.Nodes = nodes
+	} else {
+		j.Nodes = []string{}
+	}
+	return j
+}
+
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// stripID 去掉 scontrol 中 name(uid) 形式的 ID 部分.
+func stripID(s string) string {
+	if i := strings.IndexByte(s, '('); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,27 @@
+package models
+
+// SchedulerDiag 为 sdiag 输出解析后的 slurmctld 运行统计.
+// Main 与 Backfill 中的键由 sdiag 的字段名转换为小写下划线形式, 如 "Mean cycle" 转为 mean_cycle.
+type SchedulerDiag struct {
+	Time              int64            `json:"time"`                 // sdiag 输出时间(Unix 秒)
+	DataSince         int64            `json:"data_since"`           // 统计起始时间(Unix 秒)
+	ServerThreadCount int64            `json:"server_thread_count"`  // slurmctld 服务线程数
+	AgentQueueSize    int64            `json:"agent_queue_size"`     // agent 队列长度
+	AgentCount        int64            `json:"agent_count"`          // agent 数量
+	AgentThreadCount  int64            `json:"agent_thread_count"`   // agent 线程数
+	DBDAgentQueueSize int64            `json:"dbd_agent_queue_size"` // slurmdbd agent 队列长度
+	Jobs              map[string]int64 `json:"jobs"`                 // 作业计数, 如 submitted, started, pending, running
+	Main              map[string]int64 `json:"main"`                 // 主调度器周期统计(微秒)
+	Backfill          map[string]int64 `json:"backfill"`             // 回填调度器周期统计(微秒)
+	RPCByType         []RPCStat        `json:"rpc_by_message_type"`  // 按消息类型的 RPC 统计
+	RPCByUser         []RPCStat        `json:"rpc_by_user"`          // 按用户的 RPC 统计
+}
+
+// RPCStat 单个消息类型或用户的 RPC 统计, 时间单位为微秒.
+type RPCStat struct {
+	Name      string `json:"name"`       // 消息类型或用户名
+	ID        int64  `json:"id"`         // 消息类型编号或 UID
+	Count     int64  `json:"count"`      // 调用次数
+	AveTime   int64  `json:"ave_time"`   // 平均耗时
+	TotalTime int64  `json:"total_time"` // 总耗时
+}
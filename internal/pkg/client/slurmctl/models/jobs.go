@@ -3,15 +3,19 @@ package models
 type Jobs []Job
 
 type Job struct {
-	Jobid     string `json:"jobid"`     // 作业ID
-	State     string `json:"state"`     // 状态
-	User      string `json:"user"`      // 用户
-	Account   string `json:"account"`   // 账户
-	CPUs      string `json:"cpus"`      // 资源个数
-	Nodelist  string `json:"nodelist"`  // 节点列表
-	Partition string `json:"partition"` // 分区
-	QoS       string `json:"qos"`       // QoS
-	Reason    string `json:"reason"`    // 原因
+	Jobid      string   `json:"jobid"`           // 作业ID
+	State      string   `json:"state"`           // 状态
+	User       string   `json:"user"`            // 用户
+	Account    string   `json:"account"`         // 账户
+	CPUs       string   `json:"cpus"`            // 资源个数
+	Nodelist   string   `json:"nodelist"`        // 节点列表
+	Partition  string   `json:"partition"`       // 分区
+	QoS        string   `json:"qos"`             // QoS
+	Reason     string   `json:"reason"`          // 原因
+	Priority   int64    `json:"priority"`        // 优先级
+	SubmitTime string   `json:"submit_time"`     // 提交时间
+	Name       string   `json:"name"`            // 作业名称
+	Nodes      []string `json:"nodes,omitempty"` // 展开后的节点列表, 仅在请求 expand_nodes=true 时填充
 }
 
 type Steps []Step
@@ -21,3 +25,45 @@ type Step struct {
 	Name  string `json:"Name"`
 	State string `json:"state"`
 }
+
+// ArraySummary 数组作业在队列中的任务统计.
+type ArraySummary struct {
+	ArrayJobID uint32         `json:"array_job_id"` // 数组作业 ID
+	Tasks      int            `json:"tasks"`        // 队列中的任务数量
+	States     map[string]int `json:"states"`       // 各状态的任务数量
+}
+
+// JobDetail 为 scontrol show job 解析后的作业信息, 异构作业的每个组件为一条记录.
+// 未单独建模的字段及原始取值保留在 Raw 中.
+type JobDetail struct {
+	JobID        string            `json:"jobid"`          // 作业 ID, 异构作业组件为组件自身的作业 ID
+	DisplayID    string            `json:"display_id"`     // 常用表示法, 如 1234+1, 123_7
+	Name         string            `json:"name"`           // 作业名称
+	User         string            `json:"user"`           // 用户
+	Account      string            `json:"account"`        // 账户
+	Partition    string            `json:"partition"`      // 分区
+	QoS          string            `json:"qos"`            // QoS
+	State        string            `json:"state"`          // 状态, 如 RUNNING
+	Reason       string            `json:"reason"`         // 等待原因
+	Nodelist     string            `json:"nodelist"`       // 节点 hostlist 表达式
+	Nodes        []string          `json:"nodes"`          // 展开后的节点列表
+	NumNodes     int               `json:"num_nodes"`      // 节点数
+	NumCPUs      int               `json:"num_cpus"`       // CPU 数
+	TimeLimit    int64             `json:"time_limit"`     // 运行时间限制(秒), -1 表示 UNLIMITED
+	SubmitTime   string            `json:"submit_time"`    // 提交时间
+	StartTime    string            `json:"start_time"`     // 开始时间
+	EndTime      string            `json:"end_time"`       // 结束时间
+	TRES         map[string]string `json:"tres"`           // 分配的 TRES
+	HetJobID     string            `json:"het_job_id"`     // 异构作业 leader ID, 非异构作业为空
+	HetJobOffset string            `json:"het_job_offset"` // 异构作业组件偏移
+	ArrayJobID   string            `json:"array_job_id"`   // 数组作业 ID
+	ArrayTaskID  string            `json:"array_task_id"`  // 数组任务 ID
+	Steps        Steps             `json:"steps"`          // 组件的作业步
+	Raw          map[string]string `json:"raw"`            // scontrol 原始字段
+}
+
+// HetJob 异构作业, 由 leader 与按偏移排序的组件组成. 非异构作业只有一个组件.
+type HetJob struct {
+	HetJobID   string      `json:"het_job_id"` // leader 作业 ID
+	Components []JobDetail `json:"components"` // 各组件
+}
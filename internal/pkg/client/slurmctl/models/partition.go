@@ -2,4 +2,40 @@ package models
 
 type Partitions []Partition
 
-type Partition map[string]string
+// Partition 为 scontrol show partition 解析后的分区信息.
+// 时长字段单位为秒, -1 表示 UNLIMITED; 数量字段 -1 表示 UNLIMITED.
+// 未单独建模的字段及原始取值保留在 Raw 中.
+type Partition struct {
+	Name              string            `json:"name"`                 // 分区名称
+	State             string            `json:"state"`                // 分区状态, UP/DOWN/DRAIN/INACTIVE
+	Default           bool              `json:"default"`              // 是否为默认分区
+	Hidden            bool              `json:"hidden"`               // 是否隐藏
+	RootOnly          bool              `json:"root_only"`            // 是否仅允许 root 提交
+	ReqResv           bool              `json:"req_resv"`             // 是否要求预约
+	ExclusiveUser     bool              `json:"exclusive_user"`       // 是否按用户独占节点
+	DisableRootJobs   bool              `json:"disable_root_jobs"`    // 是否禁止 root 作业
+	LLN               bool              `json:"lln"`                  // 是否优先分配负载最低的节点
+	OverSubscribe     string            `json:"oversubscribe"`        // 超额订阅策略
+	PreemptMode       string            `json:"preempt_mode"`         // 抢占模式
+	QoS               string            `json:"qos"`                  // 分区 QoS
+	PriorityTier      int               `json:"priority_tier"`        // 优先级层级
+	PriorityJobFactor int               `json:"priority_job_factor"`  // 作业优先级因子
+	MaxTime           int64             `json:"max_time"`             // 最大运行时间(秒)
+	DefaultTime       int64             `json:"default_time"`         // 默认运行时间(秒), 未设置为 0
+	MinNodes          int               `json:"min_nodes"`            // 单作业最少节点数
+	MaxNodes          int               `json:"max_nodes"`            // 单作业最多节点数
+	MaxCPUsPerNode    int               `json:"max_cpus_per_node"`    // 单节点最多 CPU 数
+	TotalCPUs         int               `json:"total_cpus"`           // 分区 CPU 总数
+	TotalNodes        int               `json:"total_nodes"`          // 分区节点总数
+	AllowGroups       []string          `json:"allow_groups"`         // 允许的用户组
+	AllowAccounts     []string          `json:"allow_accounts"`       // 允许的账户
+	AllowQos          []string          `json:"allow_qos"`            // 允许的 QoS
+	DenyAccounts      []string          `json:"deny_accounts"`        // 禁止的账户
+	DenyQos           []string          `json:"deny_qos"`             // 禁止的 QoS
+	Nodelist          string            `json:"nodelist"`             // 节点 hostlist 表达式
+	Nodes             []string          `json:"nodes"`                // 展开后的节点列表
+	TRES              map[string]string `json:"tres"`                 // 分区 TRES, 如 cpu=100, mem=1000G
+	TRESBillingWeight map[string]string `json:"tres_billing_weights"` // TRES 计费权重
+	NodeStates        map[string]int    `json:"node_states"`          // 各状态的节点数量, 来自 sinfo
+	Raw               map[string]string `json:"raw"`                  // scontrol 原始字段
+}
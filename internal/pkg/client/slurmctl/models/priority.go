@@ -0,0 +1,58 @@
+package models
+
+// JobPriority 为 sprio 输出的作业优先级构成, 作业提交到多个分区时每个分区一条记录.
+// Weighted 为乘以权重后计入总优先级的值, Normalized 为 0-1 之间的归一化因子.
+type JobPriority struct {
+	JobID      string            `json:"jobid"`      // 作业 ID
+	Partition  string            `json:"partition"`  // 分区
+	User       string            `json:"user"`       // 用户
+	Account    string            `json:"account"`    // 账户
+	QoS        string            `json:"qos"`        // QoS 名称
+	Priority   float64           `json:"priority"`   // 总优先级
+	Weighted   PriorityFactors   `json:"weighted"`   // 加权后的各因子
+	Normalized PriorityFactors   `json:"normalized"` // 归一化的各因子
+	Nice       int64             `json:"nice"`       // nice 值, 从优先级中扣除
+	TRES       map[string]string `json:"tres"`       // 各 TRES 的加权因子, 如 cpu=12
+}
+
+// PriorityFactors 多因子优先级插件的各项因子.
+type PriorityFactors struct {
+	Site      float64 `json:"site"`      // 站点因子
+	Age       float64 `json:"age"`       // 排队时长因子
+	Assoc     float64 `json:"assoc"`     // 关联因子
+	FairShare float64 `json:"fairshare"` // 公平共享因子
+	JobSize   float64 `json:"jobsize"`   // 作业规模因子
+	Partition float64 `json:"partition"` // 分区因子
+	QoS       float64 `json:"qos"`       // QoS 因子
+}
+
+// FairshareNode 为 sshare 输出中的账户节点, 按账户层级组织.
+// LevelFS 为 nil 表示 sshare 输出为 inf(该层级没有使用量)或为空.
+type FairshareNode struct {
+	Account        string          `json:"account"`         // 账户名称
+	RawShares      string          `json:"raw_shares"`      // 配置的份额, 可能为 parent
+	NormShares     float64         `json:"norm_shares"`     // 归一化份额
+	RawUsage       int64           `json:"raw_usage"`       // 原始使用量(衰减后的 CPU 秒)
+	NormUsage      float64         `json:"norm_usage"`      // 归一化使用量
+	EffectiveUsage float64         `json:"effective_usage"` // 有效使用量
+	FairShare      float64         `json:"fairshare"`       // 公平共享因子
+	LevelFS        *float64        `json:"level_fs"`        // 本层级公平共享值
+	GrpTRESMins    string          `json:"grp_tres_mins"`   // 组 TRES 分钟限制
+	TRESRunMins    string          `json:"tres_run_mins"`   // 运行中作业剩余 TRES 分钟
+	SubAccounts    []FairshareNode `json:"sub_accounts"`    // 子账户
+	Users          []FairshareUser `json:"users"`           // 账户下的用户
+}
+
+// FairshareUser 为 sshare 输出中的用户关联.
+type FairshareUser struct {
+	User           string   `json:"user"`
+	RawShares      string   `json:"raw_shares"`
+	NormShares     float64  `json:"norm_shares"`
+	RawUsage       int64    `json:"raw_usage"`
+	NormUsage      float64  `json:"norm_usage"`
+	EffectiveUsage float64  `json:"effective_usage"`
+	FairShare      float64  `json:"fairshare"`
+	LevelFS        *float64 `json:"level_fs"`
+	GrpTRESMins    string   `json:"grp_tres_mins"`
+	TRESRunMins    string   `json:"tres_run_mins"`
+}
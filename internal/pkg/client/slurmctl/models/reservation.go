@@ -0,0 +1,26 @@
+package models
+
+type Reservations []Reservation
+
+// Reservation 为 scontrol show reservation 解析后的预约信息.
+// 时长字段单位为秒, -1 表示 UNLIMITED; 未单独建模的字段及原始取值保留在 Raw 中.
+type Reservation struct {
+	Name      string            `json:"name"`       // 预约名称
+	State     string            `json:"state"`      // 预约状态, ACTIVE/INACTIVE
+	StartTime string            `json:"start_time"` // 开始时间, 如 2024-01-01T08:00:00
+	EndTime   string            `json:"end_time"`   // 结束时间
+	Duration  int64             `json:"duration"`   // 持续时间(秒)
+	Nodelist  string            `json:"nodelist"`   // 节点 hostlist 表达式
+	Nodes     []string          `json:"nodes"`      // 展开后的节点列表
+	NodeCount int               `json:"node_count"` // 节点数量
+	CoreCount int               `json:"core_count"` // 核心数量
+	Partition string            `json:"partition"`  // 分区
+	Features  string            `json:"features"`   // 节点特性要求
+	Users     []string          `json:"users"`      // 允许的用户, "-" 前缀表示排除
+	Accounts  []string          `json:"accounts"`   // 允许的账户, "-" 前缀表示排除
+	Groups    []string          `json:"groups"`     // 允许的用户组
+	Flags     []string          `json:"flags"`      // 预约标志, 如 MAINT, IGNORE_JOBS
+	TRES      map[string]string `json:"tres"`       // 预约的 TRES
+	Licenses  string            `json:"licenses"`   // 预约的 License
+	Raw       map[string]string `json:"raw"`        // scontrol 原始字段
+}
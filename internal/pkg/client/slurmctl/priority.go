@@ -0,0 +1,227 @@
+package slurmctl
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"solid/internal/pkg/client/slurmctl/models"
+	jobidpkg "solid/internal/pkg/common/jobid"
+)
+
+// sprioFormat sprio 输出格式, 与 sprio -l 的列一致并追加归一化因子.
+// JOBID PARTITION USER ACCOUNT QOSNAME PRIORITY SITE AGE ASSOC FAIRSHARE JOBSIZE PARTITION QOS NICE TRES
+// 以及归一化的 PRIORITY AGE ASSOC FAIRSHARE JOBSIZE PARTITION QOS.
+const (
+	sprioFormat = "%i|%r|%u|%o|%n|%Y|%S|%A|%B|%F|%J|%P|%Q|%N|%T|%y|%a|%b|%f|%j|%p|%q"
+	sprioFields = 22
+)
+
+// GetJobPriority 通过 sprio 获取作业的优先级构成, 作业不在排队中时返回空切片.
+func (c *Client) GetJobPriority(ctx context.Context, jobid string) ([]models.JobPriority, error) {
+	id, err := jobidpkg.Parse(jobid)
+	if err != nil {
+		return nil, err
+	}
+	cmd := c.execCommand(ctx, "sprio", "-h", "-j", id.String(), "-o", sprioFormat)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		// 作业已开始运行或不存在时 sprio 返回错误
+		if strings.Contains(string(out), "Unable to find jobs") {
+			return []models.JobPriority{}, nil
+		}
+		c.logger.Error("unable to get job priority", "output", string(out), "cmd", cmd.String(), "err", err)
+		return nil, fmt.Errorf("failed to exec %s", cmd.String())
+	}
+	return parseSprio(string(out)), nil
+}
+
+// parseSprio 解析 sprioFormat 格式的输出, 忽略字段数不符的行.
+func parseSprio(content string) []models.JobPriority {
+	rows := make([]models.JobPriority, 0)
+	for _, line := range strings.Split(content, "\n") {
+		f := strings.Split(strings.TrimSpace(line), "|")
+		if len(f) != sprioFields {
+			continue
+		}
+		for i := range f {
+			f[i] = strings.TrimSpace(f[i])
+		}
+		rows = append(rows, models.JobPriority{
+			JobID:     f[0],
+			Partition: f[1],
+			User:      f[2],
+			Account:   f[3],
+			QoS:       f[4],
+			Priority:  parseFloat(f[5]),
+			Weighted: models.PriorityFactors{
+				Site:      parseFloat(f[6]),
+				Age:       parseFloat(f[7]),
+				Assoc:     parseFloat(f[8]),
+				FairShare: parseFloat(f[9]),
+				JobSize:   parseFloat(f[10]),
+				Partition: parseFloat(f[11]),
+				QoS:       parseFloat(f[12]),
+			},
+			Nice: int64(parseFloat(f[13])),
+			TRES: parseKeyValues(f[14]),
+			Normalized: models.PriorityFactors{
+				Age:       parseFloat(f[16]),
+				Assoc:     parseFloat(f[17]),
+				FairShare: parseFloat(f[18]),
+				JobSize:   parseFloat(f[19]),
+				Partition: parseFloat(f[20]),
+				QoS:       parseFloat(f[21]),
+			},
+		})
+	}
+	return rows
+}
+
+// GetFairshare 通过 sshare -a -l -P 获取公平共享信息, 返回以 root 为根的账户树.
+// account 非空时返回以该账户为根的子树, 账户不存在时返回 nil.
+func (c *Client) GetFairshare(ctx context.Context, account string) (*models.FairshareNode, error) {
+	cmd := c.execCommand(ctx, "sshare", "-a", "-l", "-P")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		c.logger.Error("unable to get fairshare", "output", string(out), "cmd", cmd.String(), "err", err)
+		return nil, fmt.Errorf("failed to exec %s", cmd.String())
+	}
+	root, err := parseSshare(string(out))
+	if err != nil {
+		return nil, err
+	}
+	if account = strings.TrimSpace(account); account != "" {
+		return findFairshareNode(root, account), nil
+	}
+	return root, nil
+}
+
+// parseSshare 解析 sshare -a -l -P 的输出. 首行为表头, 账户名前的空格数表示层级深度,
+// User 列为空的行为账户, 否则为该账户下的用户关联.
+func parseSshare(content string) (*models.FairshareNode, error) {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if len(lines) < 2 {
+		return nil, fmt.Errorf("unexpected sshare output")
+	}
+	index := make(map[string]int)
+	for i, h := range strings.Split(lines[0], "|") {
+		index[strings.TrimSpace(h)] = i
+	}
+	if _, ok := index["Account"]; !ok {
+		return nil, fmt.Errorf("unexpected sshare header: %s", lines[0])
+	}
+
+	type level struct {
+		depth int
+		node  *models.FairshareNode
+	}
+	var root *models.FairshareNode
+	stack := make([]level, 0)
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		f := strings.Split(line, "|")
+		get := func(name string) string {
+			if i, ok := index[name]; ok && i < len(f) {
+				return strings.TrimSpace(f[i])
+			}
+			return ""
+		}
+		rawAccount := f[index["Account"]]
+		depth := len(rawAccount) - len(strings.TrimLeft(rawAccount, " "))
+
+		// 弹出深度不小于当前行的账户, 栈顶即为父账户
+		for len(stack) > 0 && stack[len(stack)-1].depth >= depth {
+			stack = stack[:len(stack)-1]
+		}
+
+		if user := get("User"); user != "" {
+			if len(stack) == 0 {
+				continue
+			}
+			parent := stack[len(stack)-1].node
+			parent.Users = append(parent.Users, models.FairshareUser{
+				User:           user,
+				RawShares:      get("RawShares"),
+				NormShares:     parseFloat(get("NormShares")),
+				RawUsage:       int64(parseFloat(get("RawUsage"))),
+				NormUsage:      parseFloat(get("NormUsage")),
+				EffectiveUsage: parseFloat(get("EffectvUsage")),
+				FairShare:      parseFloat(get("FairShare")),
+				LevelFS:        parseLevelFS(get("LevelFS")),
+				GrpTRESMins:    get("GrpTRESMins"),
+				TRESRunMins:    get("TRESRunMins"),
+			})
+			continue
+		}
+
+		node := &models.FairshareNode{
+			Account:        strings.TrimSpace(rawAccount),
+			RawShares:      get("RawShares"),
+			NormShares:     parseFloat(get("NormShares")),
+			RawUsage:       int64(parseFloat(get("RawUsage"))),
+			NormUsage:      parseFloat(get("NormUsage")),
+			EffectiveUsage: parseFloat(get("EffectvUsage")),
+			FairShare:      parseFloat(get("FairShare")),
+			LevelFS:        parseLevelFS(get("LevelFS")),
+			GrpTRESMins:    get("GrpTRESMins"),
+			TRESRunMins:    get("TRESRunMins"),
+			SubAccounts:    []models.FairshareNode{},
+			Users:          []models.FairshareUser{},
+		}
+		if len(stack) == 0 {
+			if root == nil {
+				root = node
+			}
+		} else {
+			// 栈中只保留当前路径上的节点, 追加兄弟节点导致切片扩容时前一个兄弟节点已解析完成并出栈,
+			// 因此入栈的元素地址在其子树解析完成前保持有效.
+			parent := stack[len(stack)-1].node
+			parent.SubAccounts = append(parent.SubAccounts, *node)
+			node = &parent.SubAccounts[len(parent.SubAccounts)-1]
+		}
+		stack = append(stack, level{depth: depth, node: node})
+	}
+	if root == nil {
+		return nil, fmt.Errorf("no root account in sshare output")
+	}
+	return root, nil
+}
+
+// findFairshareNode 深度优先查找账户节点.
+func findFairshareNode(node *models.FairshareNode, account string) *models.FairshareNode {
+	if node == nil {
+		return nil
+	}
+	if node.Account == account {
+		return node
+	}
+	for i := range node.SubAccounts {
+		if found := findFairshareNode(&node.SubAccounts[i], account); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// parseLevelFS 解析 LevelFS, inf 与空值返回 nil.
+func parseLevelFS(s string) *float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsInf(v, 0) || math.IsNaN(v) {
+		return nil
+	}
+	return &v
+}
+
+// parseFloat 解析浮点数, 无法解析时返回 0.
+func parseFloat(s string) float64 {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || math.IsInf(v, 0) || math.IsNaN(v) {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,99 @@
+package slurmctl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	jobidpkg "solid/internal/pkg/common/jobid"
+)
+
+// jobReasonTexts squeue 作业等待原因代码对应的说明.
+var jobReasonTexts = map[string]string{
+	"None":                        "作业没有等待原因",
+	"Priority":                    "队列中有优先级更高的作业在等待资源, 作业需要排队",
+	"Resources":                   "作业优先级最高, 正在等待足够的空闲资源",
+	"Dependency":                  "作业依赖的其他作业尚未满足条件",
+	"DependencyNeverSatisfied":    "作业依赖的条件永远无法满足, 需要取消或修改依赖",
+	"JobHeldUser":                 "作业被用户挂起(hold), 需要 scontrol release",
+	"JobHeldAdmin":                "作业被管理员挂起(hold), 需要管理员释放",
+	"BeginTime":                   "作业设置的最早开始时间尚未到达",
+	"ReqNodeNotAvail":             "作业请求的节点当前不可用(down, drain 或被预约)",
+	"NodeDown":                    "作业所需的节点处于 down 状态",
+	"PartitionDown":               "分区处于 DOWN 状态",
+	"PartitionInactive":           "分区处于 INACTIVE 状态",
+	"PartitionNodeLimit":          "作业请求的节点数超出分区限制",
+	"PartitionTimeLimit":          "作业请求的运行时间超出分区 MaxTime",
+	"Reservation":                 "作业在等待预约开始",
+	"Licenses":                    "作业在等待 License",
+	"BadConstraints":              "作业的约束条件无法被任何节点满足",
+	"InvalidAccount":              "作业的账户无效",
+	"InvalidQOS":                  "作业的 QoS 无效",
+	"QOSUsageThreshold":           "QoS 的使用量超出阈值",
+	"Cleaning":                    "作业正在被清理, 稍后会重新排队",
+	"Prolog":                      "作业的 Prolog 正在执行",
+	"AssocGrpJobsLimit":           "账户关联的运行作业数(GrpJobs)已达上限",
+	"AssocGrpSubmitJobsLimit":     "账户关联的提交作业数(GrpSubmitJobs)已达上限",
+	"AssocMaxJobsLimit":           "用户关联的运行作业数(MaxJobs)已达上限",
+	"AssocGrpCpuLimit":            "账户关联的 CPU 总量(GrpTRES cpu)已达上限",
+	"AssocGrpNodeLimit":           "账户关联的节点总量(GrpTRES node)已达上限",
+	"AssocGrpMemLimit":            "账户关联的内存总量(GrpTRES mem)已达上限",
+	"QOSMaxJobsPerUserLimit":      "QoS 单用户运行作业数(MaxJobsPerUser)已达上限",
+	"QOSMaxSubmitJobPerUserLimit": "QoS 单用户提交作业数(MaxSubmitJobsPerUser)已达上限",
+	"QOSGrpJobsLimit":             "QoS 运行作业总数(GrpJobs)已达上限",
+	"QOSGrpCpuLimit":              "QoS CPU 总量(GrpTRES cpu)已达上限",
+	"QOSGrpNodeLimit":             "QoS 节点总量(GrpTRES node)已达上限",
+	"QOSMaxCpuPerUserLimit":       "QoS 单用户 CPU 数(MaxTRESPerUser cpu)已达上限",
+	"QOSMaxNodePerUserLimit":      "QoS 单用户节点数(MaxTRESPerUser node)已达上限",
+}
+
+// ReasonText 返回等待原因代码的说明. 未收录的 Assoc*/QOS* 原因按前缀给出通用说明.
+func ReasonText(reason string) string {
+	reason = strings.TrimSpace(reason)
+	if text, ok := jobReasonTexts[reason]; ok {
+		return text
+	}
+	switch {
+	case strings.HasPrefix(reason, "Assoc"):
+		return fmt.Sprintf("作业触发了关联(association)限制 %s", reason)
+	case strings.HasPrefix(reason, "QOS"):
+		return fmt.Sprintf("作业触发了 QoS 限制 %s", reason)
+	case strings.HasPrefix(reason, "Partition"):
+		return fmt.Sprintf("作业触发了分区限制 %s", reason)
+	case reason == "":
+		return ""
+	}
+	return fmt.Sprintf("未知的等待原因 %s", reason)
+}
+
+// JobStart squeue --start 给出的预计开始时间与计划使用的节点.
+type JobStart struct {
+	JobID      string `json:"jobid"`
+	StartTime  string `json:"start_time"`  // 预计开始时间, 无法估计时为空
+	SchedNodes string `json:"sched_nodes"` // 计划分配的节点, 无法估计时为空
+}
+
+// GetJobStart 通过 squeue --start 获取排队作业的预计开始时间, 作业不在排队中时返回 nil.
+func (c *Client) GetJobStart(ctx context.Context, jobid string) (*JobStart, error) {
+	id, err := jobidpkg.Parse(jobid)
+	if err != nil {
+		return nil, err
+	}
+	cmd := c.execCommand(ctx, "squeue", "--start", "-h", "-j", id.String(), "-o", "%i|%S|%Y")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(out), "Invalid job id") {
+			return nil, nil
+		}
+		c.logger.Error("unable to get job start time", "output", string(out), "cmd", cmd.String(), "err", err)
+		return nil, fmt.Errorf("failed to exec %s", cmd.String())
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		f := strings.Split(strings.TrimSpace(line), "|")
+		if len(f) != 3 {
+			continue
+		}
+		return &JobStart{JobID: f[0], StartTime: noneToEmpty(f[1]), SchedNodes: noneToEmpty(f[2])}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,369 @@
+package slurmctl
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"solid/internal/pkg/client/slurmctl/models"
+	"solid/internal/pkg/common/hostlist"
+)
+
+// reservationFlags scontrol 支持的预约标志.
+var reservationFlags = map[string]bool{
+	"ANY_NODES":              true,
+	"DAILY":                  true,
+	"FIRST_CORES":            true,
+	"FLEX":                   true,
+	"HOURLY":                 true,
+	"IGNORE_JOBS":            true,
+	"LICENSE_ONLY":           true,
+	"MAGNETIC":               true,
+	"MAINT":                  true,
+	"NO_HOLD_JOBS_AFTER":     true,
+	"OVERLAP":                true,
+	"PART_NODES":             true,
+	"PURGE_COMP":             true,
+	"REPLACE":                true,
+	"REPLACE_DOWN":           true,
+	"SPEC_NODES":             true,
+	"STATIC_ALLOC":           true,
+	"TIME_FLOAT":             true,
+	"USER_DELETE":            true,
+	"WEEKDAY":                true,
+	"WEEKEND":                true,
+	"WEEKLY":                 true,
+	"NO_HOLD_JOBS_AFTER_END": true,
+}
+
+var (
+	// reservationNameRe 预约、用户与账户名称, 不允许空白、逗号与等号.
+	reservationNameRe = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.@-]*$`)
+	// relativeStartRe 相对开始时间, 如 now, now+30minutes, now+2hours.
+	relativeStartRe = regexp.MustCompile(`^now(\+\d+(seconds|minutes|hours|days|weeks)?)?$`)
+)
+
+// ReservationSpec 创建或修改预约的参数, 零值字段表示不设置.
+type ReservationSpec struct {
+	Name      string   `json:"name"`       // 预约名称, 创建时为空则由 slurmctld 生成
+	StartTime string   `json:"start_time"` // 开始时间: now, now+<n><unit>, YYYY-MM-DD[THH:MM[:SS]]
+	EndTime   string   `json:"end_time"`   // 结束时间, 格式同 StartTime, 与 Duration 二选一
+	Duration  string   `json:"duration"`   // 持续时间, Slurm 时长格式或 UNLIMITED
+	Nodes     string   `json:"nodes"`      // 节点 hostlist 表达式, ALL 表示全部节点
+	NodeCount int      `json:"node_count"` // 节点数量, 与 Nodes 二选一
+	Partition string   `json:"partition"`  // 分区
+	Users     []string `json:"users"`      // 用户, "-" 前缀表示排除, 不能与不带前缀的混用
+	Accounts  []string `json:"accounts"`   // 账户, 规则同 Users
+	Flags     []string `json:"flags"`      // 预约标志; 修改时 "-" 前缀表示清除该标志
+}
+
+// ValidateCreate 校验创建预约的参数. 只校验格式, 节点、用户与账户是否存在见 CreateReservation.
+func (s ReservationSpec) ValidateCreate() error {
+	if strings.TrimSpace(s.StartTime) == "" {
+		return fmt.Errorf("start_time is required")
+	}
+	if strings.TrimSpace(s.Duration) == "" && strings.TrimSpace(s.EndTime) == "" {
+		return fmt.Errorf("duration or end_time is required")
+	}
+	if strings.TrimSpace(s.Nodes) == "" && s.NodeCount <= 0 {
+		return fmt.Errorf("nodes or node_count is required")
+	}
+	if len(s.Users) == 0 && len(s.Accounts) == 0 {
+		return fmt.Errorf("users or accounts is required")
+	}
+	return s.validate(false)
+}
+
+// ValidateUpdate 校验修改预约的参数, Name 必填且至少修改一个字段.
+func (s ReservationSpec) ValidateUpdate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return fmt.Errorf("reservation name is required")
+	}
+	if len(s.args()) == 0 {
+		return fmt.Errorf("nothing to update")
+	}
+	return s.validate(true)
+}
+
+func (s ReservationSpec) validate(update bool) error {
+	if name := strings.TrimSpace(s.Name); name != "" && !reservationNameRe.MatchString(name) {
+		return fmt.Errorf("invalid reservation name: %q", name)
+	}
+	for _, t := range []struct{ field, value string }{{"start_time", s.StartTime}, {"end_time", s.EndTime}} {
+		if v := strings.TrimSpace(t.value); v != "" {
+			if err := validateReservationTime(v); err != nil {
+				return fmt.Errorf("invalid %s: %w", t.field, err)
+			}
+		}
+	}
+	if s.Duration != "" && s.EndTime != "" {
+		return fmt.Errorf("duration and end_time are mutually exclusive")
+	}
+	if v := strings.TrimSpace(s.Duration); v != "" {
+		secs, err := ParseDuration(v)
+		if err != nil || secs == 0 {
+			return fmt.Errorf("invalid duration: %q", v)
+		}
+	}
+	if v := strings.TrimSpace(s.Nodes); v != "" && !strings.EqualFold(v, "ALL") {
+		if _, err := hostlist.Expand(v); err != nil {
+			return fmt.Errorf("invalid nodes: %w", err)
+		}
+	}
+	if s.NodeCount < 0 {
+		return fmt.Errorf("invalid node_count: %d", s.NodeCount)
+	}
+	if v := strings.TrimSpace(s.Partition); v != "" && !reservationNameRe.MatchString(v) {
+		return fmt.Errorf("invalid partition: %q", v)
+	}
+	if err := validateNameList("users", s.Users); err != nil {
+		return err
+	}
+	if err := validateNameList("accounts", s.Accounts); err != nil {
+		return err
+	}
+	for _, f := range s.Flags {
+		flag := strings.ToUpper(strings.TrimSpace(f))
+		if update {
+			flag = strings.TrimPrefix(flag, "-")
+		}
+		if !reservationFlags[flag] {
+			return fmt.Errorf("unsupported reservation flag: %q", f)
+		}
+	}
+	return nil
+}
+
+// validateReservationTime 校验 scontrol 接受的时间格式.
+func validateReservationTime(v string) error {
+	if relativeStartRe.MatchString(strings.ToLower(v)) {
+		return nil
+	}
+	for _, layout := range []string{"2006-01-02", "2006-01-02T15:04", "2006-01-02T15:04:05"} {
+		if _, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("unrecognized time %q", v)
+}
+
+// validateNameList 校验用户或账户列表, "-" 前缀表示排除且不能与不带前缀的名称混用.
+func validateNameList(field string, names []string) error {
+	negated := 0
+	for _, n := range names {
+		n = strings.TrimSpace(n)
+		if strings.HasPrefix(n, "-") {
+			negated++
+			n = n[1:]
+		}
+		if !reservationNameRe.MatchString(n) {
+			return fmt.Errorf("invalid %s entry: %q", field, n)
+		}
+	}
+	if negated > 0 && negated != len(names) {
+		return fmt.Errorf("%s cannot mix excluded and included entries", field)
+	}
+	return nil
+}
+
+// args 返回 scontrol create/update reservation 中除 ReservationName 以外的参数.
+func (s ReservationSpec) args() []string {
+	args := []string{}
+	add := func(key, val string) {
+		if val = strings.TrimSpace(val); val != "" {
+			args = append(args, key+"="+val)
+		}
+	}
+	add("StartTime", s.StartTime)
+	add("EndTime", s.EndTime)
+	add("Duration", s.Duration)
+	add("Nodes", s.Nodes)
+	if s.NodeCount > 0 {
+		add("NodeCnt", fmt.Sprint(s.NodeCount))
+	}
+	add("PartitionName", s.Partition)
+	add("Users", joinTrimmed(s.Users))
+	add("Accounts", joinTrimmed(s.Accounts))
+	add("Flags", strings.ToUpper(joinTrimmed(s.Flags)))
+	return args
+}
+
+func joinTrimmed(vals []string) string {
+	out := make([]string, 0, len(vals))
+	for _, v := range vals {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return strings.Join(out, ",")
+}
+
+// GetReservations 获取所有预约.
+func (c *Client) GetReservations(ctx context.Context) (models.Reservations, error) {
+	cmd := c.execCommand(ctx, "scontrol", "show", "reservation")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		c.logger.Error("unable to get reservations", "output", string(out), "cmd", cmd.String(), "err", err)
+		return nil, fmt.Errorf("failed to exec %s", cmd.String())
+	}
+	return parseReservations(string(out)), nil
+}
+
+// GetReservation 获取指定名称的预约, 预约不存在时返回 nil; 名称不合法时返回 ErrInvalidArgument.
+func (c *Client) GetReservation(ctx context.Context, name string) (*models.Reservation, error) {
+	name = strings.TrimSpace(name)
+	if !reservationNameRe.MatchString(name) {
+		return nil, fmt.Errorf("%w: invalid reservation name: %q", ErrInvalidArgument, name)
+	}
+	cmd := c.execCommand(ctx, "scontrol", "show", "reservation", name)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(out), "not found") {
+			return nil, nil
+		}
+		c.logger.Error("unable to get reservation", "output", string(out), "cmd", cmd.String(), "err", err)
+		return nil, fmt.Errorf("failed to exec %s", cmd.String())
+	}
+	resvs := parseReservations(string(out))
+	if len(resvs) == 0 {
+		return nil, nil
+	}
+	return &resvs[0], nil
+}
+
+// checkNodes 检查 nodes 展开后的节点均存在于 sinfo 的输出中, nodes 为空或 ALL 时不检查. 存在未知节点时返回 ErrInvalidArgument.
+func (c *Client) checkNodes(ctx context.Context, nodes string) error {
+	nodes = strings.TrimSpace(nodes)
+	if nodes == "" || strings.EqualFold(nodes, "ALL") {
+		return nil
+	}
+	hosts, err := hostlist.Expand(nodes)
+	if err != nil {
+		return fmt.Errorf("%w: invalid nodes: %s", ErrInvalidArgument, err)
+	}
+	known, err := c.GetNodes(ctx, "")
+	if err != nil {
+		return err
+	}
+	unknown := make([]string, 0)
+	for _, h := range hosts {
+		if _, ok := known[h]; !ok {
+			unknown = append(unknown, h)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("%w: unknown nodes: %s", ErrInvalidArgument, strings.Join(unknown, ","))
+	}
+	return nil
+}
+
+// CreateReservation 创建预约, 返回 slurmctld 确认的预约名称. 调用前应先执行 ValidateCreate.
+// 节点在执行 scontrol 前按 sinfo 检查是否存在, 存在未知节点时返回 ErrInvalidArgument;
+// 用户与账户是否存在由调用方按 slurmdb 检查, 未检查时由 scontrol 拒绝.
+func (c *Client) CreateReservation(ctx context.Context, spec ReservationSpec, operator string) (string, error) {
+	if err := spec.ValidateCreate(); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidArgument, err)
+	}
+	if err := c.checkNodes(ctx, spec.Nodes); err != nil {
+		return "", err
+	}
+	args := spec.args()
+	if name := strings.TrimSpace(spec.Name); name != "" {
+		args = append([]string{"ReservationName=" + name}, args...)
+	}
+	cmd := c.execCommand(ctx, "scontrol", append([]string{"create", "reservation"}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		c.logger.Error("unable to create reservation", "output", string(out), "cmd", cmd.String(), "err", err)
+		return "", fmt.Errorf("failed to exec %s: %s", cmd.String(), strings.TrimSpace(string(out)))
+	}
+	// 输出形如 "Reservation created: maint_1"
+	name := spec.Name
+	if _, created, ok := strings.Cut(strings.TrimSpace(string(out)), "Reservation created:"); ok {
+		name = strings.TrimSpace(created)
+	}
+	c.logger.Info("reservation created", "name", name, "args", args, "operator", operator)
+	return name, nil
+}
+
+// UpdateReservation 修改预约. 调用前应先执行 ValidateUpdate. 节点、用户与账户的检查同 CreateReservation.
+func (c *Client) UpdateReservation(ctx context.Context, spec ReservationSpec, operator string) error {
+	if err := spec.ValidateUpdate(); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidArgument, err)
+	}
+	if err := c.checkNodes(ctx, spec.Nodes); err != nil {
+		return err
+	}
+	args := append([]string{"ReservationName=" + strings.TrimSpace(spec.Name)}, spec.args()...)
+	cmd := c.execCommand(ctx, "scontrol", append([]string{"update"}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		c.logger.Error("unable to update reservation", "output", string(out), "cmd", cmd.String(), "err", err)
+		return fmt.Errorf("failed to exec %s: %s", cmd.String(), strings.TrimSpace(string(out)))
+	}
+	c.logger.Info("reservation updated", "name", spec.Name, "args", args, "operator", operator)
+	return nil
+}
+
+// DeleteReservation 删除预约. 名称不合法时返回 ErrInvalidArgument, 预约不存在时返回 ErrNotFound.
+func (c *Client) DeleteReservation(ctx context.Context, name, operator string) error {
+	name = strings.TrimSpace(name)
+	if !reservationNameRe.MatchString(name) {
+		return fmt.Errorf("%w: invalid reservation name: %q", ErrInvalidArgument, name)
+	}
+	cmd := c.execCommand(ctx, "scontrol", "delete", "ReservationName="+name)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		// 预约不存在时 scontrol 报告 "Requested reservation is invalid" 或 "not found"
+		if msg := string(out); strings.Contains(msg, "not found") || strings.Contains(msg, "Requested reservation is invalid") {
+			return fmt.Errorf("%w: reservation %s", ErrNotFound, name)
+		}
+		c.logger.Error("unable to delete reservation", "output", string(out), "cmd", cmd.String(), "err", err)
+		return fmt.Errorf("failed to exec %s: %s", cmd.String(), strings.TrimSpace(string(out)))
+	}
+	c.logger.Info("reservation deleted", "name", name, "operator", operator)
+	return nil
+}
+
+// parseReservations 解析 scontrol show reservation 的输出, 无预约时输出 "No reservations in the system".
+func parseReservations(content string) models.Reservations {
+	resvs := make(models.Reservations, 0)
+	for _, raw := range parseRecords(content, "ReservationName") {
+		if raw["ReservationName"] == "" {
+			continue
+		}
+		resvs = append(resvs, newReservation(raw))
+	}
+	return resvs
+}
+
+// newReservation 将 scontrol 原始字段转换为预约模型.
+func newReservation(raw map[string]string) models.Reservation {
+	r := models.Reservation{
+		Name:      raw["ReservationName"],
+		State:     raw["State"],
+		StartTime: raw["StartTime"],
+		EndTime:   raw["EndTime"],
+		Nodelist:  noneToEmpty(raw["Nodes"]),
+		NodeCount: parseCount(raw["NodeCnt"]),
+		CoreCount: parseCount(raw["CoreCnt"]),
+		Partition: noneToEmpty(raw["PartitionName"]),
+		Features:  noneToEmpty(raw["Features"]),
+		Users:     splitList(raw["Users"]),
+		Accounts:  splitList(raw["Accounts"]),
+		Groups:    splitList(raw["Groups"]),
+		Flags:     splitList(raw["Flags"]),
+		TRES:      parseKeyValues(raw["TRES"]),
+		Licenses:  noneToEmpty(raw["Licenses"]),
+		Raw:       raw,
+	}
+	r.Duration, _ = ParseDuration(raw["Duration"])
+	if nodes, err := hostlist.Expand(r.Nodelist); err == nil {
+		r.Nodes = nodes
+	} else {
+		r.Nodes = []string{}
+	}
+	return r
+}
@@ -4,10 +4,15 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"os"
 	"os/exec"
+	"regexp"
 	"solid/internal/pkg/client/slurmctl/models"
+	"solid/internal/pkg/common/hostlist"
+	jobidpkg "solid/internal/pkg/common/jobid"
 	"strconv"
 	"strings"
 )
@@ -21,9 +26,64 @@ func SetDefault(c *Client) { defaultClient = c }
 // Default returns the package-level default SlurmDB Client.
 func Default() *Client { return defaultClient }
 
+// clusterClients 各集群的 slurmctld 客户端, 键为集群名称.
+var clusterClients = map[string]*Client{}
+
+// SetCluster 注册集群 name 的客户端, 在启动时调用.
+func SetCluster(name string, c *Client) { clusterClients[name] = c }
+
+// ForCluster 返回集群 name 的客户端, name 为空时返回默认客户端, 未注册的集群返回 nil.
+func ForCluster(name string) *Client {
+	if name == "" {
+		return defaultClient
+	}
+	return clusterClients[name]
+}
+
 // ExecCommandFunc 定义 exec.CommandContext 的函数签名，方便 mock 测试.
 type ExecCommandFunc func(ctx context.Context, name string, args ...string) *exec.Cmd
 
+// WithEnv 返回在额外环境变量下执行命令的 ExecCommandFunc, 如以 SLURM_CONF 指向另一集群的配置.
+func WithEnv(execCommand ExecCommandFunc, env ...string) ExecCommandFunc {
+	return func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		cmd := execCommand(ctx, name, args...)
+		cmd.Env = append(os.Environ(), env...)
+		return cmd
+	}
+}
+
+// ErrInvalidArgument 参数校验失败, 命令不会被执行.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// ErrNotFound 操作的对象不存在.
+var ErrNotFound = errors.New("not found")
+
+// nodeNameRe 展开后的节点名称.
+var nodeNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// validateNodeArgs 校验节点管理命令的公共参数: nodes 须为合法的 hostlist 表达式, reason 与 operator 必填.
+func validateNodeArgs(nodes, reason, operator string) error {
+	if nodes == "" {
+		return fmt.Errorf("%w: node name is required", ErrInvalidArgument)
+	}
+	hosts, err := hostlist.Expand(nodes)
+	if err != nil {
+		return fmt.Errorf("%w: invalid nodes: %s", ErrInvalidArgument, err)
+	}
+	for _, h := range hosts {
+		if !nodeNameRe.MatchString(h) {
+			return fmt.Errorf("%w: invalid node name: %q", ErrInvalidArgument, h)
+		}
+	}
+	if strings.TrimSpace(reason) == "" {
+		return fmt.Errorf("%w: reason is required", ErrInvalidArgument)
+	}
+	if strings.TrimSpace(operator) == "" {
+		return fmt.Errorf("%w: operator is required", ErrInvalidArgument)
+	}
+	return nil
+}
+
 // Client 提供使用命令与 slurmctld 交互的功能.
 type Client struct {
 	execCommand ExecCommandFunc
@@ -86,67 +146,265 @@ func (sc *Client) GetNodes(ctx context.Context, condPartition string) (models.No
 	return nodes, nil
 }
 
-// GetJobs 获取调度队列中作业信息.
-// squeue -o "%i %t %u %a %C %N %P %q %r"
-// JOBID ST USER ACCOUNT CPUS NODELIST PARTITION QOS REASON
-func (sc *Client) GetJobs(ctx context.Context) (models.Jobs, error) {
+// 节点管理支持的目标状态.
+const (
+	NodeStateDrain   = "DRAIN"
+	NodeStateResume  = "RESUME"
+	NodeStateDown    = "DOWN"
+	NodeStateUndrain = "UNDRAIN"
+)
+
+// UpdateNodeState 修改节点状态, 该函数通过执行 scontrol update nodename=<nodes> state=<state> reason=<reason> 实现.
+// nodes 可以是单个节点名称, 也可以是 hostlist 表达式(如 cn[1-10]). reason 必填, 会附加操作人 operator 一并记录,
+// 对于 DRAIN/DOWN 状态 reason 由 slurmctld 保存在节点上, 对于 RESUME/UNDRAIN 状态 reason 仅记录在日志中.
+// 参数不合法时返回 ErrInvalidArgument.
+func (c *Client) UpdateNodeState(ctx context.Context, nodes, state, reason, operator string) error {
+	nodes = strings.TrimSpace(nodes)
+	if err := validateNodeArgs(nodes, reason, operator); err != nil {
+		return err
+	}
+
+	state = strings.ToUpper(strings.TrimSpace(state))
+	args := []string{"update", "nodename=" + nodes, "state=" + state}
+	switch state {
+	case NodeStateDrain, NodeStateDown:
+		args = append(args, "reason="+formatNodeReason(reason, operator))
+	case NodeStateResume, NodeStateUndrain:
+	default:
+		return fmt.Errorf("%w: unsupported node state: %s", ErrInvalidArgument, state)
+	}
+
+	cmd := c.execCommand(ctx, "scontrol", args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		c.logger.Error("unable to update node state", "output", string(out), "cmd", cmd.String(), "err", err)
+		return fmt.Errorf("failed to exec %s: %s", cmd.String(), strings.TrimSpace(string(out)))
+	}
+	c.logger.Info("node state updated", "nodes", nodes, "state", state, "reason", reason, "operator", operator)
+
+	return nil
+}
+
+// RebootNodes 重启节点, 该函数通过执行 scontrol reboot [ASAP] [nextstate=<state>] reason=<reason> <nodes> 实现.
+// asap 为 true 时节点会被置为 DRAIN 以尽快重启, nextState 为重启完成后节点的状态(RESUME 或 DOWN), 可为空.
+func (c *Client) RebootNodes(ctx context.Context, nodes string, asap bool, nextState, reason, operator string) error {
+	nodes = strings.TrimSpace(nodes)
+	if err := validateNodeArgs(nodes, reason, operator); err != nil {
+		return err
+	}
+
+	args := []string{"reboot"}
+	if asap {
+		args = append(args, "ASAP")
+	}
+	nextState = strings.ToUpper(strings.TrimSpace(nextState))
+	switch nextState {
+	case "":
+	case NodeStateResume, NodeStateDown:
+		args = append(args, "nextstate="+nextState)
+	default:
+		return fmt.Errorf("%w: unsupported next state: %s", ErrInvalidArgument, nextState)
+	}
+	args = append(args, "reason="+formatNodeReason(reason, operator), nodes)
+
+	cmd := c.execCommand(ctx, "scontrol", args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		c.logger.Error("unable to reboot nodes", "output", string(out), "cmd", cmd.String(), "err", err)
+		return fmt.Errorf("failed to exec %s: %s", cmd.String(), strings.TrimSpace(string(out)))
+	}
+	c.logger.Info("node reboot requested", "nodes", nodes, "asap", asap, "nextstate", nextState, "reason", reason, "operator", operator)
+
+	return nil
+}
+
+// formatNodeReason 将操作人附加到 reason 中, 例如 "disk failure [by alice]".
+func formatNodeReason(reason, operator string) string {
+	return fmt.Sprintf("%s [by %s]", strings.TrimSpace(reason), strings.TrimSpace(operator))
+}
+
+// squeueJobFormat squeue 作业输出格式, 作业名称放在最后以容忍名称中的分隔符.
+// JOBID ST USER ACCOUNT CPUS NODELIST PARTITION QOS REASON PRIORITY SUBMIT_TIME NAME
+const (
+	squeueJobFormat = "%i|%t|%u|%a|%C|%N|%P|%q|%r|%Q|%V|%j"
+	squeueJobFields = 12
+)
+
+// JobsFilter squeue 作业查询条件, 零值表示不过滤. 除 Reason 外均下推为 squeue 参数,
+// 多个取值以逗号分隔.
+type JobsFilter struct {
+	User      string // --user
+	Account   string // --account
+	Partition string // --partition
+	State     string // --states
+	QoS       string // --qos
+	Node      string // --nodelist, 支持 hostlist 表达式
+	Name      string // --name
+	Reason    string // squeue 不支持按原因过滤, 在内存中匹配(忽略大小写)
+	Sort      string // 排序字段: priority, submit_time, jobid; 前缀 "-" 表示降序
+}
+
+// jobSortKeys 排序字段与 squeue --sort 字段的对应关系.
+var jobSortKeys = map[string]string{
+	"priority":    "p",
+	"submit_time": "V",
+	"jobid":       "i",
+}
+
+// Validate 校验过滤条件, 当前校验作业状态与排序字段.
+func (f JobsFilter) Validate() error {
+	_, err := f.args()
+	return err
+}
+
+// args 将过滤条件转换为 squeue 参数. 采用 --key=value 形式避免取值被解析为选项.
+func (f JobsFilter) args() ([]string, error) {
+	if v := strings.TrimSpace(f.State); v != "" {
+		for _, s := range strings.Split(v, ",") {
+			if !validJobState(s) {
+				return nil, fmt.Errorf("unsupported job state: %s", strings.TrimSpace(s))
+			}
+		}
+	}
+	args := make([]string, 0)
+	for _, kv := range []struct{ flag, val string }{
+		{"--user", f.User},
+		{"--account", f.Account},
+		{"--partition", f.Partition},
+		{"--states", f.State},
+		{"--qos", f.QoS},
+		{"--nodelist", f.Node},
+		{"--name", f.Name},
+	} {
+		if v := strings.TrimSpace(kv.val); v != "" {
+			args = append(args, kv.flag+"="+v)
+		}
+	}
+	if sortBy := strings.TrimSpace(f.Sort); sortBy != "" {
+		desc := strings.HasPrefix(sortBy, "-")
+		key, ok := jobSortKeys[strings.TrimPrefix(sortBy, "-")]
+		if !ok {
+			return nil, fmt.Errorf("unsupported sort field: %s", sortBy)
+		}
+		if desc {
+			key = "-" + key
+		}
+		args = append(args, "--sort="+key)
+	}
+	return args, nil
+}
+
+// GetJobs 获取调度队列中满足 filter 的作业信息.
+// squeue -h -o "%i|%t|%u|%a|%C|%N|%P|%q|%r|%Q|%V|%j" [filter...]
+func (sc *Client) GetJobs(ctx context.Context, filter JobsFilter) (models.Jobs, error) {
 	jobs := make(models.Jobs, 0)
-	cmd := sc.execCommand(ctx, "squeue", "-h", "-o", "%i|%t|%u|%a|%C|%N|%P|%q|%r")
+	fargs, err := filter.args()
+	if err != nil {
+		return nil, err
+	}
+	args := append([]string{"-h", "-o", squeueJobFormat}, fargs...)
+	cmd := sc.execCommand(ctx, "squeue", args...)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		sc.logger.Error("unable to get all jobs in scheduling queue", "output", string(out), "cmd", cmd.String(), "err", err)
 		return nil, fmt.Errorf("failed to exec squeue command")
 	}
+	reason := strings.TrimSpace(filter.Reason)
 	scanner := bufio.NewScanner(bytes.NewReader(out))
 	for scanner.Scan() {
 		line := scanner.Text()
-		fields := strings.Split(line, "|")
-		if len(fields) != 9 {
+		job, ok := parseJobLine(line)
+		if !ok {
 			sc.logger.Warn("invalid squeue output line, skip", "line", line)
 			continue
 		}
-		jobs = append(jobs, models.Job{
-			Jobid:     fields[0],
-			State:     fields[1],
-			User:      fields[2],
-			Account:   fields[3],
-			CPUs:      fields[4],
-			Nodelist:  fields[5],
-			Partition: fields[6],
-			QoS:       fields[7],
-			Reason:    fields[8],
-		})
+		if reason != "" && !strings.EqualFold(job.Reason, reason) {
+			continue
+		}
+		jobs = append(jobs, job)
 	}
 
 	return jobs, nil
 }
 
+// parseJobLine 解析一行 squeueJobFormat 格式的输出.
+func parseJobLine(line string) (models.Job, bool) {
+	fields := strings.SplitN(line, "|", squeueJobFields)
+	if len(fields) != squeueJobFields {
+		return models.Job{}, false
+	}
+	priority, _ := strconv.ParseInt(fields[9], 10, 64)
+	return models.Job{
+		Jobid:      fields[0],
+		State:      fields[1],
+		User:       fields[2],
+		Account:    fields[3],
+		CPUs:       fields[4],
+		Nodelist:   fields[5],
+		Partition:  fields[6],
+		QoS:        fields[7],
+		Reason:     fields[8],
+		Priority:   priority,
+		SubmitTime: fields[10],
+		Name:       fields[11],
+	}, true
+}
+
 func (c *Client) GetJob(ctx context.Context, jobid string) (*models.Job, error) {
-	cmd := c.execCommand(ctx, "squeue", "-h", "-j", jobid, "-o", "%i|%t|%u|%a|%C|%N|%P|%q|%r")
+	id, err := jobidpkg.Parse(jobid)
+	if err != nil {
+		return nil, err
+	}
+	cmd := c.execCommand(ctx, "squeue", "-h", "-j", id.String(), "-o", squeueJobFormat)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		c.logger.Error("unable to get job in scheduling queue", "output", string(out), "cmd", cmd.String(), "err", err)
 		return nil, fmt.Errorf("unable to get job in scheduling queue")
 	}
 
-	fields := strings.Split(strings.TrimSpace(string(out)), "|")
-	if len(fields) != 9 {
+	// 数组作业 ID 会返回所有任务, 只取第一行(数组作业本身或仍在排队的任务)
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	job, ok := parseJobLine(line)
+	if !ok {
 		c.logger.Warn("invalid squeue output line, skip", "line", string(out))
 		return nil, fmt.Errorf("invalid squeue output line, skip")
 	}
-	job := &models.Job{
-		Jobid:     fields[0],
-		State:     fields[1],
-		User:      fields[2],
-		Account:   fields[3],
-		CPUs:      fields[4],
-		Nodelist:  fields[5],
-		Partition: fields[6],
-		QoS:       fields[7],
-		Reason:    fields[8],
+
+	return &job, nil
+}
+
+// GetArrayTasks 通过 squeue -r 获取数组作业在队列中的所有任务, 每个任务一条记录.
+// 已结束并离开队列的任务不会返回.
+func (c *Client) GetArrayTasks(ctx context.Context, arrayJobID uint32) (models.Jobs, error) {
+	cmd := c.execCommand(ctx, "squeue", "-h", "-r", "-j", strconv.FormatUint(uint64(arrayJobID), 10), "-o", squeueJobFormat)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		c.logger.Error("unable to get array tasks in scheduling queue", "output", string(out), "cmd", cmd.String(), "err", err)
+		return nil, fmt.Errorf("unable to get array tasks in scheduling queue")
 	}
 
-	return job, nil
+	tasks := make(models.Jobs, 0)
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		job, ok := parseJobLine(line)
+		if !ok {
+			c.logger.Warn("invalid squeue output line, skip", "line", line)
+			continue
+		}
+		tasks = append(tasks, job)
+	}
+	return tasks, nil
+}
+
+// SummarizeArray 按状态统计数组作业的任务数量.
+func SummarizeArray(arrayJobID uint32, tasks models.Jobs) models.ArraySummary {
+	sum := models.ArraySummary{ArrayJobID: arrayJobID, Tasks: len(tasks), States: make(map[string]int)}
+	for _, t := range tasks {
+		sum.States[t.State]++
+	}
+	return sum
 }
 
 func (c *Client) GetStepsOfJob(ctx context.Context, jobid string) (models.Steps, error) {
@@ -155,7 +413,7 @@ func (c *Client) GetStepsOfJob(ctx context.Context, jobid string) (models.Steps,
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		c.logger.Error("unable to execute command", "output", string(out), "cmd", cmd.String(), "err", err)
-		return nil, fmt.Errorf("failed to exec sinfo command")
+		return nil, fmt.Errorf("failed to exec squeue command")
 	}
 
 	scanner := bufio.NewScanner(bytes.NewReader(out))
@@ -176,8 +434,23 @@ func (c *Client) GetStepsOfJob(ctx context.Context, jobid string) (models.Steps,
 	return steps, nil
 }
 
-// GetPartitions 获取分区详情.
+// GetPartitions 获取分区详情, 并从 sinfo 中汇总各分区的节点状态数量.
 func (c *Client) GetPartitions(ctx context.Context) (models.Partitions, error) {
+	parts, err := c.showPartitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := c.GetNodes(ctx, "")
+	if err != nil {
+		c.logger.Warn("unable to join node states into partitions", "err", err)
+		return parts, nil
+	}
+	JoinNodeStates(parts, nodes)
+	return parts, nil
+}
+
+// showPartitions 执行 scontrol show partition 获取所有分区, 不包含节点状态统计.
+func (c *Client) showPartitions(ctx context.Context) (models.Partitions, error) {
 	// 获取所有分区
 	cmd := c.execCommand(ctx, "scontrol", "show", "partition")
 	out, err := cmd.CombinedOutput()
@@ -189,7 +462,7 @@ func (c *Client) GetPartitions(ctx context.Context) (models.Partitions, error) {
 	return parsePartitions(string(out)), nil
 }
 
-func (c *Client) GetPartition(ctx context.Context, name string) (models.Partition, error) {
+func (c *Client) GetPartition(ctx context.Context, name string) (*models.Partition, error) {
 	cmd := c.execCommand(ctx, "scontrol", "show", "partition", name)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
@@ -198,72 +471,248 @@ func (c *Client) GetPartition(ctx context.Context, name string) (models.Partitio
 		return nil, fmt.Errorf("failed to exec %s", cmd.String())
 	}
 
-	return parsePartition(string(out)), nil
+	part := parsePartition(string(out))
+	nodes, err := c.GetNodes(ctx, name)
+	if err != nil {
+		c.logger.Warn("unable to join node states into partition", "partition", name, "err", err)
+		return &part, nil
+	}
+	parts := models.Partitions{part}
+	JoinNodeStates(parts, nodes)
+	return &parts[0], nil
+}
+
+// JoinNodeStates 按 sinfo 节点状态汇总每个分区中各状态的节点数量.
+func JoinNodeStates(parts models.Partitions, nodes models.Nodes) {
+	index := make(map[string]int, len(parts))
+	for i := range parts {
+		parts[i].NodeStates = make(map[string]int)
+		index[parts[i].Name] = i
+	}
+	for _, n := range nodes {
+		for _, p := range n.Partition {
+			if i, ok := index[strings.TrimSuffix(p, "*")]; ok {
+				parts[i].NodeStates[n.State]++
+			}
+		}
+	}
 }
 
-// parseParttion 解析 scontrol show partition 的输出为一个或多个 partition 字段映射。
+// parseParttion 解析 scontrol show partition 的输出为一个或多个分区。
 // 输入可包含多个分区，分区之间通常以空行分隔；每行可能包含多个以空格分隔的 key=value 对。
-// 返回按出现顺序的分区切片，每个分区以 map[string]string 表示。
+// 返回按出现顺序的分区切片。
 func parsePartitions(content string) models.Partitions {
 	parts := make(models.Partitions, 0)
-	current := make(models.Partition)
+	for _, raw := range parseRecords(content, "PartitionName") {
+		parts = append(parts, newPartition(raw))
+	}
+	return parts
+}
+
+func parsePartition(content string) models.Partition {
+	current := make(map[string]string)
 
 	scanner := bufio.NewScanner(strings.NewReader(content))
 	for scanner.Scan() {
 		line := scanner.Text()
 		trimmed := strings.TrimSpace(line)
 
-		// 空行表示一个分区的结束
-		if trimmed == "" {
-			if len(current) > 0 {
-				parts = append(parts, current)
-				current = make(models.Partition)
-			}
-			continue
-		}
-
 		// 一行可能有多个 key=value，以空白分隔
 		tokens := strings.Fields(trimmed)
 		for _, tok := range tokens {
 			if eq := strings.IndexByte(tok, '='); eq >= 0 {
 				key := tok[:eq]
 				val := tok[eq+1:]
-				// 若遇到新的 PartitionName 且当前分区已存在 PartitionName，则视为新分区开始
-				if key == "PartitionName" && len(current) > 0 && current["PartitionName"] != "" {
-					parts = append(parts, current)
-					current = make(map[string]string)
-				}
 				current[key] = val
 			}
 		}
 	}
 
-	// 文件结尾若仍有未提交的分区
-	if len(current) > 0 {
-		parts = append(parts, current)
-	}
-
-	return parts
+	return newPartition(current)
 }
 
-func parsePartition(content string) models.Partition {
-	current := make(models.Partition)
+// parseRecords 解析 scontrol show 风格的输出为多条 key=value 记录. 记录之间以空行分隔,
+// 或以 firstKey 字段的再次出现作为新记录的开始.
+func parseRecords(content, firstKey string) []map[string]string {
+	records := make([]map[string]string, 0)
+	current := make(map[string]string)
 
 	scanner := bufio.NewScanner(strings.NewReader(content))
 	for scanner.Scan() {
 		line := scanner.Text()
 		trimmed := strings.TrimSpace(line)
 
+		// 空行表示一条记录的结束
+		if trimmed == "" {
+			if len(current) > 0 {
+				records = append(records, current)
+				current = make(map[string]string)
+			}
+			continue
+		}
+
 		// 一行可能有多个 key=value，以空白分隔
 		tokens := strings.Fields(trimmed)
 		for _, tok := range tokens {
 			if eq := strings.IndexByte(tok, '='); eq >= 0 {
 				key := tok[:eq]
 				val := tok[eq+1:]
+				// 若遇到新的 firstKey 且当前记录已存在 firstKey，则视为新记录开始
+				if key == firstKey && len(current) > 0 && current[firstKey] != "" {
+					records = append(records, current)
+					current = make(map[string]string)
+				}
 				current[key] = val
 			}
 		}
 	}
 
-	return current
+	// 结尾若仍有未提交的记录
+	if len(current) > 0 {
+		records = append(records, current)
+	}
+
+	return records
+}
+
+// newPartition 将 scontrol 原始字段转换为分区模型.
+func newPartition(raw map[string]string) models.Partition {
+	p := models.Partition{
+		Name:              raw["PartitionName"],
+		State:             raw["State"],
+		Default:           raw["Default"] == "YES",
+		Hidden:            raw["Hidden"] == "YES",
+		RootOnly:          raw["RootOnly"] == "YES",
+		ReqResv:           raw["ReqResv"] == "YES",
+		ExclusiveUser:     raw["ExclusiveUser"] == "YES",
+		DisableRootJobs:   raw["DisableRootJobs"] == "YES",
+		LLN:               raw["LLN"] == "YES",
+		OverSubscribe:     raw["OverSubscribe"],
+		PreemptMode:       raw["PreemptMode"],
+		QoS:               noneToEmpty(raw["QoS"]),
+		PriorityTier:      parseCount(raw["PriorityTier"]),
+		PriorityJobFactor: parseCount(raw["PriorityJobFactor"]),
+		MinNodes:          parseCount(raw["MinNodes"]),
+		MaxNodes:          parseCount(raw["MaxNodes"]),
+		MaxCPUsPerNode:    parseCount(raw["MaxCPUsPerNode"]),
+		TotalCPUs:         parseCount(raw["TotalCPUs"]),
+		TotalNodes:        parseCount(raw["TotalNodes"]),
+		AllowGroups:       splitList(raw["AllowGroups"]),
+		AllowAccounts:     splitList(raw["AllowAccounts"]),
+		AllowQos:          splitList(raw["AllowQos"]),
+		DenyAccounts:      splitList(raw["DenyAccounts"]),
+		DenyQos:           splitList(raw["DenyQos"]),
+		Nodelist:          noneToEmpty(raw["Nodes"]),
+		TRES:              parseKeyValues(raw["TRES"]),
+		TRESBillingWeight: parseKeyValues(raw["TRESBillingWeights"]),
+		Raw:               raw,
+	}
+	p.MaxTime, _ = ParseDuration(raw["MaxTime"])
+	p.DefaultTime, _ = ParseDuration(raw["DefaultTime"])
+	if nodes, err := hostlist.Expand(p.Nodelist); err == nil {
+		p.Nodes = nodes
+	} else {
+		p.Nodes = []string{}
+	}
+	return p
+}
+
+// ParseDuration 解析 Slurm 时长格式, 返回秒数. 支持 minutes, minutes:seconds, hours:minutes:seconds,
+// days-hours, days-hours:minutes, days-hours:minutes:seconds; UNLIMITED/INFINITE 返回 -1, 空值与 NONE 返回 0.
+func ParseDuration(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	switch strings.ToUpper(s) {
+	case "", "NONE", "N/A":
+		return 0, nil
+	case "UNLIMITED", "INFINITE":
+		return -1, nil
+	}
+
+	var days int64
+	rest := s
+	hasDays := false
+	if d, r, ok := strings.Cut(s, "-"); ok {
+		v, err := strconv.ParseInt(d, 10, 64)
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		days, rest, hasDays = v, r, true
+	}
+
+	parts := strings.Split(rest, ":")
+	vals := make([]int64, len(parts))
+	for i, p := range parts {
+		v, err := strconv.ParseInt(p, 10, 64)
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		vals[i] = v
+	}
+
+	var secs int64
+	switch {
+	case hasDays && len(vals) == 1: // days-hours
+		secs = vals[0] * 3600
+	case hasDays && len(vals) == 2: // days-hours:minutes
+		secs = vals[0]*3600 + vals[1]*60
+	case len(vals) == 3: // [days-]hours:minutes:seconds
+		secs = vals[0]*3600 + vals[1]*60 + vals[2]
+	case !hasDays && len(vals) == 1: // minutes
+		secs = vals[0] * 60
+	case !hasDays && len(vals) == 2: // minutes:seconds
+		secs = vals[0]*60 + vals[1]
+	default:
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return days*86400 + secs, nil
+}
+
+// FormatDuration 将秒数格式化为 Slurm 时长格式 [days-]hours:minutes:seconds, 负数表示 UNLIMITED.
+func FormatDuration(secs int64) string {
+	if secs < 0 {
+		return "UNLIMITED"
+	}
+	d, h, m, s := secs/86400, secs%86400/3600, secs%3600/60, secs%60
+	if d > 0 {
+		return fmt.Sprintf("%d-%02d:%02d:%02d", d, h, m, s)
+	}
+	return fmt.Sprintf("%02d:%02d:%02d", h, m, s)
+}
+
+// parseCount 解析数量字段, UNLIMITED 返回 -1, 无法解析返回 0.
+func parseCount(s string) int {
+	if strings.EqualFold(s, "UNLIMITED") {
+		return -1
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// splitList 将逗号分隔的字段拆分为列表, 空值与 (null) 返回空列表.
+func splitList(s string) []string {
+	s = noneToEmpty(s)
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, ",")
+}
+
+// parseKeyValues 解析形如 cpu=8,mem=16G 的字段.
+func parseKeyValues(s string) map[string]string {
+	out := make(map[string]string)
+	for _, item := range splitList(s) {
+		if k, v, ok := strings.Cut(item, "="); ok {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// noneToEmpty 将 scontrol 中表示空值的 (null)/N/A 转换为空字符串.
+func noneToEmpty(s string) string {
+	switch s {
+	case "(null)", "N/A":
+		return ""
+	}
+	return s
 }
@@ -1,7 +1,13 @@
 package slurmctl
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"solid/internal/pkg/client/slurmctl/models"
 	"strings"
 	"testing"
 )
@@ -13,3 +19,531 @@ func TestA(t *testing.T) {
 
 	// fmt.Printf("%s\n", a)
 }
+
+func TestJobsFilterArgs(t *testing.T) {
+	f := JobsFilter{User: "alice", State: "PD,R", Node: "cn[1-2]", Reason: "Priority", Sort: "-priority"}
+	args, err := f.args()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"--user=alice", "--states=PD,R", "--nodelist=cn[1-2]", "--sort=-p"}
+	if strings.Join(args, " ") != strings.Join(want, " ") {
+		t.Errorf("args() = %v, want %v", args, want)
+	}
+	if err := (JobsFilter{Sort: "name"}).Validate(); err == nil {
+		t.Error("Validate() expected error for unsupported sort field")
+	}
+	if err := (JobsFilter{State: "running,all,CD"}).Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	if err := (JobsFilter{State: "R,DONE"}).Validate(); err == nil {
+		t.Error("Validate() expected error for unsupported state")
+	}
+}
+
+func TestParseJobLine(t *testing.T) {
+	job, ok := parseJobLine("376352|R|hjxue04|hjxue|828|cn[1856-1860,1869]|cp2|normal|None|4294|2025-01-02T03:04:05|a|b")
+	if !ok {
+		t.Fatal("parseJobLine() failed")
+	}
+	if job.Jobid != "376352" || job.Priority != 4294 || job.Name != "a|b" {
+		t.Errorf("parseJobLine() = %+v", job)
+	}
+}
+
+func TestFilterJobs(t *testing.T) {
+	jobs := models.Jobs{
+		{Jobid: "3", State: "PD", User: "alice", Partition: "cpu,gpu", Priority: 10},
+		{Jobid: "1", State: "R", User: "alice", Partition: "cpu", Nodelist: "cn[1-4]", Priority: 30},
+		{Jobid: "2", State: "R", User: "bob", Partition: "gpu", Nodelist: "gpu1", Priority: 20},
+	}
+	got := FilterJobs(jobs, JobsFilter{User: "alice", State: "RUNNING,PD", Sort: "-priority"})
+	if len(got) != 2 || got[0].Jobid != "1" || got[1].Jobid != "3" {
+		t.Errorf("FilterJobs() = %+v", got)
+	}
+	got = FilterJobs(jobs, JobsFilter{Partition: "gpu", Sort: "jobid"})
+	if len(got) != 2 || got[0].Jobid != "2" || got[1].Jobid != "3" {
+		t.Errorf("FilterJobs() = %+v", got)
+	}
+	got = FilterJobs(jobs, JobsFilter{Node: "cn3"})
+	if len(got) != 1 || got[0].Jobid != "1" {
+		t.Errorf("FilterJobs() = %+v", got)
+	}
+
+	// 未指定状态时与 squeue 一致, 不返回已结束的作业
+	jobs = append(jobs, models.Job{Jobid: "4", State: "CD", User: "alice", Partition: "cpu"})
+	got = FilterJobs(jobs, JobsFilter{User: "alice"})
+	if len(got) != 2 {
+		t.Errorf("FilterJobs() = %+v", got)
+	}
+	got = FilterJobs(jobs, JobsFilter{User: "alice", State: "all"})
+	if len(got) != 3 {
+		t.Errorf("FilterJobs(all) = %+v", got)
+	}
+	got = FilterJobs(jobs, JobsFilter{State: "completed"})
+	if len(got) != 1 || got[0].Jobid != "4" {
+		t.Errorf("FilterJobs(completed) = %+v", got)
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	prev := &Snapshot{
+		Jobs: models.Jobs{{Jobid: "1", State: "PD"}, {Jobid: "2", State: "R"}, {Jobid: "3", State: "F"},
+			{Jobid: "5", State: "R"}, {Jobid: "6", State: "CG"}, {Jobid: "7", State: "R"}},
+		Nodes: models.Nodes{"cn1": {Name: "cn1", State: "idle"}, "cn2": {Name: "cn2", State: "drain"}},
+	}
+	next := &Snapshot{
+		Jobs: models.Jobs{{Jobid: "1", State: "R"}, {Jobid: "4", State: "PD"},
+			{Jobid: "5", State: "TO"}, {Jobid: "6", State: "CA"}, {Jobid: "7", State: "CD"}},
+		Nodes: models.Nodes{"cn1": {Name: "cn1", State: "drng"}, "cn2": {Name: "cn2", State: "idle"}},
+	}
+	got := make(map[string]string)
+	for _, e := range DiffSnapshots(prev, next) {
+		switch {
+		case e.Job != nil:
+			got[e.Job.Jobid] = e.Type
+		case e.Node != nil:
+			got[e.Node.Name] = e.Type
+		}
+	}
+	want := map[string]string{
+		"1":   EventJobStarted,
+		"2":   EventJobCompleted, // 两次快照之间结束并被清除
+		"4":   EventJobSubmitted,
+		"5":   EventJobFailed,
+		"6":   EventJobCancelled,
+		"7":   EventJobCompleted,
+		"cn1": EventNodeDrained,
+		"cn2": EventNodeResumed,
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("DiffSnapshots() = %v, want %v", got, want)
+	}
+}
+
+func TestEventHubReplay(t *testing.T) {
+	hub := NewEventHub(3)
+	first := hub.firstID
+	hub.Publish([]Event{{Type: EventJobSubmitted}, {Type: EventJobStarted}, {Type: EventJobCompleted}})
+	replay, _, cancel := hub.Subscribe(first)
+	defer cancel()
+	if len(replay) != 2 || replay[0].ID != first+1 || replay[1].ID != first+2 {
+		t.Errorf("Subscribe(first) replay = %+v", replay)
+	}
+
+	// 未携带 Last-Event-ID 时只接收后续事件
+	replay, _, cancel0 := hub.Subscribe(0)
+	cancel0()
+	if len(replay) != 0 {
+		t.Errorf("Subscribe(0) replay = %+v", replay)
+	}
+
+	// 重启前或未来的 ID 视为未知, 返回全部历史事件
+	for _, lastID := range []uint64{5, first - 1, first + 100} {
+		replay, _, cancel := hub.Subscribe(lastID)
+		cancel()
+		if len(replay) != 3 || replay[0].ID != first {
+			t.Errorf("Subscribe(%d) replay = %+v", lastID, replay)
+		}
+	}
+
+}
+
+func TestParseDuration(t *testing.T) {
+	cases := map[string]int64{
+		"UNLIMITED":  -1,
+		"NONE":       0,
+		"30":         1800,
+		"30:15":      1815,
+		"02:00:00":   7200,
+		"1-00:00:00": 86400,
+		"2-12":       216000,
+		"1-01:30":    91800,
+	}
+	for in, want := range cases {
+		got, err := ParseDuration(in)
+		if err != nil || got != want {
+			t.Errorf("ParseDuration(%q) = %d, %v, want %d", in, got, err, want)
+		}
+	}
+	if _, err := ParseDuration("1:2:3:4"); err == nil {
+		t.Errorf("ParseDuration(1:2:3:4) expected error")
+	}
+}
+
+func TestParsePartitions(t *testing.T) {
+	out := `PartitionName=cpu
+   AllowGroups=ALL AllowAccounts=acct1,acct2 AllowQos=ALL
+   Default=YES QoS=N/A
+   DefaultTime=01:00:00 Hidden=NO
+   MaxNodes=UNLIMITED MaxTime=7-00:00:00 MinNodes=0 LLN=NO MaxCPUsPerNode=UNLIMITED
+   Nodes=cn[01-03]
+   State=UP TotalCPUs=96 TotalNodes=3
+   TRES=cpu=96,mem=375G,node=3,billing=96
+
+PartitionName=gpu
+   Default=NO MaxTime=UNLIMITED Nodes=(null) State=DOWN
+`
+	parts := parsePartitions(out)
+	if len(parts) != 2 {
+		t.Fatalf("parsePartitions() returned %d partitions, want 2", len(parts))
+	}
+	p := parts[0]
+	if p.Name != "cpu" || !p.Default || p.MaxTime != 7*86400 || p.DefaultTime != 3600 ||
+		p.MaxNodes != -1 || p.TotalCPUs != 96 || p.QoS != "" || p.TRES["mem"] != "375G" {
+		t.Errorf("parsePartitions()[0] = %+v", p)
+	}
+	if fmt.Sprint(p.Nodes) != "[cn01 cn02 cn03]" || fmt.Sprint(p.AllowAccounts) != "[acct1 acct2]" {
+		t.Errorf("parsePartitions()[0] nodes = %v, accounts = %v", p.Nodes, p.AllowAccounts)
+	}
+	if g := parts[1]; g.MaxTime != -1 || len(g.Nodes) != 0 || g.State != "DOWN" {
+		t.Errorf("parsePartitions()[1] = %+v", g)
+	}
+
+	JoinNodeStates(parts, models.Nodes{
+		"cn01": {Name: "cn01", State: "idle", Partition: []string{"cpu*"}},
+		"cn02": {Name: "cn02", State: "alloc", Partition: []string{"cpu*"}},
+		"cn03": {Name: "cn03", State: "idle", Partition: []string{"cpu*", "gpu"}},
+	})
+	if fmt.Sprint(parts[0].NodeStates) != "map[alloc:1 idle:2]" || parts[1].NodeStates["idle"] != 1 {
+		t.Errorf("JoinNodeStates() = %v, %v", parts[0].NodeStates, parts[1].NodeStates)
+	}
+}
+
+func TestParseReservations(t *testing.T) {
+	out := `ReservationName=maint StartTime=2024-05-01T08:00:00 EndTime=2024-05-01T12:00:00 Duration=04:00:00
+   Nodes=cn[01-04] NodeCnt=4 CoreCnt=128 Features=(null) PartitionName=(null) Flags=MAINT,SPEC_NODES
+   TRES=cpu=128
+   Users=root Groups=(null) Accounts=(null) Licenses=(null) State=INACTIVE BurstBuffer=(null)
+   MaxStartDelay=(null)
+
+ReservationName=class StartTime=2024-05-02T09:00:00 EndTime=2024-05-02T11:00:00 Duration=02:00:00
+   Nodes=gpu1 NodeCnt=1 CoreCnt=32 Flags=IGNORE_JOBS Users=(null) Accounts=-bio,-chem State=ACTIVE
+`
+	resvs := parseReservations(out)
+	if len(resvs) != 2 {
+		t.Fatalf("parseReservations() returned %d reservations, want 2", len(resvs))
+	}
+	r := resvs[0]
+	if r.Name != "maint" || r.Duration != 4*3600 || len(r.Nodes) != 4 || r.Partition != "" ||
+		fmt.Sprint(r.Flags) != "[MAINT SPEC_NODES]" || fmt.Sprint(r.Users) != "[root]" || len(r.Accounts) != 0 {
+		t.Errorf("parseReservations()[0] = %+v", r)
+	}
+	if a := resvs[1].Accounts; fmt.Sprint(a) != "[-bio -chem]" {
+		t.Errorf("parseReservations()[1].Accounts = %v", a)
+	}
+	if got := parseReservations("No reservations in the system\n"); len(got) != 0 {
+		t.Errorf("parseReservations(empty) = %v", got)
+	}
+}
+
+func TestReservationSpecValidate(t *testing.T) {
+	ok := ReservationSpec{StartTime: "now+1hours", Duration: "1-00:00:00", Nodes: "cn[01-04]", Users: []string{"root"}, Flags: []string{"maint"}}
+	if err := ok.ValidateCreate(); err != nil {
+		t.Errorf("ValidateCreate() = %v", err)
+	}
+	bad := []ReservationSpec{
+		{Duration: "60", Nodes: "cn1", Users: []string{"root"}},                                             // 缺少开始时间
+		{StartTime: "tomorrow", Duration: "60", Nodes: "cn1", Users: []string{"root"}},                      // 无法识别的时间
+		{StartTime: "now", Duration: "60", Nodes: "cn[1-", Users: []string{"root"}},                         // 非法节点表达式
+		{StartTime: "now", Duration: "60", Nodes: "cn1", Users: []string{"root", "-bob"}},                   // 混用排除
+		{StartTime: "now", Duration: "60", Nodes: "cn1", Users: []string{"root"}, Flags: []string{"BOGUS"}}, // 未知标志
+		{StartTime: "now", Duration: "60", EndTime: "2024-05-01", Nodes: "cn1", Accounts: []string{"a b"}},  // 非法账户
+		{StartTime: "2024-05-01T08:00", Duration: "xx", Nodes: "cn1", Users: []string{"root"}},              // 非法时长
+		{StartTime: "2024-05-01T08:00", Duration: "60", NodeCount: 0, Users: []string{"root"}},              // 缺少节点
+	}
+	for i, s := range bad {
+		if err := s.ValidateCreate(); err == nil {
+			t.Errorf("ValidateCreate(bad[%d]) expected error", i)
+		}
+	}
+	if err := (ReservationSpec{Name: "maint"}).ValidateUpdate(); err == nil {
+		t.Errorf("ValidateUpdate() with no changes expected error")
+	}
+	if err := (ReservationSpec{Name: "maint", Flags: []string{"-MAINT"}}).ValidateUpdate(); err != nil {
+		t.Errorf("ValidateUpdate() = %v", err)
+	}
+}
+
+func TestParseSprio(t *testing.T) {
+	out := "1234|cpu|alice|acct1|normal|10523|0|1000|0|9000|500|0|23|0|cpu=0,mem=0|0.0000024500|0.0100000|0.0000000|0.9000000|0.0500000|0.0000000|0.0230000\n" +
+		"Unexpected line\n"
+	rows := parseSprio(out)
+	if len(rows) != 1 {
+		t.Fatalf("parseSprio() returned %d rows, want 1", len(rows))
+	}
+	r := rows[0]
+	if r.JobID != "1234" || r.Priority != 10523 || r.Weighted.FairShare != 9000 || r.Weighted.QoS != 23 ||
+		r.Normalized.FairShare != 0.9 || r.TRES["cpu"] != "0" {
+		t.Errorf("parseSprio()[0] = %+v", r)
+	}
+}
+
+func TestParseSshare(t *testing.T) {
+	out := `Account|User|RawShares|NormShares|RawUsage|NormUsage|EffectvUsage|FairShare|LevelFS|GrpTRESMins|TRESRunMins
+root|||0.000000|3000||1.000000||||cpu=0
+ root|root|1|0.333333|0|0.000000|0.000000|1.000000|inf||cpu=0
+ physics||1|0.333333|2000|0.666667|0.666667||0.500000||cpu=0
+  physics|alice|1|0.500000|2000|0.666667|1.000000|0.250000|0.500000||cpu=0
+  theory||1|0.500000|0|0.000000|0.000000||inf||cpu=0
+   theory|bob|1|1.000000|0|0.000000|0.000000|0.750000|inf||cpu=0
+ chem||1|0.333333|1000|0.333333|0.333333||1.000000||cpu=0
+`
+	root, err := parseSshare(out)
+	if err != nil {
+		t.Fatalf("parseSshare() error = %v", err)
+	}
+	if root.Account != "root" || len(root.Users) != 1 || len(root.SubAccounts) != 2 {
+		t.Fatalf("parseSshare() root = %+v", root)
+	}
+	physics := root.SubAccounts[0]
+	if physics.Account != "physics" || physics.EffectiveUsage != 0.666667 || len(physics.Users) != 1 || len(physics.SubAccounts) != 1 {
+		t.Errorf("physics = %+v", physics)
+	}
+	if theory := physics.SubAccounts[0]; theory.LevelFS != nil || len(theory.Users) != 1 || theory.Users[0].User != "bob" {
+		t.Errorf("theory = %+v", theory)
+	}
+	if root.Users[0].LevelFS != nil {
+		t.Errorf("root user LevelFS = %v, want nil for inf", *root.Users[0].LevelFS)
+	}
+	if n := findFairshareNode(root, "chem"); n == nil || n.RawUsage != 1000 {
+		t.Errorf("findFairshareNode(chem) = %+v", n)
+	}
+}
+
+func TestReasonText(t *testing.T) {
+	if got := ReasonText("AssocGrpJobsLimit"); !strings.Contains(got, "GrpJobs") {
+		t.Errorf("ReasonText(AssocGrpJobsLimit) = %q", got)
+	}
+	if got := ReasonText("AssocGrpBillingMinutes"); !strings.Contains(got, "AssocGrpBillingMinutes") {
+		t.Errorf("ReasonText(AssocGrpBillingMinutes) = %q", got)
+	}
+	if got := ReasonText(""); got != "" {
+		t.Errorf("ReasonText(\"\") = %q", got)
+	}
+}
+
+func TestParseSdiag(t *testing.T) {
+	out := `*******************************************************
+sdiag output at Sat Oct 18 10:00:00 2026 (1792288800)
+Data since      Sat Oct 18 00:00:00 2026 (1792252800)
+*******************************************************
+Server thread count:  3
+RPC queue enabled:    0
+Agent queue size:     2
+Agent count:          1
+Agent thread count:   4
+DBD Agent queue size: 7
+
+Jobs submitted: 100
+Jobs started:   90
+Job states ts:  Sat Oct 18 10:00:00 2026 (1792288800)
+Jobs pending:   10
+
+Main schedule statistics (microseconds):
+	Last cycle:   1234
+	Mean cycle:   345
+	Last queue length: 10
+
+Backfilling stats
+	Total backfilled jobs (since last slurm start): 12
+	Last cycle when: Sat Oct 18 09:59:00 2026 (1792288740)
+	Last cycle: 1000
+
+Remote Procedure Call statistics by message type
+	REQUEST_PARTITION_INFO                  ( 2009) count:100    ave_time:200    total_time:20000
+	MESSAGE_NODE_REGISTRATION_STATUS        ( 1002) count:5      ave_time:80     total_time:400
+
+Remote Procedure Call statistics by user
+	root            (       0) count:500    ave_time:300    total_time:150000
+
+Pending RPC statistics
+	No pending RPCs
+`
+	d := parseSdiag(out)
+	if d.Time != 1792288800 || d.DataSince != 1792252800 || d.ServerThreadCount != 3 || d.AgentQueueSize != 2 || d.DBDAgentQueueSize != 7 {
+		t.Errorf("parseSdiag() header = %+v", d)
+	}
+	if d.Jobs["submitted"] != 100 || d.Jobs["pending"] != 10 {
+		t.Errorf("parseSdiag() jobs = %v", d.Jobs)
+	}
+	if d.Main["last_cycle"] != 1234 || d.Main["last_queue_length"] != 10 || d.Backfill["last_cycle"] != 1000 ||
+		d.Backfill["total_backfilled_jobs_since_last_slurm_start"] != 12 {
+		t.Errorf("parseSdiag() main = %v, backfill = %v", d.Main, d.Backfill)
+	}
+	if len(d.RPCByType) != 2 || d.RPCByType[0].ID != 2009 || d.RPCByType[0].TotalTime != 20000 {
+		t.Errorf("parseSdiag() rpc by type = %+v", d.RPCByType)
+	}
+	if len(d.RPCByUser) != 1 || d.RPCByUser[0].Name != "root" || d.RPCByUser[0].Count != 500 {
+		t.Errorf("parseSdiag() rpc by user = %+v", d.RPCByUser)
+	}
+}
+
+func TestParseJobDetails(t *testing.T) {
+	out := `JobId=1234 HetJobId=1234 HetJobOffset=0 JobName=het
+   UserId=alice(1001) GroupId=alice(1001) Account=proj QOS=normal
+   JobState=RUNNING Reason=None TimeLimit=01:00:00
+   Partition=cpu NodeList=cn[01-02] NumNodes=2 NumCPUs=8
+   TRES=cpu=8,mem=16G,node=2,billing=8
+
+JobId=1235 HetJobId=1234 HetJobOffset=1 JobName=het
+   UserId=alice(1001) GroupId=alice(1001) Account=proj QOS=normal
+   JobState=RUNNING Reason=None TimeLimit=01:00:00
+   Partition=gpu NodeList=gn01 NumNodes=1 NumCPUs=4
+   TRES=cpu=4,mem=8G,node=1,gres/gpu=2
+`
+	jobs := parseJobDetails(out)
+	if len(jobs) != 2 {
+		t.Fatalf("parseJobDetails() = %d jobs, want 2", len(jobs))
+	}
+	if jobs[0].DisplayID != "1234+0" || jobs[0].User != "alice" || len(jobs[0].Nodes) != 2 || jobs[0].TimeLimit != 3600 {
+		t.Errorf("parseJobDetails() leader = %+v", jobs[0])
+	}
+	if jobs[1].DisplayID != "1234+1" || jobs[1].Partition != "gpu" || jobs[1].TRES["gres/gpu"] != "2" {
+		t.Errorf("parseJobDetails() component = %+v", jobs[1])
+	}
+
+	assignHetSteps(jobs, models.Steps{{ID: "1234+0.batch"}, {ID: "1234+1.0"}, {ID: "1234+10.0"}})
+	if len(jobs[0].Steps) != 1 || len(jobs[1].Steps) != 1 || jobs[1].Steps[0].ID != "1234+1.0" {
+		t.Errorf("assignHetSteps() = %+v, %+v", jobs[0].Steps, jobs[1].Steps)
+	}
+}
+
+// recordExec 返回记录命令行并以 output 与 exitCode 结束的 ExecCommandFunc.
+func recordExec(calls *[]string, output string, exitCode int) ExecCommandFunc {
+	return func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		*calls = append(*calls, strings.Join(append([]string{name}, args...), " | "))
+		return exec.CommandContext(ctx, "sh", "-c", fmt.Sprintf("printf '%%s' '%s'; exit %d", output, exitCode))
+	}
+}
+
+func TestUpdateNodeState(t *testing.T) {
+	var calls []string
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	c := (&Client{}).Set(recordExec(&calls, "", 0), logger)
+	ctx := context.Background()
+
+	if err := c.UpdateNodeState(ctx, "cn[1-2]", "drain", "disk failure", "alice"); err != nil {
+		t.Fatalf("UpdateNodeState(drain) error = %v", err)
+	}
+	if err := c.UpdateNodeState(ctx, " cn1 ", "Resume", "fixed", "alice"); err != nil {
+		t.Fatalf("UpdateNodeState(resume) error = %v", err)
+	}
+	want := []string{
+		"scontrol | update | nodename=cn[1-2] | state=DRAIN | reason=disk failure [by alice]",
+		"scontrol | update | nodename=cn1 | state=RESUME",
+	}
+	if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("UpdateNodeState() calls = %q, want %q", calls, want)
+	}
+
+	calls = nil
+	bad := []struct{ nodes, state, reason, operator string }{
+		{"cn1", "IDLE", "x", "alice"},             // 不支持的状态
+		{"", "DRAIN", "x", "alice"},               // 缺少节点
+		{"cn[1-", "DRAIN", "x", "alice"},          // 非法 hostlist
+		{"cn1 state=down", "DRAIN", "x", "alice"}, // 非法节点名称
+		{"cn1", "DRAIN", " ", "alice"},            // 缺少原因
+		{"cn1", "DRAIN", "x", ""},                 // 缺少操作人
+	}
+	for _, b := range bad {
+		if err := c.UpdateNodeState(ctx, b.nodes, b.state, b.reason, b.operator); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("UpdateNodeState(%+v) error = %v, want ErrInvalidArgument", b, err)
+		}
+	}
+	if len(calls) != 0 {
+		t.Errorf("UpdateNodeState() executed %q for invalid arguments", calls)
+	}
+
+	c = (&Client{}).Set(recordExec(&calls, "Invalid node name specified", 1), logger)
+	err := c.UpdateNodeState(ctx, "cn9", "down", "x", "alice")
+	if err == nil || errors.Is(err, ErrInvalidArgument) || !strings.Contains(err.Error(), "Invalid node name specified") {
+		t.Errorf("UpdateNodeState() exec failure error = %v", err)
+	}
+}
+
+func TestRebootNodes(t *testing.T) {
+	var calls []string
+	c := (&Client{}).Set(recordExec(&calls, "", 0), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	if err := c.RebootNodes(ctx, "cn[1-4]", true, "resume", "bios update", "bob"); err != nil {
+		t.Fatalf("RebootNodes() error = %v", err)
+	}
+	if err := c.RebootNodes(ctx, "cn5", false, "", "kernel", "bob"); err != nil {
+		t.Fatalf("RebootNodes() error = %v", err)
+	}
+	want := []string{
+		"scontrol | reboot | ASAP | nextstate=RESUME | reason=bios update [by bob] | cn[1-4]",
+		"scontrol | reboot | reason=kernel [by bob] | cn5",
+	}
+	if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("RebootNodes() calls = %q, want %q", calls, want)
+	}
+
+	calls = nil
+	if err := c.RebootNodes(ctx, "cn1", false, "IDLE", "x", "bob"); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("RebootNodes(nextstate=IDLE) error = %v, want ErrInvalidArgument", err)
+	}
+	if err := c.RebootNodes(ctx, "cn[2-1]", false, "", "x", "bob"); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("RebootNodes(cn[2-1]) error = %v, want ErrInvalidArgument", err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("RebootNodes() executed %q for invalid arguments", calls)
+	}
+}
+
+func TestCreateReservationChecksNodes(t *testing.T) {
+	var calls []string
+	run := func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		calls = append(calls, name)
+		out := "Reservation created: maint_1"
+		if name == "sinfo" {
+			out = "cn1 debug idle 1000 4 1 4 1 (null)\ncn2 debug idle 1000 4 1 4 1 (null)\n"
+		}
+		return recordExec(new([]string), out, 0)(ctx, name, args...)
+	}
+	c := (&Client{}).Set(run, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+	spec := ReservationSpec{StartTime: "now", Duration: "1:00:00", Nodes: "cn[1-3]", Users: []string{"alice"}}
+
+	if _, err := c.CreateReservation(ctx, spec, "bob"); !errors.Is(err, ErrInvalidArgument) || !strings.Contains(err.Error(), "cn3") {
+		t.Errorf("CreateReservation(cn[1-3]) error = %v, want unknown node cn3", err)
+	}
+	if strings.Join(calls, ",") != "sinfo" {
+		t.Errorf("CreateReservation(cn[1-3]) calls = %q, want sinfo only", calls)
+	}
+
+	calls = nil
+	spec.Nodes = "cn[1-2]"
+	if name, err := c.CreateReservation(ctx, spec, "bob"); err != nil || name != "maint_1" {
+		t.Errorf("CreateReservation(cn[1-2]) = %q, %v", name, err)
+	}
+	if err := c.UpdateReservation(ctx, ReservationSpec{Name: "maint_1", Nodes: "ALL"}, "bob"); err != nil {
+		t.Errorf("UpdateReservation(ALL) error = %v", err)
+	}
+	if strings.Join(calls, ",") != "sinfo,scontrol,scontrol" {
+		t.Errorf("calls = %q", calls)
+	}
+}
+
+func TestDeleteReservationErrors(t *testing.T) {
+	var calls []string
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+
+	c := (&Client{}).Set(recordExec(&calls, "Error deleting the reservation: Requested reservation is invalid", 1), logger)
+	if err := c.DeleteReservation(ctx, "maint_9", "bob"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("DeleteReservation(missing) error = %v, want ErrNotFound", err)
+	}
+	calls = nil
+	if err := c.DeleteReservation(ctx, "maint 9", "bob"); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("DeleteReservation(invalid name) error = %v, want ErrInvalidArgument", err)
+	}
+	if _, err := c.GetReservation(ctx, "a,b"); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("GetReservation(invalid name) error = %v, want ErrInvalidArgument", err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("executed %q for invalid names", calls)
+	}
+}
@@ -0,0 +1,371 @@
+package slurmctl
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"solid/internal/pkg/client/slurmctl/models"
+	"solid/internal/pkg/common/hostlist"
+)
+
+// Snapshot 某一时刻集群节点、作业与分区的状态快照, 获取后不应被修改.
+type Snapshot struct {
+	Nodes      models.Nodes
+	Jobs       models.Jobs // 所有状态的作业(squeue --states=all), 包括 slurmctld 尚未清除的已结束作业
+	Partitions models.Partitions
+	Time       time.Time // 快照生成时间
+}
+
+// Age 返回快照距今的时长.
+func (s *Snapshot) Age() time.Duration { return time.Since(s.Time) }
+
+// SnapshotCache 由后台轮询刷新的集群快照缓存. 并发的刷新请求通过 singleflight 合并为一次
+// sinfo/squeue/scontrol 调用, 避免大量请求同时压到 slurmctld.
+type SnapshotCache struct {
+	client   *Client
+	interval time.Duration
+	timeout  time.Duration
+	logger   *slog.Logger
+
+	group   singleflight.Group
+	mu      sync.RWMutex
+	current *Snapshot
+	events  *EventHub
+
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	refreshes     atomic.Uint64
+	refreshErrors atomic.Uint64
+}
+
+// Package-level default SnapshotCache, nil 表示未启用缓存.
+var defaultCache *SnapshotCache
+
+// SetDefaultCache sets the package-level default SnapshotCache.
+func SetDefaultCache(c *SnapshotCache) { defaultCache = c }
+
+// DefaultCache returns the package-level default SnapshotCache.
+func DefaultCache() *SnapshotCache { return defaultCache }
+
+// NewSnapshotCache 创建快照缓存, interval 为后台刷新周期.
+func NewSnapshotCache(client *Client, interval time.Duration, logger *slog.Logger) *SnapshotCache {
+	timeout := interval
+	if timeout < 30*time.Second {
+		timeout = 30 * time.Second
+	}
+	return &SnapshotCache{client: client, interval: interval, timeout: timeout, logger: logger, events: NewEventHub(0)}
+}
+
+// Events 返回由相邻快照差异产生的事件中心.
+func (sc *SnapshotCache) Events() *EventHub { return sc.events }
+
+// Run 启动后台轮询, 立即刷新一次后按 interval 周期刷新, 直到 ctx 取消.
+func (sc *SnapshotCache) Run(ctx context.Context) {
+	ticker := time.NewTicker(sc.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := sc.Refresh(); err != nil {
+			sc.logger.Warn("unable to refresh cluster snapshot", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Get 返回当前快照. fresh 为 true 或缓存为空时会同步刷新; 刷新失败且存在旧快照时返回旧快照.
+func (sc *SnapshotCache) Get(fresh bool) (*Snapshot, error) {
+	if !fresh {
+		sc.mu.RLock()
+		cur := sc.current
+		sc.mu.RUnlock()
+		if cur != nil {
+			sc.hits.Add(1)
+			return cur, nil
+		}
+	}
+	sc.misses.Add(1)
+	snap, err := sc.Refresh()
+	if err != nil {
+		sc.mu.RLock()
+		cur := sc.current
+		sc.mu.RUnlock()
+		if cur != nil {
+			return cur, nil
+		}
+		return nil, err
+	}
+	return snap, nil
+}
+
+// Refresh 重新获取集群快照, 并发调用只会触发一次实际采集.
+func (sc *SnapshotCache) Refresh() (*Snapshot, error) {
+	v, err, _ := sc.group.Do("snapshot", func() (interface{}, error) {
+		// 采集结果由多个请求共享, 不使用任一请求的 context
+		ctx, cancel := context.WithTimeout(context.Background(), sc.timeout)
+		defer cancel()
+
+		sc.refreshes.Add(1)
+		snap, err := sc.collect(ctx)
+		if err != nil {
+			sc.refreshErrors.Add(1)
+			return nil, err
+		}
+		sc.mu.Lock()
+		prev := sc.current
+		sc.current = snap
+		sc.mu.Unlock()
+		if prev != nil {
+			sc.events.Publish(DiffSnapshots(prev, snap))
+		}
+		return snap, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*Snapshot), nil
+}
+
+func (sc *SnapshotCache) collect(ctx context.Context) (*Snapshot, error) {
+	nodes, err := sc.client.GetNodes(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("unable to collect nodes: %w", err)
+	}
+	// 采集所有状态, 以便看到作业的最终状态; FilterJobs 在未指定状态时按 squeue 的默认状态过滤
+	jobs, err := sc.client.GetJobs(ctx, JobsFilter{State: "all"})
+	if err != nil {
+		return nil, fmt.Errorf("unable to collect jobs: %w", err)
+	}
+	parts, err := sc.client.showPartitions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to collect partitions: %w", err)
+	}
+	JoinNodeStates(parts, nodes)
+	return &Snapshot{Nodes: nodes, Jobs: jobs, Partitions: parts, Time: time.Now()}, nil
+}
+
+// CacheStats 快照缓存的运行统计.
+type CacheStats struct {
+	Interval      string    `json:"interval"`       // 后台刷新周期
+	Hits          uint64    `json:"hits"`           // 命中次数
+	Misses        uint64    `json:"misses"`         // 未命中(含 fresh=true)次数
+	HitRate       float64   `json:"hit_rate"`       // 命中率
+	Refreshes     uint64    `json:"refreshes"`      // 实际采集次数
+	RefreshErrors uint64    `json:"refresh_errors"` // 采集失败次数
+	SnapshotTime  time.Time `json:"snapshot_time"`  // 当前快照生成时间
+	SnapshotAge   float64   `json:"snapshot_age"`   // 当前快照年龄, 单位秒
+}
+
+// Stats 返回缓存统计信息.
+func (sc *SnapshotCache) Stats() CacheStats {
+	st := CacheStats{
+		Interval:      sc.interval.String(),
+		Hits:          sc.hits.Load(),
+		Misses:        sc.misses.Load(),
+		Refreshes:     sc.refreshes.Load(),
+		RefreshErrors: sc.refreshErrors.Load(),
+	}
+	if total := st.Hits + st.Misses; total > 0 {
+		st.HitRate = float64(st.Hits) / float64(total)
+	}
+	sc.mu.RLock()
+	if sc.current != nil {
+		st.SnapshotTime = sc.current.Time
+		st.SnapshotAge = sc.current.Age().Seconds()
+	}
+	sc.mu.RUnlock()
+	return st
+}
+
+// jobStateCodes squeue 长状态名与 %t 短状态码的对应关系.
+var jobStateCodes = map[string]string{
+	"BOOT_FAIL":     "BF",
+	"CANCELLED":     "CA",
+	"COMPLETED":     "CD",
+	"CONFIGURING":   "CF",
+	"COMPLETING":    "CG",
+	"DEADLINE":      "DL",
+	"FAILED":        "F",
+	"NODE_FAIL":     "NF",
+	"OUT_OF_MEMORY": "OOM",
+	"PENDING":       "PD",
+	"PREEMPTED":     "PR",
+	"RUNNING":       "R",
+	"RESV_DEL_HOLD": "RD",
+	"REQUEUE_FED":   "RF",
+	"REQUEUE_HOLD":  "RH",
+	"REQUEUED":      "RQ",
+	"RESIZING":      "RS",
+	"REVOKED":       "RV",
+	"SIGNALING":     "SI",
+	"SPECIAL_EXIT":  "SE",
+	"STAGE_OUT":     "SO",
+	"STOPPED":       "ST",
+	"SUSPENDED":     "S",
+	"TIMEOUT":       "TO",
+}
+
+// validJobState 判断 squeue --states 的取值是否合法, 支持长状态名、短状态码与 all, 不区分大小写.
+func validJobState(s string) bool {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "ALL" {
+		return true
+	}
+	if _, ok := jobStateCodes[s]; ok {
+		return true
+	}
+	for _, code := range jobStateCodes {
+		if code == s {
+			return true
+		}
+	}
+	return false
+}
+
+// finishedJobStates 已结束的作业短状态码. squeue 未指定 --states 时不显示这些状态的作业.
+var finishedJobStates = map[string]bool{
+	"CD": true, "CA": true, "F": true, "TO": true, "NF": true, "PR": true,
+	"BF": true, "DL": true, "OOM": true, "RV": true,
+}
+
+// Match 在内存中判断作业是否满足过滤条件, 语义与下推到 squeue 时一致.
+func (f JobsFilter) Match(job models.Job) bool { return f.matcher()(job) }
+
+// matcher 返回过滤函数, 节点表达式只展开一次.
+func (f JobsFilter) matcher() func(models.Job) bool {
+	var nodes map[string]struct{}
+	if v := strings.TrimSpace(f.Node); v != "" {
+		hosts, err := hostlist.Expand(v)
+		if err != nil {
+			return func(models.Job) bool { return false }
+		}
+		nodes = make(map[string]struct{}, len(hosts))
+		for _, h := range hosts {
+			nodes[h] = struct{}{}
+		}
+	}
+	// 未指定状态时与 squeue 一致, 只匹配未结束的作业; all 匹配所有状态
+	var states map[string]struct{}
+	allStates := false
+	if v := strings.TrimSpace(f.State); v != "" {
+		states = make(map[string]struct{})
+		for _, s := range strings.Split(v, ",") {
+			s = strings.ToUpper(strings.TrimSpace(s))
+			if s == "ALL" {
+				allStates = true
+			}
+			if code, ok := jobStateCodes[s]; ok {
+				s = code
+			}
+			states[s] = struct{}{}
+		}
+	}
+
+	return func(job models.Job) bool {
+		if !matchList(f.User, job.User) || !matchList(f.Account, job.Account) ||
+			!matchList(f.QoS, job.QoS) || !matchList(f.Name, job.Name) {
+			return false
+		}
+		if v := strings.TrimSpace(f.Partition); v != "" {
+			ok := false
+			for _, p := range strings.Split(job.Partition, ",") {
+				if matchList(v, p) {
+					ok = true
+					break
+				}
+			}
+			if !ok {
+				return false
+			}
+		}
+		switch {
+		case allStates:
+		case states == nil:
+			if finishedJobStates[job.State] {
+				return false
+			}
+		default:
+			if _, ok := states[job.State]; !ok {
+				return false
+			}
+		}
+		if v := strings.TrimSpace(f.Reason); v != "" && !strings.EqualFold(job.Reason, v) {
+			return false
+		}
+		if nodes != nil {
+			if ok, _ := hostlist.Intersects(job.Nodelist, nodes); !ok {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// matchList 判断 val 是否在逗号分隔的 list 中, list 为空表示不过滤.
+func matchList(list, val string) bool {
+	list = strings.TrimSpace(list)
+	if list == "" {
+		return true
+	}
+	for _, item := range strings.Split(list, ",") {
+		if strings.TrimSpace(item) == val {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterJobs 在内存中过滤并排序作业, 用于从快照中返回与 GetJobs 等价的结果. 不修改输入.
+func FilterJobs(jobs models.Jobs, filter JobsFilter) models.Jobs {
+	match := filter.matcher()
+	out := make(models.Jobs, 0)
+	for _, job := range jobs {
+		if match(job) {
+			out = append(out, job)
+		}
+	}
+
+	sortBy := strings.TrimSpace(filter.Sort)
+	if sortBy == "" {
+		return out
+	}
+	desc := strings.HasPrefix(sortBy, "-")
+	var less func(a, b models.Job) bool
+	switch strings.TrimPrefix(sortBy, "-") {
+	case "priority":
+		less = func(a, b models.Job) bool { return a.Priority < b.Priority }
+	case "submit_time":
+		less = func(a, b models.Job) bool { return a.SubmitTime < b.SubmitTime }
+	case "jobid":
+		less = func(a, b models.Job) bool { return jobIDKey(a.Jobid) < jobIDKey(b.Jobid) }
+	default:
+		return out
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if desc {
+			return less(out[j], out[i])
+		}
+		return less(out[i], out[j])
+	})
+	return out
+}
+
+// jobIDKey 返回作业 ID 的数值部分用于排序, 数组作业(123_4)与异构作业(123+1)取主作业 ID.
+func jobIDKey(id string) uint64 {
+	if i := strings.IndexAny(id, "_+"); i >= 0 {
+		id = id[:i]
+	}
+	n, _ := strconv.ParseUint(id, 10, 64)
+	return n
+}
@@ -0,0 +1,47 @@
+package slurmdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"solid/internal/pkg/model"
+)
+
+// GetAssocTree 返回以账户 root 为根的完整关联树. slurmdbd 以嵌套集合(lft/rgt)维护关联层级, 一次自连接查询
+// 即可取得根账户的整个子树及其祖先, 祖先仅用于计算根节点继承的限制, 不出现在结果中.
+// depth 大于 0 时只返回相对根节点深度不超过 depth 的节点.
+func (c *Client) GetAssocTree(ctx context.Context, root string, depth int) (*model.AssocTreeNode, error) {
+	if c == nil || c.DB == nil {
+		return nil, fmt.Errorf("nil slurmdb Client")
+	}
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(root) == "" {
+		return nil, fmt.Errorf("account name is required")
+	}
+
+	var rows []*model.AssocTreeNode
+	if err := cdb.TableAs(AssocTable, "a").
+		Select("a.*").
+		Joins("JOIN "+cdb.Name(AssocTable)+" AS r ON (a.lft BETWEEN r.lft AND r.rgt) OR (a.lft < r.lft AND a.rgt > r.rgt)").
+		Where("r.acct = ? AND r.`user` = '' AND r.deleted = 0 AND a.deleted = 0", root).
+		Order("a.lft ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	var inherited model.AssocLimits
+	for i, n := range rows {
+		if n.Acct == root && !n.IsUser() {
+			return model.BuildAssocTree(rows[i:], inherited, depth), nil
+		}
+		// 祖先按 lft 升序排列, 依次继承
+		inherited = model.InheritLimits(inherited, n.Limits)
+	}
+	return nil, gorm.ErrRecordNotFound
+}
@@ -0,0 +1,100 @@
+package slurmdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"solid/internal/pkg/model"
+)
+
+// ErrUnknownCluster 集群不存在于 cluster_table 或不在配置允许的集群列表中.
+var ErrUnknownCluster = errors.New("unknown cluster")
+
+// GetClusters 返回 cluster_table 中未删除的集群, 配置了集群列表时只返回列表中的集群. 结果按名称排序.
+func (c *Client) GetClusters(ctx context.Context) (model.Clusters, error) {
+	if c == nil || c.DB == nil {
+		return nil, fmt.Errorf("nil slurmdb Client")
+	}
+	q := c.DB.WithContext(ctx).Model(&model.Cluster{}).Where("deleted = 0")
+	if len(c.clusters) > 0 {
+		q = q.Where("name IN ?", c.clusters)
+	}
+	var rows model.Clusters
+	if err := q.Order("name ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].Default = rows[i].Name == c.ClusterName
+	}
+	return rows, nil
+}
+
+// WithCluster 返回查询集群 name 的客户端, 与当前客户端共享连接池. name 为空或与当前集群相同时返回当前客户端.
+// name 经 ForCluster 校验, 格式不合法时返回 ErrInvalidCluster, 不存在时返回 ErrUnknownCluster.
+func (c *Client) WithCluster(ctx context.Context, name string) (*Client, error) {
+	name = strings.TrimSpace(name)
+	if c == nil || name == "" || name == c.ClusterName {
+		return c, nil
+	}
+	if _, err := c.ForCluster(ctx, name); err != nil {
+		return nil, err
+	}
+	cp := *c
+	cp.ClusterName = name
+	return &cp, nil
+}
+
+// GetJobsDetailAllClusters 在所有集群中查询满足 filter 的作业, 结果按提交时间降序合并后分页, 每个作业填充所属集群.
+// 每个集群按相同的排序键(time_submit DESC, id_job DESC)读取前 page*pageSize 条, 合并后的前 page*pageSize 条
+// 必然来自这些行, 因此分页结果稳定; 页码越大开销越高.
+func (c *Client) GetJobsDetailAllClusters(ctx context.Context, filter JobsFilter, page, pageSize int) (model.Jobs, int64, error) {
+	clusters, err := c.GetClusters(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	var (
+		all   model.Jobs
+		total int64
+	)
+	for _, cl := range clusters {
+		cc := *c
+		cc.ClusterName = cl.Name
+		rows, n, err := cc.getJobsDetail(ctx, filter, jobsBySubmit, 1, page*pageSize)
+		if err != nil {
+			return nil, 0, fmt.Errorf("cluster %s: %w", cl.Name, err)
+		}
+		for i := range rows {
+			rows[i].Cluster = cl.Name
+		}
+		all = append(all, rows...)
+		total += n
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].TimeSubmit != all[j].TimeSubmit {
+			return all[i].TimeSubmit > all[j].TimeSubmit
+		}
+		if all[i].IDJob != all[j].IDJob {
+			return all[i].IDJob > all[j].IDJob
+		}
+		return all[i].Cluster < all[j].Cluster
+	})
+
+	offset := (page - 1) * pageSize
+	if offset > len(all) {
+		offset = len(all)
+	}
+	end := offset + pageSize
+	if end > len(all) {
+		end = len(all)
+	}
+	return all[offset:end], total, nil
+}
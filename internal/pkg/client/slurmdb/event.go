@@ -0,0 +1,137 @@
+package slurmdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"solid/internal/pkg/model"
+)
+
+// failedJobGrace 关联失败作业时允许的时间偏差(秒). 作业因节点故障结束与节点事件的记录时间不完全一致.
+const failedJobGrace = 300
+
+// NodeEventsFilter 节点事件查询条件, 零值表示不过滤.
+type NodeEventsFilter struct {
+	Nodes []string // 节点名称
+	Start int64    // 仅返回结束时间不早于该时间或尚未结束的事件(Unix 秒)
+	End   int64    // 仅返回开始时间早于该时间的事件(Unix 秒)
+}
+
+// nodeEvents 返回 <cluster>_event_table 中满足 filter 的节点事件查询, node_name 为空的集群事件不包含在内.
+func (d *ClusterDB) nodeEvents(filter NodeEventsFilter) *gorm.DB {
+	q := d.Table(EventTable).Where("node_name <> ''")
+	if len(filter.Nodes) > 0 {
+		q = q.Where("node_name IN ?", filter.Nodes)
+	}
+	if filter.Start > 0 {
+		q = q.Where("(time_end = 0 OR time_end >= ?)", filter.Start)
+	}
+	if filter.End > 0 {
+		q = q.Where("time_start < ?", filter.End)
+	}
+	return q
+}
+
+// GetNodeEvents 查询节点的停机历史(DOWN、DRAIN、FAIL 等), 按开始时间降序分页返回.
+func (c *Client) GetNodeEvents(ctx context.Context, filter NodeEventsFilter, page, pageSize int) (model.Events, int64, error) {
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	base := cdb.nodeEvents(filter)
+
+	var total int64
+	if err := base.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	rows := make(model.Events, 0)
+	if err := base.Order("time_start DESC, node_name ASC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	now := time.Now().Unix()
+	for i := range rows {
+		rows[i].Fill(now)
+	}
+	return rows, total, nil
+}
+
+// GetNodeAvailability 统计 nodes 在 [start, end) 内的可用性, nodes 为空时统计窗口内出现过事件的节点.
+func (c *Client) GetNodeAvailability(ctx context.Context, nodes []string, start, end int64) ([]model.NodeAvailability, error) {
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if start < 0 || end <= start {
+		return nil, fmt.Errorf("end must be after start")
+	}
+	var events model.Events
+	if err := cdb.nodeEvents(NodeEventsFilter{Nodes: nodes, Start: start, End: end}).
+		Select("time_start, time_end, node_name").
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return model.ComputeAvailability(events, nodes, start, end, time.Now().Unix()), nil
+}
+
+// AttachFailedJobs 为每个事件查询期间因该节点失败的作业, 即 <cluster>_job_table 中 failed_node 为该节点、
+// 结束时间落在事件时间范围(前后放宽 failedJobGrace 秒)内的作业, 填充到 FailedJobs.
+func (c *Client) AttachFailedJobs(ctx context.Context, events model.Events) error {
+	if len(events) == 0 {
+		return nil
+	}
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return err
+	}
+	now := uint64(time.Now().Unix())
+	from, to := events[0].TimeStart, uint64(0)
+	for _, e := range events {
+		from = min(from, e.TimeStart)
+		end := e.TimeEnd
+		if end == 0 {
+			end = now
+		}
+		to = max(to, end)
+	}
+	if from > failedJobGrace {
+		from -= failedJobGrace
+	} else {
+		from = 0
+	}
+	to += failedJobGrace
+
+	var jobs []model.FailedJob
+	if err := cdb.Table(JobTable).
+		Select("id_job, job_name, id_user, account, `partition`, state, failed_node, time_start, time_end").
+		Where("deleted = 0 AND failed_node IN ? AND time_end BETWEEN ? AND ?", events.NodeNames(), from, to).
+		Order("time_end ASC").
+		Find(&jobs).Error; err != nil {
+		return err
+	}
+	for i := range events {
+		e := &events[i]
+		end := e.TimeEnd
+		if end == 0 {
+			end = now
+		}
+		for _, j := range jobs {
+			if j.Node != e.NodeName || j.TimeEnd+failedJobGrace < e.TimeStart || j.TimeEnd > end+failedJobGrace {
+				continue
+			}
+			j.StateName = model.JobStateName(j.State)
+			e.FailedJobs = append(e.FailedJobs, j)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package slurmdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"solid/internal/pkg/model"
+)
+
+// ErrUnknownQos 指定的 QoS 不存在.
+var ErrUnknownQos = errors.New("qos not found")
+
+// PartitionLimits 分区上的限制, 由调用方从 slurmctld 获取. 数值不大于 0 表示不限制.
+type PartitionLimits struct {
+	Name           string // 分区名称
+	Qos            string // 分区 QoS 名称
+	MaxWall        int64  // 最长运行时间(分钟)
+	MaxNodes       int64  // 单个作业的最大节点数
+	MaxCPUsPerNode int64  // 每个节点的最大 CPU 数
+}
+
+// layer 返回分区设置的限制.
+func (p *PartitionLimits) layer() model.LimitLayer {
+	l := model.LimitLayer{Source: model.LimitSource{Kind: "partition", Name: p.Name}, Values: map[string]int64{}}
+	if p.MaxWall > 0 {
+		l.Values["max_wall_pj"] = p.MaxWall
+	}
+	// TRES ID: cpu=1, node=4
+	if p.MaxNodes > 0 {
+		l.Values["max_tres_pj/4"] = p.MaxNodes
+	}
+	if p.MaxCPUsPerNode > 0 {
+		l.Values["max_tres_pn/1"] = p.MaxCPUsPerNode
+	}
+	return l
+}
+
+// GetEffectiveLimits 计算用户 user 在账户 account、分区 partition 下提交作业时生效的限制, 每项限制附带其来源.
+// user 为空时计算账户关联本身. 用户存在分区专属关联时使用该关联, 否则使用不区分分区的关联.
+// 优先级与 Slurm 一致: 分区 QoS > 作业 QoS > 用户关联 > 各级父账户关联 > 分区限制; 作业 QoS 带有
+// OverPartQOS 标志时作业 QoS 优先于分区 QoS. 各级关联的组限制同时生效, 取最严格的取值, 见 model.ResolveLimits.
+// qos 为空时使用关联(含继承)的默认 QoS. part 可为 nil.
+func (c *Client) GetEffectiveLimits(ctx context.Context, account, user, partition, qos string, part *PartitionLimits) (*model.EffectiveLimits, error) {
+	if c == nil || c.DB == nil {
+		return nil, fmt.Errorf("nil slurmdb Client")
+	}
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(account) == "" {
+		return nil, fmt.Errorf("account is required")
+	}
+
+	var leaf model.AssocTreeNode
+	if err := cdb.Table(AssocTable).
+		Where("deleted = 0 AND acct = ? AND `user` = ? AND `partition` IN ?", account, user, []string{partition, ""}).
+		Order("`partition` DESC").
+		First(&leaf).Error; err != nil {
+		return nil, err
+	}
+	// 关联及其所有祖先, 叶子在前
+	var chain []*model.AssocTreeNode
+	if err := cdb.Table(AssocTable).
+		Where("deleted = 0 AND lft <= ? AND rgt >= ?", leaf.Lft, leaf.Rgt).
+		Order("lft DESC").
+		Find(&chain).Error; err != nil {
+		return nil, err
+	}
+
+	out := &model.EffectiveLimits{
+		IDAssoc:   leaf.IDAssoc,
+		Account:   leaf.Acct,
+		User:      leaf.User,
+		Partition: leaf.Partition,
+	}
+
+	var jobQos, partQos *model.QosLimits
+	if qos != "" {
+		if jobQos, err = c.getQosLimits(ctx, "name = ?", qos); err != nil {
+			return nil, err
+		}
+		if jobQos == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownQos, qos)
+		}
+	} else {
+		var inherited model.AssocLimits
+		for i := len(chain) - 1; i >= 0; i-- {
+			inherited = model.InheritLimits(inherited, chain[i].Limits)
+		}
+		if inherited.DefQosID != nil && *inherited.DefQosID > 0 {
+			if jobQos, err = c.getQosLimits(ctx, "id = ?", *inherited.DefQosID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if part != nil && part.Qos != "" {
+		if partQos, err = c.getQosLimits(ctx, "name = ?", part.Qos); err != nil {
+			return nil, err
+		}
+	}
+
+	var layers []model.LimitLayer
+	if jobQos != nil {
+		out.Qos = jobQos.Name
+		out.OverPartQOS = jobQos.Flags&model.QosFlagOverPartQOS != 0
+	}
+	if partQos != nil {
+		out.PartitionQos = partQos.Name
+	}
+	switch {
+	case out.OverPartQOS:
+		layers = appendQosLayer(layers, "qos", jobQos)
+		layers = appendQosLayer(layers, "partition_qos", partQos)
+	default:
+		layers = appendQosLayer(layers, "partition_qos", partQos)
+		layers = appendQosLayer(layers, "qos", jobQos)
+	}
+	for _, n := range chain {
+		layers = append(layers, model.AssocLimitLayer(n))
+	}
+	if part != nil {
+		layers = append(layers, part.layer())
+	}
+
+	tres, err := c.GetTresTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out.Chain = make([]model.LimitSource, 0, len(layers))
+	for _, l := range layers {
+		out.Chain = append(out.Chain, l.Source)
+	}
+	out.Limits = model.ResolveLimits(layers, tres)
+	return out, nil
+}
+
+// appendQosLayer q 不为 nil 时追加其限制.
+func appendQosLayer(layers []model.LimitLayer, kind string, q *model.QosLimits) []model.LimitLayer {
+	if q == nil {
+		return layers
+	}
+	return append(layers, model.QosLimitLayer(kind, q))
+}
+
+// getQosLimits 查询单个未删除 QoS 的限制, 不存在时返回 nil.
+func (c *Client) getQosLimits(ctx context.Context, cond string, arg any) (*model.QosLimits, error) {
+	var rows []model.QosLimits
+	if err := c.DB.WithContext(ctx).Table("qos_table").
+		Where("deleted = 0").Where(cond, arg).
+		Limit(1).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return &rows[0], nil
+}
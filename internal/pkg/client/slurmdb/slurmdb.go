@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 
@@ -14,6 +15,8 @@ import (
 	glogger "gorm.io/gorm/logger"
 
 	"solid/config"
+	"solid/internal/pkg/common/hostlist"
+	"solid/internal/pkg/common/jobid"
 	"solid/internal/pkg/model"
 )
 
@@ -21,6 +24,8 @@ import (
 type Client struct {
 	DB          *gorm.DB
 	ClusterName string
+	clusters    []string      // 允许访问的集群, 为空表示 cluster_table 中的全部集群
+	known       *clusterCache // 已校验的集群名称, 客户端副本之间共享
 	logger      *slog.Logger
 }
 
@@ -77,7 +82,7 @@ func New(cfg config.Slurmdb, logger *slog.Logger) (*Client, error) {
 	// Enforce read-only at ORM layer
 	enforceReadOnly(db)
 
-	return &Client{DB: db, ClusterName: cfg.ClusterName, logger: logger}, nil
+	return &Client{DB: db, ClusterName: cfg.ClusterName, clusters: cfg.Clusters, known: &clusterCache{}, logger: logger}, nil
 }
 
 // buildDSN constructs a DSN string without importing the mysql driver package.
@@ -377,10 +382,12 @@ func (c *Client) GetAssociationChildNodesOfAccount(ctx context.Context, account
 
 // GetPartitionOfAccount 从 assoc_table 中查找某个账户的分区信息.
 func (c *Client) GetPartitionOfAccount(ctx context.Context, account string) (string, error) {
-	table := fmt.Sprintf("%s_assoc_table", c.ClusterName)
 	var partition string
-	if err := c.DB.WithContext(ctx).
-		Table(table).
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return partition, err
+	}
+	if err := cdb.Table(AssocTable).
 		Where("acct = ? AND deleted = 0 AND `user` = ''", account).
 		Distinct("`partition`").
 		Pluck("`partition`", &partition).Error; err != nil {
@@ -400,14 +407,13 @@ func (c *Client) GetPartitionsOfUser(ctx context.Context, account, user string)
 	if strings.TrimSpace(user) == "" {
 		return nil, fmt.Errorf("username is required")
 	}
-	if strings.TrimSpace(c.ClusterName) == "" {
-		return nil, fmt.Errorf("cluster name is empty in slurmdb Client")
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	table := fmt.Sprintf("%s_assoc_table", c.ClusterName)
 	var parts []string
-	if err := c.DB.WithContext(ctx).
-		Table(table).
+	if err := cdb.Table(AssocTable).
 		Where("acct = ? AND `user` = ? AND deleted = 0", account, user).
 		Where("`partition` <> ''").
 		Distinct().
@@ -474,15 +480,13 @@ func (c *Client) GetSubAccountsAndUsers(ctx context.Context, account string) ([]
 	if strings.TrimSpace(account) == "" {
 		return nil, nil, fmt.Errorf("account name is required")
 	}
-	if strings.TrimSpace(c.ClusterName) == "" {
-		return nil, nil, fmt.Errorf("cluster name is empty in slurmdb Client")
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return nil, nil, err
 	}
-	table := fmt.Sprintf("%s_assoc_table", c.ClusterName)
-
 	// Sub-accounts: rows with user='' and parent_acct = account
 	var subAccts []string
-	if err := c.DB.WithContext(ctx).
-		Table(table).
+	if err := cdb.Table(AssocTable).
 		Where("parent_acct = ? AND deleted = 0 AND `user` = ''", account).
 		Distinct().
 		Pluck("acct", &subAccts).Error; err != nil {
@@ -491,8 +495,7 @@ func (c *Client) GetSubAccountsAndUsers(ctx context.Context, account string) ([]
 
 	// Sub-users: rows with acct=account and user<>''
 	var subUsers []string
-	if err := c.DB.WithContext(ctx).
-		Table(table).
+	if err := cdb.Table(AssocTable).
 		Where("acct = ? AND deleted = 0 AND `user` <> ''", account).
 		Distinct().
 		Pluck("`user`", &subUsers).Error; err != nil {
@@ -510,13 +513,12 @@ func (c *Client) GetParentAccountsByUser(ctx context.Context, username string) (
 	if strings.TrimSpace(username) == "" {
 		return nil, fmt.Errorf("username is required")
 	}
-	if strings.TrimSpace(c.ClusterName) == "" {
-		return nil, fmt.Errorf("cluster name is empty in slurmdb Client")
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return nil, err
 	}
-	table := fmt.Sprintf("%s_assoc_table", c.ClusterName)
 	var accts []string
-	if err := c.DB.WithContext(ctx).
-		Table(table).
+	if err := cdb.Table(AssocTable).
 		Where("`user` = ? AND deleted = 0", username).
 		Distinct().
 		Pluck("acct", &accts).Error; err != nil {
@@ -534,13 +536,12 @@ func (c *Client) GetUserAssociations(ctx context.Context, username string) ([]mo
 	if strings.TrimSpace(username) == "" {
 		return nil, fmt.Errorf("username is required")
 	}
-	if strings.TrimSpace(c.ClusterName) == "" {
-		return nil, fmt.Errorf("cluster name is empty in slurmdb Client")
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return nil, err
 	}
-	table := model.AssocTableName(c.ClusterName)
-
 	var rows []model.UserAssociation
-	q := c.DB.WithContext(ctx).Table(table).
+	q := cdb.Table(AssocTable).
 		Where("`user` = ? AND deleted = 0", username)
 	if err := q.Find(&rows).Error; err != nil {
 		return nil, err
@@ -555,13 +556,12 @@ func (c *Client) GetAssociation(ctx context.Context, account, user, partition st
 	if c == nil || c.DB == nil {
 		return nil, fmt.Errorf("nil slurmdb Client")
 	}
-	if strings.TrimSpace(c.ClusterName) == "" {
-		return nil, fmt.Errorf("cluster name is empty in slurmdb Client")
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return nil, err
 	}
-	table := model.AssocTableName(c.ClusterName)
 	var row model.UserAssociation
-	err := c.DB.WithContext(ctx).
-		Table(table).
+	err = cdb.Table(AssocTable).
 		Where("deleted = 0 AND acct = ? AND `user` = ? AND `partition` = ?", account, user, partition).
 		First(&row).Error
 	if err != nil {
@@ -570,6 +570,23 @@ func (c *Client) GetAssociation(ctx context.Context, account, user, partition st
 	return &row, nil
 }
 
+// GetAssociationPartitions 返回用户在账户下未删除的分区关联的分区名称, 按名称排序.
+func (c *Client) GetAssociationPartitions(ctx context.Context, account, user string) ([]string, error) {
+	if c == nil || c.DB == nil {
+		return nil, fmt.Errorf("nil slurmdb Client")
+	}
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]string, 0)
+	err = cdb.Table(AssocTable).
+		Where("deleted = 0 AND acct = ? AND `user` = ? AND `partition` <> ''", account, user).
+		Order("`partition`").
+		Pluck("`partition`", &parts).Error
+	return parts, err
+}
+
 // FindAssociationOne finds a single association in <ClusterName>_assoc_table by filters.
 // Required: account. Optional: user, partition. Always filters deleted=0.
 // Returns ErrMultipleAssociations if more than one row matches.
@@ -580,11 +597,11 @@ func (c *Client) FindAssociationOne(ctx context.Context, account string, user, p
 	if strings.TrimSpace(account) == "" {
 		return nil, fmt.Errorf("account is required")
 	}
-	if strings.TrimSpace(c.ClusterName) == "" {
-		return nil, fmt.Errorf("cluster name is empty in slurmdb Client")
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return nil, err
 	}
-	table := model.AssocTableName(c.ClusterName)
-	tx := c.DB.WithContext(ctx).Table(table).Where("deleted = 0 AND acct = ?", account)
+	tx := cdb.Table(AssocTable).Where("deleted = 0 AND acct = ?", account)
 	if user != nil && strings.TrimSpace(*user) != "" {
 		tx = tx.Where("`user` = ?", *user)
 	}
@@ -616,14 +633,12 @@ func (c *Client) GetUserNamesByAccount(ctx context.Context, account string) ([]s
 	if strings.TrimSpace(account) == "" {
 		return nil, fmt.Errorf("account name is required")
 	}
-	if strings.TrimSpace(c.ClusterName) == "" {
-		return nil, fmt.Errorf("cluster name is empty in slurmdb Client")
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return nil, err
 	}
-	table := fmt.Sprintf("%s_assoc_table", c.ClusterName)
-
 	var users []string
-	tx := c.DB.WithContext(ctx).
-		Table(table).
+	tx := cdb.Table(AssocTable).
 		Where("acct = ? AND `user` <> '' AND deleted = 0", account).
 		Distinct().
 		Pluck("`user`", &users)
@@ -633,7 +648,88 @@ func (c *Client) GetUserNamesByAccount(ctx context.Context, account string) ([]s
 	return users, nil
 }
 
-type JobsFilter struct{}
+// JobsFilter 作业查询过滤条件, 零值表示不过滤. 多值字段之间为或关系, 不同字段之间为与关系.
+type JobsFilter struct {
+	Users        []uint32 // 用户 UID(id_user)
+	Accounts     []string // 账户
+	Partitions   []string // 分区
+	States       []uint32 // 作业基本状态, 见 model.JobStateValue
+	Qos          []string // QoS 名称
+	SubmitAfter  int64    // 提交时间下界(Unix 秒, 含)
+	SubmitBefore int64    // 提交时间上界(Unix 秒, 含)
+	StartAfter   int64    // 开始时间下界(Unix 秒, 含)
+	StartBefore  int64    // 开始时间上界(Unix 秒, 含)
+	EndAfter     int64    // 结束时间下界(Unix 秒, 含)
+	EndBefore    int64    // 结束时间上界(Unix 秒, 含), 仅匹配已结束的作业
+	Node         string   // 节点名称或 hostlist 表达式, 匹配 nodelist 与之存在交集的作业
+	Name         string   // 作业名称, 支持 * 通配符
+	ExitCode     *uint32  // 退出码(exit_code 高 8 位)
+	ArrayJobID   uint32   // 数组作业 ID(id_array_job)
+	Reservation  string   // 预约名称
+	Wckeys       []string // WCKey 名称
+}
+
+// applyJobsFilter 将 filter 中除 Node 以外的条件下推到 <cluster>_job_table 查询.
+// 用户与时间条件可命中 sacct_def(id_user, time_start, time_end) 等索引, QoS 与预约按名称
+// 经子查询换算为 id_qos 与 id_resv, 以便使用对应索引.
+func (d *ClusterDB) applyJobsFilter(q *gorm.DB, filter JobsFilter) *gorm.DB {
+	if len(filter.Users) > 0 {
+		q = q.Where("id_user IN ?", filter.Users)
+	}
+	if len(filter.Accounts) > 0 {
+		q = q.Where("account IN ?", filter.Accounts)
+	}
+	if len(filter.Partitions) > 0 {
+		q = q.Where("`partition` IN ?", filter.Partitions)
+	}
+	if len(filter.States) > 0 {
+		// slurmdbd 在 state 列中只记录基本状态
+		q = q.Where("state IN ?", filter.States)
+	}
+	if len(filter.Wckeys) > 0 {
+		// 未显式指定 WCKey 的作业使用默认 WCKey, slurmdbd 记录为 "*" 加名称
+		names := make([]string, 0, 2*len(filter.Wckeys))
+		for _, w := range filter.Wckeys {
+			names = append(names, w, "*"+w)
+		}
+		q = q.Where("wckey IN ?", names)
+	}
+	if len(filter.Qos) > 0 {
+		q = q.Where("id_qos IN (?)", d.db.Table("qos_table").Select("id").Where("deleted = 0 AND name IN ?", filter.Qos))
+	}
+	for _, r := range []struct {
+		expr string
+		val  int64
+	}{
+		{"time_submit >= ?", filter.SubmitAfter},
+		{"time_submit <= ?", filter.SubmitBefore},
+		{"time_start >= ?", filter.StartAfter},
+		{"time_start > 0 AND time_start <= ?", filter.StartBefore},
+		{"time_end >= ?", filter.EndAfter},
+		{"time_end > 0 AND time_end <= ?", filter.EndBefore},
+	} {
+		if r.val > 0 {
+			q = q.Where(r.expr, r.val)
+		}
+	}
+	if name := strings.TrimSpace(filter.Name); name != "" {
+		if strings.Contains(name, "*") {
+			q = q.Where("job_name LIKE ?", strings.ReplaceAll(escapeLike(name), "*", "%"))
+		} else {
+			q = q.Where("job_name = ?", name)
+		}
+	}
+	if filter.ExitCode != nil {
+		q = q.Where("exit_code >> 8 = ?", *filter.ExitCode)
+	}
+	if filter.ArrayJobID > 0 {
+		q = q.Where("id_array_job = ?", filter.ArrayJobID)
+	}
+	if resv := strings.TrimSpace(filter.Reservation); resv != "" {
+		q = q.Where("id_resv IN (?)", d.Table(ResvTable).Select("id_resv").Where("deleted = 0 AND resv_name = ?", resv))
+	}
+	return q
+}
 
 // GetUserAdminLevels returns a map of username -> admin_level for the given usernames
 // from user_table, filtering deleted = 0. Unknown users are omitted from the map.
@@ -675,28 +771,61 @@ func (c *Client) GetUserAdminLevels(ctx context.Context, usernames []string) (ma
 	return out, nil
 }
 
+// MissingUsers 返回 names 中在 user_table 中不存在(或已删除)的用户, 保持 names 中的顺序.
+func (c *Client) MissingUsers(ctx context.Context, names []string) ([]string, error) {
+	return c.missingNames(ctx, &model.User{}, names)
+}
+
+// MissingAccounts 返回 names 中在 acct_table 中不存在(或已删除)的账户, 保持 names 中的顺序.
+func (c *Client) MissingAccounts(ctx context.Context, names []string) ([]string, error) {
+	return c.missingNames(ctx, &model.Account{}, names)
+}
+
+// missingNames 返回 names 中在 m 对应的表中没有 deleted = 0 记录的名称.
+func (c *Client) missingNames(ctx context.Context, m any, names []string) ([]string, error) {
+	if c == nil || c.DB == nil {
+		return nil, fmt.Errorf("nil slurmdb Client")
+	}
+	missing := make([]string, 0)
+	if len(names) == 0 {
+		return missing, nil
+	}
+	var found []string
+	if err := c.DB.WithContext(ctx).Model(m).Where("deleted = 0 AND name IN ?", names).Pluck("name", &found).Error; err != nil {
+		return nil, err
+	}
+	exists := make(map[string]bool, len(found))
+	for _, n := range found {
+		exists[n] = true
+	}
+	for _, n := range names {
+		if !exists[n] {
+			missing = append(missing, n)
+		}
+	}
+	return missing, nil
+}
+
 func (c *Client) GetAccoutingJobs(ctx context.Context, paging bool, page, page_size int64) {}
 
-func (c *Client) GetJobSteps(ctx context.Context, jobid int64) (model.Steps, error) {
+func (c *Client) GetJobSteps(ctx context.Context, id jobid.ID) (model.Steps, error) {
 	steps := make(model.Steps, 0)
 	if c == nil || c.DB == nil {
 		return steps, fmt.Errorf("nil slurmdb Client")
 	}
-	if strings.TrimSpace(c.ClusterName) == "" {
-		return steps, fmt.Errorf("cluster name is empty in slurmdb Client")
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return steps, err
 	}
-	if jobid <= 0 {
+	if id.JobID == 0 {
 		return steps, fmt.Errorf("invalid jobid")
 	}
 
-	jobTable := fmt.Sprintf("%s_job_table", c.ClusterName)
-	stepTable := fmt.Sprintf("%s_step_table", c.ClusterName)
-
 	// Join job and step tables by job_db_inx, filter by jobid and deleted=0, order by start/id
-	q := c.DB.WithContext(ctx).
-		Table(stepTable+" AS s").
-		Joins("JOIN "+jobTable+" AS j ON s.job_db_inx = j.job_db_inx").
-		Where("j.id_job = ? AND s.deleted = 0", jobid)
+	q := cdb.TableAs(StepTable, "s").
+		Joins("JOIN " + cdb.Name(JobTable) + " AS j ON s.job_db_inx = j.job_db_inx").
+		Where("s.deleted = 0")
+	q = whereJobID(q, "j.", id)
 	if err := q.Find(&steps).Error; err != nil {
 		return nil, err
 	}
@@ -704,22 +833,21 @@ func (c *Client) GetJobSteps(ctx context.Context, jobid int64) (model.Steps, err
 }
 
 // GetJobDetail 返回指定 jobid 的作业详情（来自 <cluster>_job_table），过滤 deleted=0。
-// 当 jobid 存在多行（如数组作业），返回最新记录（按 job_db_inx DESC）。
-func (c *Client) GetJobDetail(ctx context.Context, jobid int64) (*model.Job, error) {
+// 数组任务 123_7 与异构作业组件 123+1 定位到对应的行, 见 whereJobID; 数组作业的汇总与任务列表见
+// GetArraySummary 与 GetArrayTasks. 同一 jobid 存在多行（如重新排队）时返回最新记录（按 job_db_inx DESC）。
+func (c *Client) GetJobDetail(ctx context.Context, id jobid.ID) (*model.Job, error) {
 	if c == nil || c.DB == nil {
 		return nil, fmt.Errorf("nil slurmdb Client")
 	}
-	if strings.TrimSpace(c.ClusterName) == "" {
-		return nil, fmt.Errorf("cluster name is empty in slurmdb Client")
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return nil, err
 	}
-	if jobid <= 0 {
+	if id.JobID == 0 {
 		return nil, fmt.Errorf("invalid jobid")
 	}
-	table := fmt.Sprintf("%s_job_table", c.ClusterName)
 	var row model.Job
-	tx := c.DB.WithContext(ctx).
-		Table(table).
-		Where("id_job = ? AND deleted = 0", jobid).
+	tx := whereJobID(cdb.Table(JobTable).Where("deleted = 0"), "", id).
 		Order("job_db_inx DESC").
 		First(&row)
 	if tx.Error != nil {
@@ -728,14 +856,33 @@ func (c *Client) GetJobDetail(ctx context.Context, jobid int64) (*model.Job, err
 	return &row, nil
 }
 
-// GetJobsDetail 按 jobid 降序分页返回作业详情（deleted=0）。
-// page 从 1 开始；page_size > 0。内部按 id_job DESC 排序。
-func (c *Client) GetJobsDetail(ctx context.Context, page, pageSize int) (model.Jobs, int64, error) {
+// whereJobID 按作业 ID 过滤, 数组任务 123_7 按 id_array_job 与 id_array_task 匹配,
+// 异构作业组件 123+1 按 het_job_id 与 het_job_offset 匹配. prefix 为表别名前缀, 如 "j.".
+func whereJobID(q *gorm.DB, prefix string, id jobid.ID) *gorm.DB {
+	if id.IsHetComponent {
+		return q.Where(prefix+"het_job_id = ? AND "+prefix+"het_job_offset = ?", id.JobID, id.HetOffset)
+	}
+	if id.IsArrayTask {
+		return q.Where(prefix+"id_array_job = ? AND "+prefix+"id_array_task = ?", id.JobID, id.ArrayTask)
+	}
+	return q.Where(prefix+"id_job = ?", id.JobID)
+}
+
+// GetJobsDetail 按 jobid 降序分页返回满足 filter 的作业详情（deleted=0）。
+// page 从 1 开始；page_size > 0。内部按 id_job DESC 排序。过滤条件均在 SQL 中执行。
+// 当 filter.Node 非空时, 先按主机名在 SQL 中预筛选, 再分批展开 nodelist 精确匹配, 返回的总数只是下界, 见 getJobsDetailByNodes.
+func (c *Client) GetJobsDetail(ctx context.Context, filter JobsFilter, page, pageSize int) (model.Jobs, int64, error) {
+	return c.getJobsDetail(ctx, filter, jobsByID, page, pageSize)
+}
+
+// getJobsDetail 按 order 分页返回满足 filter 的作业详情.
+func (c *Client) getJobsDetail(ctx context.Context, filter JobsFilter, order jobOrder, page, pageSize int) (model.Jobs, int64, error) {
 	if c == nil || c.DB == nil {
 		return nil, 0, fmt.Errorf("nil slurmdb Client")
 	}
-	if strings.TrimSpace(c.ClusterName) == "" {
-		return nil, 0, fmt.Errorf("cluster name is empty in slurmdb Client")
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return nil, 0, err
 	}
 	if page < 1 {
 		page = 1
@@ -745,8 +892,11 @@ func (c *Client) GetJobsDetail(ctx context.Context, page, pageSize int) (model.J
 	}
 	offset := (page - 1) * pageSize
 
-	table := fmt.Sprintf("%s_job_table", c.ClusterName)
-	base := c.DB.WithContext(ctx).Table(table).Where("deleted = 0")
+	base := cdb.applyJobsFilter(cdb.Table(JobTable).Where("deleted = 0"), filter)
+
+	if node := strings.TrimSpace(filter.Node); node != "" {
+		return c.getJobsDetailByNodes(base, node, order, offset, pageSize)
+	}
 
 	var total int64
 	if err := base.Count(&total).Error; err != nil {
@@ -754,13 +904,143 @@ func (c *Client) GetJobsDetail(ctx context.Context, page, pageSize int) (model.J
 	}
 
 	var rows model.Jobs
-	q := base.Order("id_job DESC").Offset(offset).Limit(pageSize)
+	q := base.Order(order.clause()).Offset(offset).Limit(pageSize)
 	if err := q.Find(&rows).Error; err != nil {
 		return nil, 0, err
 	}
 	return rows, total, nil
 }
 
+// jobOrder 作业列表的排序列, 均为降序. 最后一列为主键 job_db_inx, 保证顺序唯一, 以便按键集分批读取.
+type jobOrder []string
+
+var (
+	jobsByID     = jobOrder{"id_job", "job_db_inx"}                // 按作业 ID 降序
+	jobsBySubmit = jobOrder{"time_submit", "id_job", "job_db_inx"} // 按提交时间降序, 用于跨集群合并
+)
+
+// clause 返回 ORDER BY 子句.
+func (o jobOrder) clause() string {
+	cols := make([]string, 0, len(o))
+	for _, col := range o {
+		cols = append(cols, col+" DESC")
+	}
+	return strings.Join(cols, ", ")
+}
+
+// after 返回排在 j 之后的行的条件.
+func (o jobOrder) after(q *gorm.DB, j model.Job) *gorm.DB {
+	vals := make([]any, 0, len(o))
+	for _, col := range o {
+		switch col {
+		case "id_job":
+			vals = append(vals, j.IDJob)
+		case "time_submit":
+			vals = append(vals, j.TimeSubmit)
+		case "job_db_inx":
+			vals = append(vals, j.JobDBInx)
+		}
+	}
+	return q.Where("("+strings.Join(o, ", ")+") < ?", vals)
+}
+
+const (
+	nodeScanBatch         = 2000   // 按节点过滤时每批读取的候选作业数
+	nodeScanMaxCandidates = 200000 // 按节点过滤时最多读取的候选作业数
+)
+
+// getJobsDetailByNodes 返回 nodelist 与 expr 存在交集的作业. nodelist 为压缩后的 hostlist,
+// 无法直接在 SQL 中精确匹配, 因此按主机名在 SQL 中预筛选, 再按 order 以键集分批读取候选作业的
+// nodelist 展开比较. 每批只读取排序列与 nodelist, 只保留当前页的作业 ID, 最后读取当前页的完整记录.
+// 当前页填满并确认还有下一条匹配, 或读取的候选作业达到 nodeScanMaxCandidates 时停止扫描,
+// 此时返回的总数是已匹配的作业数, 只是下界, 但足以判断是否存在下一页.
+func (c *Client) getJobsDetailByNodes(base *gorm.DB, expr string, order jobOrder, offset, limit int) (model.Jobs, int64, error) {
+	cond, want, err := c.nodeFilter(expr)
+	if err != nil {
+		return nil, 0, err
+	}
+	base = base.Where(cond).Session(&gorm.Session{})
+
+	var (
+		total   int64
+		scanned int
+		ids     []uint64
+		last    *model.Job
+	)
+scan:
+	for scanned < nodeScanMaxCandidates {
+		q := base.Select(strings.Join(order, ", ") + ", nodelist").Order(order.clause()).Limit(nodeScanBatch)
+		if last != nil {
+			q = order.after(q, *last)
+		}
+		var batch model.Jobs
+		if err := q.Find(&batch).Error; err != nil {
+			return nil, 0, err
+		}
+		scanned += len(batch)
+		for _, j := range filterJobsByNodes(batch, want) {
+			total++
+			if total > int64(offset+limit) {
+				break scan
+			}
+			if total > int64(offset) {
+				ids = append(ids, j.JobDBInx)
+			}
+		}
+		if len(batch) < nodeScanBatch {
+			break
+		}
+		last = &batch[len(batch)-1]
+	}
+
+	rows := make(model.Jobs, 0, len(ids))
+	if len(ids) == 0 {
+		return rows, total, nil
+	}
+	if err := base.Where("job_db_inx IN ?", ids).Order(order.clause()).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+// nodeFilter 展开 expr, 返回按主机名前缀预筛选 nodelist 的 SQL 条件与展开后的节点集合.
+// 节点可能出现在 nodelist 的任意位置(如 gpu01,cn05), 因此前缀按子串匹配, 结果需经 filterJobsByNodes 精确比较.
+func (c *Client) nodeFilter(expr string) (*gorm.DB, map[string]struct{}, error) {
+	hosts, err := hostlist.Expand(expr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid node filter: %w", err)
+	}
+	want := make(map[string]struct{}, len(hosts))
+	prefixes := make(map[string]struct{})
+	for _, h := range hosts {
+		want[h] = struct{}{}
+		prefixes[strings.TrimRight(h, "0123456789")] = struct{}{}
+	}
+
+	cond := c.DB.Where("1 = 0")
+	for p := range prefixes {
+		cond = cond.Or("nodelist LIKE ?", "%"+escapeLike(p)+"%")
+	}
+	return cond, want, nil
+}
+
+// filterJobsByNodes 返回 nodelist 与 want 存在交集的作业.
+func filterJobsByNodes(jobs model.Jobs, want map[string]struct{}) model.Jobs {
+	matched := make(model.Jobs, 0)
+	for _, job := range jobs {
+		if ok, _ := hostlist.Intersects(job.Nodelist, want); ok {
+			matched = append(matched, job)
+		}
+	}
+	return matched
+}
+
+// escapeLike 转义 LIKE 模式中的通配符.
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return r.Replace(s)
+}
+
 // GetQos 根据 ID 获取单个 QoS（deleted=0）。
 func (c *Client) GetQos(ctx context.Context, id int) (*model.Qos, error) {
 	if c == nil || c.DB == nil {
@@ -805,3 +1085,375 @@ func (c *Client) GetQosAll(ctx context.Context, paging bool, page, pageSize int)
 	}
 	return rows, total, nil
 }
+
+// ReservationsFilter 历史预约查询条件, 零值表示不过滤.
+type ReservationsFilter struct {
+	Name  string // 预约名称
+	Since int64  // 仅返回结束时间不早于该时间的预约(Unix 秒)
+	Until int64  // 仅返回开始时间不晚于该时间的预约(Unix 秒)
+}
+
+// GetReservations 查询 <cluster>_resv_table 中的历史预约, 并关联 <cluster>_job_table 统计预约期间运行的作业.
+// 结果按开始时间降序分页返回.
+func (c *Client) GetReservations(ctx context.Context, filter ReservationsFilter, page, pageSize int) (model.Reservations, int64, error) {
+	if c == nil || c.DB == nil {
+		return nil, 0, fmt.Errorf("nil slurmdb Client")
+	}
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	offset := (page - 1) * pageSize
+
+	base := cdb.TableAs(ResvTable, "r").Where("r.deleted = 0")
+	if name := strings.TrimSpace(filter.Name); name != "" {
+		base = base.Where("r.resv_name = ?", name)
+	}
+	if filter.Since > 0 {
+		base = base.Where("(r.time_end = 0 OR r.time_end >= ?)", filter.Since)
+	}
+	if filter.Until > 0 {
+		base = base.Where("r.time_start <= ?", filter.Until)
+	}
+
+	var total int64
+	if err := base.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 作业与预约通过 id_resv 关联, id_resv 会在预约修改后沿用, 因此以作业开始时间限定在预约窗口内.
+	// 运行时长按预约窗口截断, 未结束的作业以当前时间计.
+	rows := make(model.Reservations, 0)
+	q := base.
+		Select("r.*, COUNT(j.job_db_inx) AS job_count, " +
+			"COALESCE(SUM(GREATEST(0, CAST(LEAST(IF(j.time_end = 0, UNIX_TIMESTAMP(), j.time_end), r.time_end) AS SIGNED) - CAST(GREATEST(j.time_start, r.time_start) AS SIGNED))), 0) AS job_wall_seconds").
+		Joins("LEFT JOIN " + cdb.Name(JobTable) + " AS j ON j.id_resv = r.id_resv AND j.deleted = 0 AND j.time_start > 0 " +
+			"AND j.time_start >= r.time_start AND j.time_start < r.time_end").
+		Group("r.id_resv, r.time_start").
+		Order("r.time_start DESC").
+		Offset(offset).Limit(pageSize)
+	if err := q.Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	for i := range rows {
+		rows[i].FillUsage()
+	}
+	return rows, total, nil
+}
+
+// GetTresTable 返回 tres_table 中所有 TRES 定义, 用于解码 TRES 字符串. 已删除的 TRES 仍可能出现在历史作业中, 因此一并返回.
+func (c *Client) GetTresTable(ctx context.Context) (model.TresTable, error) {
+	if c == nil || c.DB == nil {
+		return nil, fmt.Errorf("nil slurmdb Client")
+	}
+	var rows []model.Tres
+	if err := c.DB.WithContext(ctx).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make(model.TresTable, len(rows))
+	for _, r := range rows {
+		out[r.ID] = r
+	}
+	return out, nil
+}
+
+// GetQosByName 按名称查询未删除的 QoS.
+func (c *Client) GetQosByName(ctx context.Context, name string) (*model.Qos, error) {
+	if c == nil || c.DB == nil {
+		return nil, fmt.Errorf("nil slurmdb Client")
+	}
+
+	var row model.Qos
+	tx := c.DB.WithContext(ctx).Model(&model.Qos{}).Where("deleted = 0 AND name = ?", name).First(&row)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return &row, nil
+}
+
+// GetArrayTasks 分页返回数组作业的任务, 同一任务被重新排队时只返回最新的记录, 按任务 ID 升序排列.
+// 尚未开始的任务合并在 id_array_task 为 model.NoArrayTask 的一条记录中, 由 array_task_str 描述.
+func (c *Client) GetArrayTasks(ctx context.Context, arrayJobID uint32, page, pageSize int) (model.Jobs, int64, error) {
+	if c == nil || c.DB == nil {
+		return nil, 0, fmt.Errorf("nil slurmdb Client")
+	}
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	offset := (page - 1) * pageSize
+
+	base := cdb.latestArrayTasks(arrayJobID)
+
+	var total int64
+	if err := base.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rows model.Jobs
+	if err := base.Order("id_array_task ASC").Offset(offset).Limit(pageSize).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+// GetArraySummary 按状态统计数组作业的任务数量, 尚未开始的任务按 array_task_pending 计入 PENDING.
+func (c *Client) GetArraySummary(ctx context.Context, arrayJobID uint32) (*model.ArraySummary, error) {
+	if c == nil || c.DB == nil {
+		return nil, fmt.Errorf("nil slurmdb Client")
+	}
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		State            uint32
+		IDArrayTask      uint32
+		ArrayTaskPending uint32
+	}
+	if err := cdb.latestArrayTasks(arrayJobID).
+		Select("state, id_array_task, array_task_pending").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	sum := &model.ArraySummary{ArrayJobID: arrayJobID, States: make(map[string]int64)}
+	for _, r := range rows {
+		n := int64(1)
+		if r.IDArrayTask == model.NoArrayTask {
+			n = int64(r.ArrayTaskPending)
+		}
+		sum.Tasks += n
+		sum.States[model.JobStateName(r.State)] += n
+	}
+	return sum, nil
+}
+
+// latestArrayTasks 返回数组作业每个任务最新记录的查询.
+func (d *ClusterDB) latestArrayTasks(arrayJobID uint32) *gorm.DB {
+	latest := d.Table(JobTable).
+		Select("MAX(job_db_inx)").
+		Where("id_array_job = ? AND deleted = 0", arrayJobID).
+		Group("id_array_task")
+	return d.Table(JobTable).Where("job_db_inx IN (?)", latest)
+}
+
+// GetHetJob 返回异构作业的各组件及其作业步, 同一组件被重新排队时只取最新记录. id 为组件形式 1234+1 时
+// 只返回该组件; 非异构作业作为只有一个组件的异构作业返回. 作业不存在时返回 gorm.ErrRecordNotFound.
+func (c *Client) GetHetJob(ctx context.Context, id jobid.ID) (*model.HetJob, error) {
+	if c == nil || c.DB == nil {
+		return nil, fmt.Errorf("nil slurmdb Client")
+	}
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if id.JobID == 0 || id.IsArrayTask {
+		return nil, fmt.Errorf("invalid het job id")
+	}
+
+	latest := cdb.Table(JobTable).
+		Select("MAX(job_db_inx)").
+		Where("het_job_id = ? AND deleted = 0", id.JobID).
+		Group("het_job_offset")
+	q := cdb.Table(JobTable).Where("job_db_inx IN (?)", latest)
+	if id.IsHetComponent {
+		q = q.Where("het_job_offset = ?", id.HetOffset)
+	}
+	var jobs model.Jobs
+	if err := q.Order("het_job_offset ASC").Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 && !id.IsHetComponent {
+		// 非异构作业
+		job, err := c.GetJobDetail(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		jobs = model.Jobs{*job}
+	}
+	if len(jobs) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	steps, err := c.stepsOfJobs(ctx, jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	het := &model.HetJob{HetJobID: id.JobID, Components: make([]model.HetJobComponent, 0, len(jobs))}
+	for _, j := range jobs {
+		comp := model.HetJobComponent{Job: j, Steps: steps[j.JobDBInx]}
+		if comp.Steps == nil {
+			comp.Steps = make(model.Steps, 0)
+		}
+		het.Components = append(het.Components, comp)
+	}
+	return het, nil
+}
+
+// stepsOfJobs 查询 jobs 的作业步, 按 job_db_inx 分组返回.
+func (c *Client) stepsOfJobs(ctx context.Context, jobs model.Jobs) (map[uint64]model.Steps, error) {
+	out := make(map[uint64]model.Steps, len(jobs))
+	if len(jobs) == 0 {
+		return out, nil
+	}
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	inx := make([]uint64, 0, len(jobs))
+	for _, j := range jobs {
+		inx = append(inx, j.JobDBInx)
+	}
+	var steps model.Steps
+	if err := cdb.Table(StepTable).
+		Where("job_db_inx IN ? AND deleted = 0", inx).
+		Order("id_step ASC").
+		Find(&steps).Error; err != nil {
+		return nil, err
+	}
+	for _, st := range steps {
+		out[st.JobDBInx] = append(out[st.JobDBInx], st)
+	}
+	return out, nil
+}
+
+// GetJobEfficiency 计算作业的资源使用效率. id 为数组作业 ID 时计算每个任务(最新记录)的效率,
+// 尚未开始的任务不计入; 其余情况返回单个作业的效率.
+func (c *Client) GetJobEfficiency(ctx context.Context, id jobid.ID) ([]model.JobEfficiency, error) {
+	job, err := c.GetJobDetail(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	jobs := model.Jobs{*job}
+	if !id.IsArrayTask && !id.IsHetComponent && job.IDArrayJob != 0 && job.IDArrayJob == job.IDJob {
+		cdb, err := c.clusterDB(ctx)
+		if err != nil {
+			return nil, err
+		}
+		jobs = nil
+		if err := cdb.latestArrayTasks(job.IDArrayJob).
+			Where("id_array_task <> ?", model.NoArrayTask).
+			Order("id_array_task ASC").
+			Find(&jobs).Error; err != nil {
+			return nil, err
+		}
+	}
+	return c.computeEfficiency(ctx, jobs)
+}
+
+// EfficiencyQuery 批量效率查询条件.
+type EfficiencyQuery struct {
+	Filter     JobsFilter // 作业过滤条件, 仅统计已结束的作业
+	MinElapsed int64      // 最短运行时长(秒), 运行时间过短的作业效率没有参考意义
+	SortBy     string     // 排序指标: cpu、mem、gpu 或 wall, 默认 cpu
+	Limit      int        // 返回数量
+}
+
+// maxEfficiencyCandidates 批量效率查询中参与计算的最近作业数量上限.
+const maxEfficiencyCandidates = 2000
+
+// GetLeastEfficientJobs 返回满足条件的已结束作业中效率最低的 Limit 个, 无法计算所选指标的作业不参与排序.
+// 效率需要结合作业步计算, 因此只在最近结束的 maxEfficiencyCandidates 个作业中排序.
+func (c *Client) GetLeastEfficientJobs(ctx context.Context, query EfficiencyQuery) ([]model.JobEfficiency, error) {
+	if c == nil || c.DB == nil {
+		return nil, fmt.Errorf("nil slurmdb Client")
+	}
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metric, err := efficiencyMetric(query.SortBy)
+	if err != nil {
+		return nil, err
+	}
+	if query.Limit <= 0 {
+		query.Limit = 20
+	}
+
+	q := cdb.applyJobsFilter(cdb.Table(JobTable).Where("deleted = 0"), query.Filter).
+		Where("time_start > 0 AND time_end > 0")
+	if query.MinElapsed > 0 {
+		q = q.Where("CAST(time_end AS SIGNED) - CAST(time_start AS SIGNED) - CAST(time_suspended AS SIGNED) >= ?", query.MinElapsed)
+	}
+	var want map[string]struct{}
+	if node := strings.TrimSpace(query.Filter.Node); node != "" {
+		var cond *gorm.DB
+		if cond, want, err = c.nodeFilter(node); err != nil {
+			return nil, err
+		}
+		q = q.Where(cond)
+	}
+	var jobs model.Jobs
+	if err := q.Order("time_end DESC").Limit(maxEfficiencyCandidates).Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	if want != nil {
+		jobs = filterJobsByNodes(jobs, want)
+	}
+
+	all, err := c.computeEfficiency(ctx, jobs)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.JobEfficiency, 0, len(all))
+	for _, e := range all {
+		if metric(e) != nil {
+			out = append(out, e)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return *metric(out[i]) < *metric(out[j]) })
+	if len(out) > query.Limit {
+		out = out[:query.Limit]
+	}
+	return out, nil
+}
+
+// efficiencyMetric 返回排序指标的取值函数.
+func efficiencyMetric(name string) (func(model.JobEfficiency) *float64, error) {
+	switch name {
+	case "", "cpu":
+		return func(e model.JobEfficiency) *float64 { return e.CPUEfficiency }, nil
+	case "mem":
+		return func(e model.JobEfficiency) *float64 { return e.MemEfficiency }, nil
+	case "gpu":
+		return func(e model.JobEfficiency) *float64 { return e.GPUUtil }, nil
+	case "wall":
+		return func(e model.JobEfficiency) *float64 { return e.WallUsage }, nil
+	}
+	return nil, fmt.Errorf("invalid sort metric: %s", name)
+}
+
+// computeEfficiency 查询作业步并计算每个作业的效率.
+func (c *Client) computeEfficiency(ctx context.Context, jobs model.Jobs) ([]model.JobEfficiency, error) {
+	tres, err := c.GetTresTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	steps, err := c.stepsOfJobs(ctx, jobs)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	out := make([]model.JobEfficiency, 0, len(jobs))
+	for _, j := range jobs {
+		out = append(out, model.ComputeEfficiency(j, steps[j.JobDBInx], tres, now))
+	}
+	return out, nil
+}
@@ -0,0 +1,178 @@
+package slurmdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+
+	"solid/internal/pkg/model"
+)
+
+// ErrInvalidCluster 集群名称格式不合法, 不能用作表名前缀.
+var ErrInvalidCluster = errors.New("invalid cluster name")
+
+// clusterNameRe slurm 集群名称: 以字母或数字开头, 只包含字母、数字、下划线与连字符.
+var clusterNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// maxClusterNameLen 集群名称最大长度. MySQL 标识符最长 64 字符, 最长的表名后缀 _assoc_usage_month_table 为 24 字符.
+const maxClusterNameLen = 40
+
+// clusterCacheTTL 已知集群列表的缓存时间, 过期后重新读取 cluster_table. 有效期内未知的名称直接判定为不存在,
+// 新增的集群最迟在缓存过期后可见.
+const clusterCacheTTL = time.Minute
+
+// Table 集群专属的表, 实际表名为 <cluster>_<Table>.
+type Table string
+
+const (
+	AssocTable Table = "assoc_table"
+	JobTable   Table = "job_table"
+	StepTable  Table = "step_table"
+	ResvTable  Table = "resv_table"
+	EventTable Table = "event_table"
+	WckeyTable Table = "wckey_table"
+)
+
+// UsageTable 返回集群使用量汇总表 <cluster>_usage_<granularity>_table, granularity 为 hour、day 或 month.
+func UsageTable(granularity string) Table { return Table("usage_" + granularity + "_table") }
+
+// AssocUsageTable 返回关联使用量汇总表 <cluster>_assoc_usage_<granularity>_table.
+func AssocUsageTable(granularity string) Table {
+	return Table("assoc_usage_" + granularity + "_table")
+}
+
+// WckeyUsageTable 返回 WCKey 使用量汇总表 <cluster>_wckey_usage_<granularity>_table.
+func WckeyUsageTable(granularity string) Table {
+	return Table("wckey_usage_" + granularity + "_table")
+}
+
+// ValidateClusterName 检查 name 是否可以安全地用作表名前缀.
+func ValidateClusterName(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: empty", ErrInvalidCluster)
+	}
+	if len(name) > maxClusterNameLen || !clusterNameRe.MatchString(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidCluster, name)
+	}
+	return nil
+}
+
+// quoteIdent 以反引号引用 MySQL 标识符.
+func quoteIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// ClusterDB 某个集群的查询作用域. 只能由 Client.ForCluster 构造, 构造时集群名称已通过格式与 cluster_table 校验,
+// 所有 <cluster>_* 表的查询都应经由 ClusterDB 取得表名, 不要自行拼接.
+type ClusterDB struct {
+	db      *gorm.DB
+	cluster string
+}
+
+// Cluster 返回集群名称.
+func (d *ClusterDB) Cluster() string { return d.cluster }
+
+// Name 返回表 t 引用后的完整表名, 用于 JOIN 与子查询.
+func (d *ClusterDB) Name(t Table) string { return quoteIdent(d.cluster + "_" + string(t)) }
+
+// Table 返回以表 t 为主表的查询.
+func (d *ClusterDB) Table(t Table) *gorm.DB { return d.db.Table(d.Name(t)) }
+
+// TableAs 返回以表 t 为主表并使用别名 alias 的查询.
+func (d *ClusterDB) TableAs(t Table, alias string) *gorm.DB {
+	return d.db.Table(d.Name(t) + " AS " + alias)
+}
+
+// ForCluster 返回集群 name 的查询作用域. name 必须符合集群命名规则, 并且是 cluster_table 中未删除的集群
+// (配置了集群列表时还必须在列表中); 格式不合法时返回 ErrInvalidCluster, 不存在时返回 ErrUnknownCluster.
+func (c *Client) ForCluster(ctx context.Context, name string) (*ClusterDB, error) {
+	if c == nil || c.DB == nil {
+		return nil, fmt.Errorf("nil slurmdb Client")
+	}
+	if err := ValidateClusterName(name); err != nil {
+		return nil, err
+	}
+	ok, err := c.knownCluster(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCluster, name)
+	}
+	return &ClusterDB{db: c.DB.WithContext(ctx), cluster: name}, nil
+}
+
+// clusterDB 返回客户端当前集群的查询作用域.
+func (c *Client) clusterDB(ctx context.Context) (*ClusterDB, error) {
+	if c == nil || c.DB == nil {
+		return nil, fmt.Errorf("nil slurmdb Client")
+	}
+	return c.ForCluster(ctx, c.ClusterName)
+}
+
+// clusterCache 已知集群名称的缓存, 由同一连接池的客户端副本共享.
+type clusterCache struct {
+	mu     sync.Mutex
+	names  map[string]struct{}
+	loaded time.Time
+	group  singleflight.Group // 合并并发的刷新, 刷新期间不持有 mu
+}
+
+// knownCluster 判断 name 是否为可访问的集群. 缓存有效期内直接按缓存的集群列表判断(包括不存在的名称),
+// 过期后重新读取 cluster_table, 并发的刷新只执行一次查询.
+func (c *Client) knownCluster(ctx context.Context, name string) (bool, error) {
+	cache := c.known
+	if cache == nil {
+		return c.lookupCluster(ctx, name)
+	}
+	cache.mu.Lock()
+	names := cache.names
+	if time.Since(cache.loaded) >= clusterCacheTTL {
+		names = nil
+	}
+	cache.mu.Unlock()
+
+	if names == nil {
+		// 查询由多个请求共享, 不随发起请求的取消而中断
+		v, err, _ := cache.group.Do("clusters", func() (any, error) {
+			clusters, err := c.GetClusters(context.WithoutCancel(ctx))
+			if err != nil {
+				return nil, err
+			}
+			loaded := make(map[string]struct{}, len(clusters))
+			for _, cl := range clusters {
+				loaded[cl.Name] = struct{}{}
+			}
+			cache.mu.Lock()
+			cache.names, cache.loaded = loaded, time.Now()
+			cache.mu.Unlock()
+			return loaded, nil
+		})
+		if err != nil {
+			return false, err
+		}
+		names = v.(map[string]struct{})
+	}
+	_, ok := names[name]
+	return ok, nil
+}
+
+// lookupCluster 不使用缓存, 直接在 cluster_table 中查找 name.
+func (c *Client) lookupCluster(ctx context.Context, name string) (bool, error) {
+	if len(c.clusters) > 0 && !slices.Contains(c.clusters, name) {
+		return false, nil
+	}
+	var n int64
+	if err := c.DB.WithContext(ctx).Model(&model.Cluster{}).Where("deleted = 0 AND name = ?", name).Count(&n).Error; err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
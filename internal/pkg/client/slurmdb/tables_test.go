@@ -0,0 +1,156 @@
+package slurmdb
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"solid/internal/pkg/model"
+)
+
+// dryRunDB 返回只生成 SQL、不连接数据库的 gorm.DB.
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "u:p@tcp(127.0.0.1:1)/slurm_acct_db", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	return db
+}
+
+func TestValidateClusterName(t *testing.T) {
+	for _, name := range []string{"linux", "hpc01", "gpu_cluster", "a-b", "Cluster1", strings.Repeat("c", maxClusterNameLen)} {
+		if err := ValidateClusterName(name); err != nil {
+			t.Errorf("ValidateClusterName(%q) error = %v", name, err)
+		}
+	}
+	bad := []string{
+		"",
+		" linux",
+		"linux ",
+		"_linux",
+		"-linux",
+		"linux;DROP TABLE acct_table",
+		"linux_job_table` UNION SELECT 1 -- ",
+		"linux`",
+		"linux.job",
+		"linux/**/",
+		"linux'",
+		"集群",
+		"linux\x00",
+		strings.Repeat("c", maxClusterNameLen+1),
+	}
+	for _, name := range bad {
+		if err := ValidateClusterName(name); !errors.Is(err, ErrInvalidCluster) {
+			t.Errorf("ValidateClusterName(%q) error = %v, want ErrInvalidCluster", name, err)
+		}
+	}
+}
+
+func TestForClusterRejectsMalformedNames(t *testing.T) {
+	c := &Client{DB: dryRunDB(t), ClusterName: "linux"}
+	ctx := context.Background()
+	for _, name := range []string{"linux`", "linux; DELETE FROM acct_table", "x AS y JOIN acct_table"} {
+		if _, err := c.ForCluster(ctx, name); !errors.Is(err, ErrInvalidCluster) {
+			t.Errorf("ForCluster(%q) error = %v, want ErrInvalidCluster", name, err)
+		}
+		if _, err := c.WithCluster(ctx, name); !errors.Is(err, ErrInvalidCluster) {
+			t.Errorf("WithCluster(%q) error = %v, want ErrInvalidCluster", name, err)
+		}
+	}
+	// 配置的集群名称同样经过校验
+	for _, name := range []string{"", "linux_job_table` UNION SELECT 1 -- "} {
+		bad := &Client{DB: c.DB, ClusterName: name}
+		if _, _, err := bad.GetJobsDetail(ctx, JobsFilter{}, 1, 20); !errors.Is(err, ErrInvalidCluster) {
+			t.Errorf("GetJobsDetail() with cluster %q error = %v, want ErrInvalidCluster", name, err)
+		}
+	}
+	// 格式合法但不在允许的集群列表中
+	c = &Client{DB: dryRunDB(t), ClusterName: "linux", clusters: []string{"linux"}}
+	if _, err := c.ForCluster(ctx, "other"); !errors.Is(err, ErrUnknownCluster) {
+		t.Errorf("ForCluster(other) error = %v, want ErrUnknownCluster", err)
+	}
+}
+
+func TestClusterDBQuotesTables(t *testing.T) {
+	d := &ClusterDB{db: dryRunDB(t), cluster: "hpc-a"}
+	if got, want := d.Name(JobTable), "`hpc-a_job_table`"; got != want {
+		t.Errorf("Name(JobTable) = %s, want %s", got, want)
+	}
+	if got, want := d.Name(AssocUsageTable("day")), "`hpc-a_assoc_usage_day_table`"; got != want {
+		t.Errorf("Name(AssocUsageTable(day)) = %s, want %s", got, want)
+	}
+	if got, want := quoteIdent("a`b"), "`a``b`"; got != want {
+		t.Errorf("quoteIdent() = %s, want %s", got, want)
+	}
+
+	var rows []map[string]any
+	stmt := d.TableAs(StepTable, "s").
+		Joins("JOIN "+d.Name(JobTable)+" AS j ON s.job_db_inx = j.job_db_inx").
+		Where("j.id_job = ?", 1).
+		Find(&rows).Statement
+	sql := stmt.SQL.String()
+	if !strings.Contains(sql, "FROM `hpc-a_step_table` AS s JOIN `hpc-a_job_table` AS j") {
+		t.Errorf("generated SQL = %s", sql)
+	}
+}
+
+func TestApplyJobsFilterWckey(t *testing.T) {
+	d := &ClusterDB{db: dryRunDB(t), cluster: "linux"}
+	var rows []map[string]any
+	stmt := d.applyJobsFilter(d.Table(JobTable), JobsFilter{Wckeys: []string{"proj"}}).Find(&rows).Statement
+	// 默认 WCKey 在 job_table 中记录为 "*proj"
+	if sql := stmt.SQL.String(); !strings.Contains(sql, "wckey IN (?,?)") || len(stmt.Vars) != 2 || stmt.Vars[1] != "*proj" {
+		t.Errorf("generated SQL = %s, vars = %v", sql, stmt.Vars)
+	}
+}
+
+func TestNodeFilter(t *testing.T) {
+	c := &Client{DB: dryRunDB(t), ClusterName: "linux"}
+	d := &ClusterDB{db: c.DB, cluster: "linux"}
+	cond, want, err := c.nodeFilter("cn05")
+	if err != nil {
+		t.Fatalf("nodeFilter() error = %v", err)
+	}
+	var rows model.Jobs
+	stmt := jobsByID.after(d.Table(JobTable).Where(cond), model.Job{IDJob: 7, JobDBInx: 9}).Find(&rows).Statement
+	sql := stmt.SQL.String()
+	// 节点不一定排在 nodelist 首位, 前缀按子串匹配
+	if !strings.Contains(sql, "nodelist LIKE ?") || stmt.Vars[0] != "%cn%" || !strings.Contains(sql, "(id_job, job_db_inx) < (?,?)") {
+		t.Errorf("generated SQL = %s, vars = %v", sql, stmt.Vars)
+	}
+
+	jobs := model.Jobs{{IDJob: 1, Nodelist: "gpu01,cn05"}, {IDJob: 2, Nodelist: "cn[01-04]"}, {IDJob: 3, Nodelist: "gpu[01-02],cn[04-06]"}}
+	got := filterJobsByNodes(jobs, want)
+	if len(got) != 2 || got[0].IDJob != 1 || got[1].IDJob != 3 {
+		t.Errorf("filterJobsByNodes() = %+v", got)
+	}
+}
+
+func TestKnownClusterCachesNegativeResults(t *testing.T) {
+	db := dryRunDB(t)
+	queries := 0
+	if err := db.Callback().Query().Before("gorm:query").Register("count_queries", func(*gorm.DB) { queries++ }); err != nil {
+		t.Fatal(err)
+	}
+	c := &Client{DB: db, ClusterName: "linux", known: &clusterCache{}}
+	ctx := context.Background()
+	for _, name := range []string{"random1", "random2", "linux"} {
+		if ok, err := c.knownCluster(ctx, name); err != nil || ok {
+			t.Errorf("knownCluster(%q) = %v, %v", name, ok, err)
+		}
+	}
+	if queries != 1 {
+		t.Errorf("cluster_table queried %d times, want 1", queries)
+	}
+	c.known.loaded = time.Now().Add(-clusterCacheTTL)
+	if _, err := c.knownCluster(ctx, "random1"); err != nil || queries != 2 {
+		t.Errorf("expired cache: queries = %d, err = %v", queries, err)
+	}
+}
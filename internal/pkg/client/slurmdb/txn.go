@@ -0,0 +1,108 @@
+package slurmdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"solid/internal/pkg/model"
+)
+
+// TxnFilter txn_table 查询条件, 各项之间为与关系.
+type TxnFilter struct {
+	Actors   []string // 执行变更的用户
+	Actions  []uint16 // 变更类型, 见 model.TxnActionValue
+	Accounts []string // 变更对象涉及的账户, 包括账户本身及其下的关联
+	Users    []string // 变更对象涉及的用户, 包括用户本身及其关联
+	Start    int64    // 变更时间下界(Unix 秒, 含)
+	End      int64    // 变更时间上界(Unix 秒, 不含)
+}
+
+// GetTxns 按变更时间降序分页返回 txn_table 中满足 filter 的记录, 只包含全局对象与当前集群的变更, 并解码可读描述.
+func (c *Client) GetTxns(ctx context.Context, filter TxnFilter, page, pageSize int) (model.Txns, int64, error) {
+	if c == nil || c.DB == nil {
+		return nil, 0, fmt.Errorf("nil slurmdb Client")
+	}
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	q := c.DB.WithContext(ctx).Model(&model.Txn{}).Where("cluster IN ?", []string{"", cdb.Cluster()})
+	if len(filter.Actors) > 0 {
+		q = q.Where("actor IN ?", filter.Actors)
+	}
+	if len(filter.Actions) > 0 {
+		q = q.Where("action IN ?", filter.Actions)
+	}
+	if filter.Start > 0 {
+		q = q.Where("timestamp >= ?", filter.Start)
+	}
+	if filter.End > 0 {
+		q = q.Where("timestamp < ?", filter.End)
+	}
+	for _, t := range []struct {
+		column string // 关联表中的列
+		key    string // info 中的列名
+		names  []string
+	}{
+		{"acct", "acct", filter.Accounts},
+		{"`user`", "user", filter.Users},
+	} {
+		if len(t.names) == 0 {
+			continue
+		}
+		cond, err := cdb.txnTargetCond(t.column, t.key, t.names)
+		if err != nil {
+			return nil, 0, err
+		}
+		q = q.Where(cond)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	rows := make(model.Txns, 0)
+	if err := q.Order("timestamp DESC, id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	for i := range rows {
+		rows[i].Decode()
+	}
+	return rows, total, nil
+}
+
+// txnTargetCond 返回匹配涉及 names 的变更的条件. slurmdbd 记录变更对象的方式因类型而异:
+// 新增账户与用户时 name 为对象名称, 修改与删除时 name 为 (name='a' || ...) 形式的条件,
+// 新增关联时 info 中记录 acct='a', user='u', 修改与删除关联时 name 只有 id_assoc, 需要经关联表换算.
+// 关联表包括已删除的关联, 以便匹配删除记录.
+func (d *ClusterDB) txnTargetCond(column, key string, names []string) (*gorm.DB, error) {
+	cond := d.db.Where("name IN ?", names)
+	for _, n := range names {
+		cond = cond.
+			Or("name LIKE ?", "%name='"+escapeLike(n)+"'%").
+			Or("info LIKE ?", "%"+key+"='"+escapeLike(n)+"'%")
+	}
+
+	var ids []uint32
+	if err := d.Table(AssocTable).Where(column+" IN ?", names).Pluck("id_assoc", &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) > 0 {
+		parts := make([]string, 0, len(ids))
+		for _, id := range ids {
+			parts = append(parts, fmt.Sprint(id))
+		}
+		cond = cond.Or("cluster = ? AND name REGEXP ?", d.cluster, "id_assoc=("+strings.Join(parts, "|")+")([^0-9]|$)")
+	}
+	return cond, nil
+}
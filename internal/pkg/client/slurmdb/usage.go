@@ -0,0 +1,391 @@
+package slurmdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"solid/internal/pkg/model"
+)
+
+// ErrInvalidUsageQuery 报表查询条件无效, 如未知的 TRES 名称或统计粒度.
+var ErrInvalidUsageQuery = errors.New("invalid usage query")
+
+// UsageQuery 使用量报表查询条件, 统计 time_start 落在 [Start, End) 内的周期.
+type UsageQuery struct {
+	Start       int64    // 开始时间(Unix 秒)
+	End         int64    // 结束时间(Unix 秒)
+	Granularity string   // 统计粒度: hour、day 或 month, 对应 slurmdbd 汇总表
+	Tres        []string // TRES 名称, 如 cpu、gres/gpu, 为空表示全部
+	Total       bool     // 是否将整个时间范围汇总为一个周期
+}
+
+// tableSuffix 返回统计粒度对应的汇总表后缀.
+func (q UsageQuery) tableSuffix() (string, error) {
+	switch q.Granularity {
+	case "hour", "day", "month":
+		return q.Granularity, nil
+	}
+	return "", fmt.Errorf("%w: granularity must be one of hour, day, month", ErrInvalidUsageQuery)
+}
+
+// usageScope 校验查询条件, 返回集群查询作用域、TRES 定义与需要统计的 TRES ID(nil 表示全部).
+func (c *Client) usageScope(ctx context.Context, q UsageQuery) (*ClusterDB, model.TresTable, []uint32, error) {
+	if c == nil || c.DB == nil {
+		return nil, nil, nil, fmt.Errorf("nil slurmdb Client")
+	}
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if q.Start < 0 || q.End <= q.Start {
+		return nil, nil, nil, fmt.Errorf("%w: end must be after start", ErrInvalidUsageQuery)
+	}
+	if _, err := q.tableSuffix(); err != nil {
+		return nil, nil, nil, err
+	}
+	tres, err := c.GetTresTable(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(q.Tres) == 0 {
+		return cdb, tres, nil, nil
+	}
+	byName := make(map[string]uint32, len(tres))
+	for id, t := range tres {
+		byName[t.FullName()] = id
+	}
+	ids := make([]uint32, 0, len(q.Tres))
+	for _, name := range q.Tres {
+		id, ok := byName[name]
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("%w: unknown tres %q", ErrInvalidUsageQuery, name)
+		}
+		ids = append(ids, id)
+	}
+	return cdb, tres, ids, nil
+}
+
+// GetClusterUsage 查询 <cluster>_usage_<granularity>_table 中集群整体的 TRES 使用情况, 按周期与 TRES ID 升序返回.
+func (c *Client) GetClusterUsage(ctx context.Context, q UsageQuery) ([]model.ClusterUsage, error) {
+	cdb, tres, ids, err := c.usageScope(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	suffix, _ := q.tableSuffix()
+	tx := cdb.Table(UsageTable(suffix)).
+		Where("deleted = 0 AND time_start >= ? AND time_start < ?", q.Start, q.End)
+	if ids != nil {
+		tx = tx.Where("id_tres IN ?", ids)
+	}
+	if q.Total {
+		// 汇总时 count 为各周期中的最大容量
+		tx = tx.Select("id_tres, ? AS time_start, MAX(count) AS count, SUM(alloc_secs) AS alloc_secs, "+
+			"SUM(down_secs) AS down_secs, SUM(pdown_secs) AS pdown_secs, SUM(idle_secs) AS idle_secs, "+
+			"SUM(plan_secs) AS plan_secs, SUM(over_secs) AS over_secs", q.Start).
+			Group("id_tres")
+	}
+	rows := make([]model.ClusterUsage, 0)
+	if err := tx.Order("time_start ASC, id_tres ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].Tres = tresName(tres, rows[i].IDTres)
+		rows[i].Fill()
+	}
+	return rows, nil
+}
+
+// GetAccountUsageTree 返回以 account 为根的账户树及各节点的使用量. 账户树的遍历方式与 GetChildNodesOfAccount 相同:
+// 子账户为 parent_acct 等于当前账户的账户关联, 用户为 acct 等于当前账户的用户关联. slurmdbd 只为用户关联记录使用量,
+// 账户的使用量为其用户与子账户使用量之和. 已删除的关联仍参与统计, 以保留历史使用量.
+func (c *Client) GetAccountUsageTree(ctx context.Context, account string, q UsageQuery) (*model.AccountUsageNode, error) {
+	if strings.TrimSpace(account) == "" {
+		return nil, fmt.Errorf("account name is required")
+	}
+	cdb, tres, ids, err := c.usageScope(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	h, err := cdb.loadAssocHierarchy()
+	if err != nil {
+		return nil, err
+	}
+	if !h.accounts[account] {
+		return nil, gorm.ErrRecordNotFound
+	}
+	usage, err := cdb.assocUsage(q, ids, h.userAssocs(account))
+	if err != nil {
+		return nil, err
+	}
+	node, _ := h.buildUsageNode(account, usage, tres, map[string]bool{})
+	return &node, nil
+}
+
+// GetTopUsers 返回 account 子树内按 q.Tres 中第一项 TRES 的使用量降序排列的前 top 个用户, 使用量汇总整个时间范围.
+func (c *Client) GetTopUsers(ctx context.Context, account string, q UsageQuery, top int) ([]model.UserUsage, error) {
+	if strings.TrimSpace(account) == "" {
+		account = "root"
+	}
+	if len(q.Tres) == 0 {
+		q.Tres = []string{"cpu"}
+	}
+	q.Total = true
+	cdb, tres, ids, err := c.usageScope(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	h, err := cdb.loadAssocHierarchy()
+	if err != nil {
+		return nil, err
+	}
+	if !h.accounts[account] {
+		return nil, gorm.ErrRecordNotFound
+	}
+	assocs := h.userAssocs(account)
+	usage, err := cdb.assocUsage(q, ids, assocs)
+	if err != nil {
+		return nil, err
+	}
+
+	type userAcc struct {
+		acc      usageAcc
+		accounts map[string]bool
+	}
+	users := make(map[string]*userAcc)
+	for _, a := range assocs {
+		acc, ok := usage[a.IDAssoc]
+		if !ok {
+			continue
+		}
+		u := users[a.User]
+		if u == nil {
+			u = &userAcc{acc: usageAcc{}, accounts: map[string]bool{}}
+			users[a.User] = u
+		}
+		u.acc.merge(acc)
+		u.accounts[a.Acct] = true
+	}
+
+	out := make([]model.UserUsage, 0, len(users))
+	rank := make(map[string]uint64, len(users))
+	for name, u := range users {
+		uu := model.UserUsage{User: name, Usage: u.acc.samples(tres)}
+		for a := range u.accounts {
+			uu.Accounts = append(uu.Accounts, a)
+		}
+		sort.Strings(uu.Accounts)
+		for k, v := range u.acc {
+			if k.tres == ids[0] {
+				rank[name] += v
+			}
+		}
+		out = append(out, uu)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if rank[out[i].User] != rank[out[j].User] {
+			return rank[out[i].User] > rank[out[j].User]
+		}
+		return out[i].User < out[j].User
+	})
+	if top > 0 && len(out) > top {
+		out = out[:top]
+	}
+	return out, nil
+}
+
+// assocUsage 查询指定关联的使用量, 按关联 ID 返回.
+func (d *ClusterDB) assocUsage(q UsageQuery, ids []uint32, assocs []assocRow) (map[uint32]usageAcc, error) {
+	assocIDs := make([]uint32, 0, len(assocs))
+	for _, a := range assocs {
+		assocIDs = append(assocIDs, a.IDAssoc)
+	}
+	suffix, _ := q.tableSuffix()
+	return d.usageByID(AssocUsageTable(suffix), q, ids, assocIDs)
+}
+
+// usageByID 查询使用量汇总表 table 中 id 属于 rowIDs 的使用量, 按 id 返回. ids 为需要统计的 TRES ID, nil 表示全部.
+func (d *ClusterDB) usageByID(table Table, q UsageQuery, ids []uint32, rowIDs []uint32) (map[uint32]usageAcc, error) {
+	out := make(map[uint32]usageAcc)
+	if len(rowIDs) == 0 {
+		return out, nil
+	}
+
+	tx := d.Table(table).
+		Where("deleted = 0 AND time_start >= ? AND time_start < ? AND id IN ?", q.Start, q.End, rowIDs)
+	if ids != nil {
+		tx = tx.Where("id_tres IN ?", ids)
+	}
+	if q.Total {
+		tx = tx.Select("id, id_tres, ? AS time_start, SUM(alloc_secs) AS alloc_secs", q.Start).Group("id, id_tres")
+	} else {
+		tx = tx.Select("id, id_tres, time_start, alloc_secs")
+	}
+	var rows []model.AssocUsage
+	if err := tx.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		acc := out[r.IDAssoc]
+		if acc == nil {
+			acc = usageAcc{}
+			out[r.IDAssoc] = acc
+		}
+		acc[usageKey{timeStart: r.TimeStart, tres: r.IDTres}] += r.AllocSecs
+	}
+	return out, nil
+}
+
+// usageKey 使用量的统计周期与 TRES.
+type usageKey struct {
+	timeStart uint64
+	tres      uint32
+}
+
+// usageAcc 按周期与 TRES 累加的使用量.
+type usageAcc map[usageKey]uint64
+
+func (a usageAcc) merge(b usageAcc) {
+	for k, v := range b {
+		a[k] += v
+	}
+}
+
+// samples 返回按周期与 TRES ID 升序排列的使用量.
+func (a usageAcc) samples(tres model.TresTable) []model.UsageSample {
+	keys := make([]usageKey, 0, len(a))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].timeStart != keys[j].timeStart {
+			return keys[i].timeStart < keys[j].timeStart
+		}
+		return keys[i].tres < keys[j].tres
+	})
+	out := make([]model.UsageSample, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, model.UsageSample{
+			TimeStart: k.timeStart,
+			Tres:      tresName(tres, k.tres),
+			AllocSecs: a[k],
+			Hours:     float64(a[k]) / 3600,
+		})
+	}
+	return out
+}
+
+// tresName 返回 TRES 名称, 未定义时使用 ID.
+func tresName(tres model.TresTable, id uint32) string {
+	if t, ok := tres[id]; ok {
+		return t.FullName()
+	}
+	return fmt.Sprintf("%d", id)
+}
+
+// assocRow 构建账户树所需的关联字段.
+type assocRow struct {
+	IDAssoc    uint32 `gorm:"column:id_assoc"`
+	Acct       string `gorm:"column:acct"`
+	User       string `gorm:"column:user"`
+	ParentAcct string `gorm:"column:parent_acct"`
+}
+
+// assocHierarchy 内存中的账户树.
+type assocHierarchy struct {
+	accounts    map[string]bool                  // 所有账户
+	subAccounts map[string][]string              // 账户 -> 子账户
+	users       map[string]map[string][]assocRow // 账户 -> 用户 -> 用户关联(不同分区各一条)
+}
+
+// loadAssocHierarchy 一次读取 <cluster>_assoc_table 并构建账户树, 避免逐层查询.
+func (d *ClusterDB) loadAssocHierarchy() (*assocHierarchy, error) {
+	var rows []assocRow
+	if err := d.Table(AssocTable).
+		Select("id_assoc, acct, `user`, parent_acct").
+		Order("deleted ASC, id_assoc ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	h := &assocHierarchy{
+		accounts:    make(map[string]bool),
+		subAccounts: make(map[string][]string),
+		users:       make(map[string]map[string][]assocRow),
+	}
+	// 未删除的关联排在前面, 账户被删除后重建时以当前的父账户为准
+	for _, r := range rows {
+		if r.User == "" {
+			if !h.accounts[r.Acct] {
+				h.accounts[r.Acct] = true
+				if r.ParentAcct != "" && r.ParentAcct != r.Acct {
+					h.subAccounts[r.ParentAcct] = append(h.subAccounts[r.ParentAcct], r.Acct)
+				}
+			}
+			continue
+		}
+		if h.users[r.Acct] == nil {
+			h.users[r.Acct] = make(map[string][]assocRow)
+		}
+		h.users[r.Acct][r.User] = append(h.users[r.Acct][r.User], r)
+	}
+	return h, nil
+}
+
+// userAssocs 返回 account 子树内的所有用户关联.
+func (h *assocHierarchy) userAssocs(account string) []assocRow {
+	var out []assocRow
+	seen := map[string]bool{}
+	var walk func(string)
+	walk = func(acct string) {
+		if seen[acct] {
+			return
+		}
+		seen[acct] = true
+		for _, rs := range h.users[acct] {
+			out = append(out, rs...)
+		}
+		for _, sub := range h.subAccounts[acct] {
+			walk(sub)
+		}
+	}
+	walk(account)
+	return out
+}
+
+// buildUsageNode 递归构建账户节点并返回其汇总使用量. seen 用于防止异常数据导致的循环.
+func (h *assocHierarchy) buildUsageNode(account string, usage map[uint32]usageAcc, tres model.TresTable, seen map[string]bool) (model.AccountUsageNode, usageAcc) {
+	seen[account] = true
+	node := model.AccountUsageNode{Account: account, Users: make([]model.UserUsage, 0), SubAccounts: make([]model.AccountUsageNode, 0)}
+	total := usageAcc{}
+
+	names := make([]string, 0, len(h.users[account]))
+	for name := range h.users[account] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		acc := usageAcc{}
+		for _, r := range h.users[account][name] {
+			acc.merge(usage[r.IDAssoc])
+		}
+		total.merge(acc)
+		node.Users = append(node.Users, model.UserUsage{User: name, Usage: acc.samples(tres)})
+	}
+
+	subs := append([]string(nil), h.subAccounts[account]...)
+	sort.Strings(subs)
+	for _, sub := range subs {
+		if seen[sub] {
+			continue
+		}
+		child, acc := h.buildUsageNode(sub, usage, tres, seen)
+		total.merge(acc)
+		node.SubAccounts = append(node.SubAccounts, child)
+	}
+	node.Usage = total.samples(tres)
+	return node, total
+}
@@ -0,0 +1,48 @@
+package slurmdb
+
+import (
+	"testing"
+
+	"solid/internal/pkg/model"
+)
+
+func TestBuildUsageNode(t *testing.T) {
+	h := &assocHierarchy{
+		accounts:    map[string]bool{"root": true, "phys": true, "chem": true},
+		subAccounts: map[string][]string{"root": {"phys", "chem"}},
+		users: map[string]map[string][]assocRow{
+			"root": {"admin": {{IDAssoc: 1, Acct: "root", User: "admin"}}},
+			"phys": {"alice": {{IDAssoc: 2, Acct: "phys", User: "alice"}, {IDAssoc: 3, Acct: "phys", User: "alice"}}},
+			"chem": {"bob": {{IDAssoc: 4, Acct: "chem", User: "bob"}}},
+		},
+	}
+	tres := model.TresTable{1: {ID: 1, Type: "cpu"}, 1001: {ID: 1001, Type: "gres", Name: "gpu"}}
+	usage := map[uint32]usageAcc{
+		1: {{timeStart: 0, tres: 1}: 3600},
+		2: {{timeStart: 0, tres: 1}: 7200, {timeStart: 0, tres: 1001}: 3600},
+		3: {{timeStart: 0, tres: 1}: 3600},
+		4: {{timeStart: 86400, tres: 1}: 1800},
+	}
+
+	if got := len(h.userAssocs("phys")); got != 2 {
+		t.Errorf("userAssocs(phys) = %d, want 2", got)
+	}
+	if got := len(h.userAssocs("root")); got != 4 {
+		t.Errorf("userAssocs(root) = %d, want 4", got)
+	}
+
+	node, total := h.buildUsageNode("root", usage, tres, map[string]bool{})
+	if len(node.SubAccounts) != 2 || node.SubAccounts[0].Account != "chem" || node.SubAccounts[1].Account != "phys" {
+		t.Fatalf("buildUsageNode() sub accounts = %+v", node.SubAccounts)
+	}
+	if total[usageKey{0, 1}] != 3600+7200+3600 || total[usageKey{86400, 1}] != 1800 || total[usageKey{0, 1001}] != 3600 {
+		t.Errorf("buildUsageNode() total = %v", total)
+	}
+	phys := node.SubAccounts[1]
+	if len(phys.Users) != 1 || len(phys.Users[0].Usage) != 2 || phys.Users[0].Usage[0].Hours != 3 || phys.Users[0].Usage[1].Tres != "gres/gpu" {
+		t.Errorf("buildUsageNode() phys = %+v", phys)
+	}
+	if len(node.Usage) != 3 || node.Usage[2].TimeStart != 86400 {
+		t.Errorf("buildUsageNode() usage = %+v", node.Usage)
+	}
+}
@@ -0,0 +1,129 @@
+package slurmdb
+
+import (
+	"context"
+	"sort"
+
+	"solid/internal/pkg/model"
+)
+
+// GetWckeys 返回 <cluster>_wckey_table 中未删除的 WCKey, users 非空时只返回这些用户的 WCKey. 结果按用户与名称排序.
+func (c *Client) GetWckeys(ctx context.Context, users []string) (model.Wckeys, error) {
+	cdb, err := c.clusterDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := cdb.Table(WckeyTable).Where("deleted = 0")
+	if len(users) > 0 {
+		q = q.Where("`user` IN ?", users)
+	}
+	rows := make(model.Wckeys, 0)
+	if err := q.Order("`user` ASC, wckey_name ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// ResolveJobWckeys 将作业的 id_wckey 换算为 WCKey 名称并填充 WckeyName. 已删除的 WCKey 同样换算, 以便显示历史作业.
+// 跨集群查询的作业按 Cluster 使用各自集群的 wckey 表.
+func (c *Client) ResolveJobWckeys(ctx context.Context, jobs model.Jobs) error {
+	byCluster := make(map[string][]uint32)
+	for _, j := range jobs {
+		if j.IDWckey == 0 {
+			continue
+		}
+		cluster := j.Cluster
+		if cluster == "" {
+			cluster = c.ClusterName
+		}
+		byCluster[cluster] = append(byCluster[cluster], j.IDWckey)
+	}
+
+	names := make(map[string]map[uint32]string, len(byCluster))
+	for cluster, ids := range byCluster {
+		cdb, err := c.ForCluster(ctx, cluster)
+		if err != nil {
+			return err
+		}
+		var rows model.Wckeys
+		if err := cdb.Table(WckeyTable).
+			Select("id_wckey, wckey_name").
+			Where("id_wckey IN ?", ids).
+			Find(&rows).Error; err != nil {
+			return err
+		}
+		names[cluster] = make(map[uint32]string, len(rows))
+		for _, r := range rows {
+			names[cluster][r.IDWckey] = r.Name
+		}
+	}
+	for i := range jobs {
+		cluster := jobs[i].Cluster
+		if cluster == "" {
+			cluster = c.ClusterName
+		}
+		jobs[i].WckeyName = names[cluster][jobs[i].IDWckey]
+	}
+	return nil
+}
+
+// GetWckeyUsage 与 sreport cluster WCKeyUtilizationByUser 类似, 从 <cluster>_wckey_usage_<granularity>_table 统计
+// 各 WCKey 及其用户的使用量. wckeys 非空时只统计这些 WCKey. 已删除的 WCKey 仍参与统计, 以保留历史使用量.
+// 结果按 WCKey 名称排序.
+func (c *Client) GetWckeyUsage(ctx context.Context, q UsageQuery, wckeys []string) ([]model.WckeyUsage, error) {
+	cdb, tres, ids, err := c.usageScope(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	var keys model.Wckeys
+	tx := cdb.Table(WckeyTable).Select("id_wckey, wckey_name, `user`")
+	if len(wckeys) > 0 {
+		tx = tx.Where("wckey_name IN ?", wckeys)
+	}
+	if err := tx.Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	keyIDs := make([]uint32, 0, len(keys))
+	for _, k := range keys {
+		keyIDs = append(keyIDs, k.IDWckey)
+	}
+	suffix, _ := q.tableSuffix()
+	usage, err := cdb.usageByID(WckeyUsageTable(suffix), q, ids, keyIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	type wckeyAcc struct {
+		total usageAcc
+		users map[string]usageAcc
+	}
+	byName := make(map[string]*wckeyAcc)
+	for _, k := range keys {
+		acc, ok := usage[k.IDWckey]
+		if !ok {
+			continue
+		}
+		w := byName[k.Name]
+		if w == nil {
+			w = &wckeyAcc{total: usageAcc{}, users: map[string]usageAcc{}}
+			byName[k.Name] = w
+		}
+		w.total.merge(acc)
+		if w.users[k.User] == nil {
+			w.users[k.User] = usageAcc{}
+		}
+		w.users[k.User].merge(acc)
+	}
+
+	out := make([]model.WckeyUsage, 0, len(byName))
+	for name, w := range byName {
+		wu := model.WckeyUsage{Wckey: name, Usage: w.total.samples(tres)}
+		for user, acc := range w.users {
+			wu.Users = append(wu.Users, model.UserUsage{User: user, Usage: acc.samples(tres)})
+		}
+		sort.Slice(wu.Users, func(i, j int) bool { return wu.Users[i].User < wu.Users[j].User })
+		out = append(out, wu)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Wckey < out[j].Wckey })
+	return out, nil
+}
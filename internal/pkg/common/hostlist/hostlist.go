@@ -0,0 +1,375 @@
+// Package hostlist 实现 Slurm hostlist 表达式的展开(expand)与压缩(compress).
+//
+// 支持的语法:
+//   - 逗号分隔的多个主机表达式: cn1,cn[2-3],gpu01
+//   - 方括号中的数值范围与列表, 保留前导零宽度: cn[001-003,010]
+//   - 同一主机名中的多个方括号(笛卡尔积): rack[1-2]-node[01-02]
+//   - 方括号嵌套: cn[0[1-3],10] 等价于 cn01,cn02,cn03,cn10
+//   - 多维范围(每一位数字为一个维度): bgp[000x011] 等价于 bgp000,bgp001,bgp010,bgp011
+package hostlist
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MaxHosts 单个表达式允许展开的最大主机数量, 防止恶意表达式耗尽内存.
+const MaxHosts = 1 << 20
+
+// Expand 将 hostlist 表达式展开为主机名列表, 按表达式中出现的顺序返回.
+func Expand(expr string) ([]string, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" || expr == "(null)" || expr == "None" {
+		return []string{}, nil
+	}
+	terms, err := splitTopLevel(expr)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		expanded, err := expandTerm(term, MaxHosts-len(hosts))
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, expanded...)
+	}
+	return hosts, nil
+}
+
+// Contains 判断 expr 展开后是否包含 host.
+func Contains(expr, host string) (bool, error) {
+	hosts, err := Expand(expr)
+	if err != nil {
+		return false, err
+	}
+	for _, h := range hosts {
+		if h == host {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Intersects 判断 expr 展开后的主机与 hosts 是否存在交集.
+func Intersects(expr string, hosts map[string]struct{}) (bool, error) {
+	expanded, err := Expand(expr)
+	if err != nil {
+		return false, err
+	}
+	for _, h := range expanded {
+		if _, ok := hosts[h]; ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// splitTopLevel 按不在方括号内的逗号切分表达式.
+func splitTopLevel(s string) ([]string, error) {
+	parts := make([]string, 0)
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unbalanced ']' in hostlist %q", s)
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced '[' in hostlist %q", s)
+	}
+	return append(parts, s[start:]), nil
+}
+
+// expandTerm 展开单个不含顶层逗号的主机表达式, 结果为各片段的笛卡尔积.
+func expandTerm(term string, limit int) ([]string, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("hostlist expands to more than %d hosts", MaxHosts)
+	}
+	result := []string{""}
+	for i := 0; i < len(term); {
+		if term[i] != '[' {
+			j := strings.IndexByte(term[i:], '[')
+			if j < 0 {
+				j = len(term) - i
+			}
+			if strings.ContainsRune(term[i:i+j], ']') {
+				return nil, fmt.Errorf("unbalanced ']' in hostlist %q", term)
+			}
+			for k := range result {
+				result[k] += term[i : i+j]
+			}
+			i += j
+			continue
+		}
+
+		end, err := matchBracket(term, i)
+		if err != nil {
+			return nil, err
+		}
+		alts, err := expandBracket(term[i+1:end], limit)
+		if err != nil {
+			return nil, err
+		}
+		if len(result)*len(alts) > limit {
+			return nil, fmt.Errorf("hostlist %q expands to more than %d hosts", term, MaxHosts)
+		}
+		next := make([]string, 0, len(result)*len(alts))
+		for _, r := range result {
+			for _, a := range alts {
+				next = append(next, r+a)
+			}
+		}
+		result = next
+		i = end + 1
+	}
+	return result, nil
+}
+
+// matchBracket 返回与 s[open] 处 '[' 匹配的 ']' 位置.
+func matchBracket(s string, open int) (int, error) {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unbalanced '[' in hostlist %q", s)
+}
+
+// expandBracket 展开方括号内的内容, 内容为逗号分隔的范围、多维范围或嵌套表达式.
+func expandBracket(body string, limit int) ([]string, error) {
+	if strings.TrimSpace(body) == "" {
+		return nil, fmt.Errorf("empty range in hostlist")
+	}
+	items, err := splitTopLevel(body)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if limit-len(out) <= 0 {
+			return nil, fmt.Errorf("hostlist expands to more than %d hosts", MaxHosts)
+		}
+		var vals []string
+		switch {
+		case item == "":
+			return nil, fmt.Errorf("empty range in hostlist [%s]", body)
+		case strings.ContainsRune(item, '['):
+			vals, err = expandTerm(item, limit-len(out))
+		case strings.ContainsRune(item, 'x'):
+			vals, err = expandBox(item, limit-len(out))
+		case strings.ContainsRune(item, '-'):
+			vals, err = expandRange(item, limit-len(out))
+		default:
+			if !isDigits(item) {
+				return nil, fmt.Errorf("invalid range %q in hostlist", item)
+			}
+			vals = []string{item}
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, vals...)
+	}
+	return out, nil
+}
+
+// expandRange 展开形如 01-10 的数值范围, 宽度取起始值的字符长度.
+func expandRange(item string, limit int) ([]string, error) {
+	lo, hi, ok := strings.Cut(item, "-")
+	if !ok || !isDigits(lo) || !isDigits(hi) {
+		return nil, fmt.Errorf("invalid range %q in hostlist", item)
+	}
+	l, err := strconv.ParseUint(lo, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid range %q in hostlist: %w", item, err)
+	}
+	h, err := strconv.ParseUint(hi, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid range %q in hostlist: %w", item, err)
+	}
+	if h < l {
+		return nil, fmt.Errorf("invalid range %q in hostlist: end before start", item)
+	}
+	if h-l >= uint64(limit) {
+		return nil, fmt.Errorf("range %q expands to more than %d hosts", item, MaxHosts)
+	}
+	width := len(lo)
+	out := make([]string, 0, h-l+1)
+	for n := l; n <= h; n++ {
+		out = append(out, fmt.Sprintf("%0*d", width, n))
+	}
+	return out, nil
+}
+
+// expandBox 展开形如 000x133 的多维范围, 两端长度相同, 每一位数字为一个维度的坐标.
+func expandBox(item string, limit int) ([]string, error) {
+	lo, hi, ok := strings.Cut(item, "x")
+	if !ok || len(lo) != len(hi) || !isDigits(lo) || !isDigits(hi) {
+		return nil, fmt.Errorf("invalid multi-dimensional range %q in hostlist", item)
+	}
+	result := []string{""}
+	for d := 0; d < len(lo); d++ {
+		if hi[d] < lo[d] {
+			return nil, fmt.Errorf("invalid multi-dimensional range %q in hostlist: end before start", item)
+		}
+		if len(result)*int(hi[d]-lo[d]+1) > limit {
+			return nil, fmt.Errorf("range %q expands to more than %d hosts", item, MaxHosts)
+		}
+		next := make([]string, 0, len(result)*int(hi[d]-lo[d]+1))
+		for _, r := range result {
+			for c := lo[d]; c <= hi[d]; c++ {
+				next = append(next, r+string(c))
+			}
+		}
+		result = next
+	}
+	return result, nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// host 为压缩时拆分出的主机名: prefix + 数字 + suffix, 数字取主机名中最后一段连续数字.
+type host struct {
+	prefix string
+	digits string
+	suffix string
+	num    uint64
+}
+
+// Compress 将主机名列表压缩为 hostlist 表达式, 自动去重; 仅对主机名中最后一段数字进行范围合并,
+// 前导零宽度不同的主机不会被合并到同一范围, 例如 [cn1 cn2 cn3 cn05] 压缩为 cn[1-3,05].
+func Compress(hosts []string) string {
+	type group struct {
+		prefix, suffix string
+		hosts          []host
+	}
+	groups := make(map[string]*group)
+	keys := make([]string, 0)
+	seen := make(map[string]struct{}, len(hosts))
+	for _, name := range hosts {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+
+		h := splitHost(name)
+		key := h.prefix + "\x00" + h.suffix
+		if h.digits == "" {
+			key = name
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &group{prefix: h.prefix, suffix: h.suffix}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.hosts = append(g.hosts, h)
+	}
+	sort.Strings(keys)
+
+	out := make([]string, 0, len(keys))
+	for _, key := range keys {
+		g := groups[key]
+		if g.hosts[0].digits == "" {
+			out = append(out, g.prefix)
+			continue
+		}
+		if len(g.hosts) == 1 {
+			h := g.hosts[0]
+			out = append(out, h.prefix+h.digits+h.suffix)
+			continue
+		}
+		out = append(out, g.prefix+"["+compressNumbers(g.hosts)+"]"+g.suffix)
+	}
+	return strings.Join(out, ",")
+}
+
+// compressNumbers 将同一前后缀下的数字合并为范围列表, 例如 1-3,05.
+func compressNumbers(hosts []host) string {
+	sort.Slice(hosts, func(i, j int) bool {
+		if hosts[i].num != hosts[j].num {
+			return hosts[i].num < hosts[j].num
+		}
+		return hosts[i].digits < hosts[j].digits
+	})
+
+	ranges := make([]string, 0)
+	for i := 0; i < len(hosts); {
+		first := hosts[i]
+		width := 0
+		if len(first.digits) > 1 && first.digits[0] == '0' {
+			width = len(first.digits)
+		}
+		j := i + 1
+		for j < len(hosts) && hosts[j].digits == fmt.Sprintf("%0*d", width, hosts[j-1].num+1) {
+			j++
+		}
+		if j-i == 1 {
+			ranges = append(ranges, first.digits)
+		} else {
+			ranges = append(ranges, first.digits+"-"+hosts[j-1].digits)
+		}
+		i = j
+	}
+	return strings.Join(ranges, ",")
+}
+
+// splitHost 按最后一段连续数字拆分主机名.
+func splitHost(name string) host {
+	end := len(name)
+	for end > 0 && (name[end-1] < '0' || name[end-1] > '9') {
+		end--
+	}
+	if end == 0 {
+		return host{prefix: name}
+	}
+	start := end
+	for start > 0 && name[start-1] >= '0' && name[start-1] <= '9' {
+		start--
+	}
+	digits := name[start:end]
+	num, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return host{prefix: name}
+	}
+	return host{prefix: name[:start], digits: digits, suffix: name[end:], num: num}
+}
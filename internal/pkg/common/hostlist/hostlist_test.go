@@ -0,0 +1,68 @@
+package hostlist
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	cases := []struct {
+		expr string
+		want []string
+	}{
+		{"cn1", []string{"cn1"}},
+		{"cn[1-3]", []string{"cn1", "cn2", "cn3"}},
+		{"cn[1856-1858,1869]", []string{"cn1856", "cn1857", "cn1858", "cn1869"}},
+		{"cn[08-10],gpu1", []string{"cn08", "cn09", "cn10", "gpu1"}},
+		{"rack[1-2]-n[01-02]", []string{"rack1-n01", "rack1-n02", "rack2-n01", "rack2-n02"}},
+		{"cn[0[1-2],10]", []string{"cn01", "cn02", "cn10"}},
+		{"bgp[000x011]", []string{"bgp000", "bgp001", "bgp010", "bgp011"}},
+		{"", []string{}},
+	}
+	for _, tc := range cases {
+		got, err := Expand(tc.expr)
+		if err != nil {
+			t.Fatalf("Expand(%q) error: %v", tc.expr, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Expand(%q) = %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestExpandInvalid(t *testing.T) {
+	for _, expr := range []string{"cn[1-3", "cn1-3]", "cn[3-1]", "cn[a-b]", "cn[]", "cn[1-99999999]"} {
+		if _, err := Expand(expr); err == nil {
+			t.Errorf("Expand(%q) expected error", expr)
+		}
+	}
+}
+
+func TestCompress(t *testing.T) {
+	cases := []struct {
+		hosts []string
+		want  string
+	}{
+		{[]string{"cn3", "cn1", "cn2", "cn1"}, "cn[1-3]"},
+		{[]string{"cn09", "cn10", "cn12"}, "cn[09-10,12]"},
+		{[]string{"cn1", "cn01"}, "cn[01,1]"},
+		{[]string{"login", "cn5"}, "cn5,login"},
+		{[]string{"rack1-n01", "rack1-n02"}, "rack1-n[01-02]"},
+	}
+	for _, tc := range cases {
+		if got := Compress(tc.hosts); got != tc.want {
+			t.Errorf("Compress(%v) = %q, want %q", tc.hosts, got, tc.want)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	expr := "cn[1856-1860,1869,1970-1978,2041-2048]"
+	hosts, err := Expand(expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Compress(hosts); got != expr {
+		t.Errorf("Compress(Expand(%q)) = %q", expr, got)
+	}
+}
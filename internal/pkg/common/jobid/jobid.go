@@ -0,0 +1,72 @@
+// Package jobid 解析 Slurm 作业 ID.
+//
+// 支持的格式:
+//   - 普通作业: 123
+//   - 数组作业的任务: 123_7
+//   - 异构作业的组件: 123+1
+package jobid
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ID Slurm 作业 ID.
+type ID struct {
+	JobID          uint32 // 作业 ID, 数组任务为数组作业 ID
+	ArrayTask      uint32 // 数组任务 ID, 仅 IsArrayTask 为 true 时有效
+	IsArrayTask    bool   // 是否指定了数组任务
+	HetOffset      uint32 // 异构作业组件偏移, 仅 IsHetComponent 为 true 时有效
+	IsHetComponent bool   // 是否指定了异构作业组件, JobID 为异构作业 leader 的 ID
+}
+
+// Parse 解析作业 ID 字符串.
+func Parse(s string) (ID, error) {
+	s = strings.TrimSpace(s)
+	var id ID
+	if job, offset, isHet := strings.Cut(s, "+"); isHet {
+		n, err := parseUint32(job)
+		if err != nil || n == 0 {
+			return ID{}, fmt.Errorf("invalid job id %q", s)
+		}
+		o, err := parseUint32(offset)
+		if err != nil {
+			return ID{}, fmt.Errorf("invalid het job offset in job id %q", s)
+		}
+		return ID{JobID: n, HetOffset: o, IsHetComponent: true}, nil
+	}
+	job, task, isTask := strings.Cut(s, "_")
+	n, err := parseUint32(job)
+	if err != nil || n == 0 {
+		return ID{}, fmt.Errorf("invalid job id %q", s)
+	}
+	id.JobID = n
+	if isTask {
+		t, err := parseUint32(task)
+		if err != nil {
+			return ID{}, fmt.Errorf("invalid array task id in job id %q", s)
+		}
+		id.ArrayTask, id.IsArrayTask = t, true
+	}
+	return id, nil
+}
+
+// String 返回 Slurm 格式的作业 ID.
+func (id ID) String() string {
+	if id.IsHetComponent {
+		return fmt.Sprintf("%d+%d", id.JobID, id.HetOffset)
+	}
+	if id.IsArrayTask {
+		return fmt.Sprintf("%d_%d", id.JobID, id.ArrayTask)
+	}
+	return strconv.FormatUint(uint64(id.JobID), 10)
+}
+
+func parseUint32(s string) (uint32, error) {
+	if s == "" || strings.TrimLeft(s, "0123456789") != "" {
+		return 0, fmt.Errorf("not a number: %q", s)
+	}
+	n, err := strconv.ParseUint(s, 10, 32)
+	return uint32(n), err
+}
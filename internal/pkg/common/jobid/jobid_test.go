@@ -0,0 +1,32 @@
+package jobid
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		in   string
+		want ID
+	}{
+		{"123", ID{JobID: 123}},
+		{" 123_7 ", ID{JobID: 123, ArrayTask: 7, IsArrayTask: true}},
+		{"123_0", ID{JobID: 123, ArrayTask: 0, IsArrayTask: true}},
+		{"1234+1", ID{JobID: 1234, HetOffset: 1, IsHetComponent: true}},
+	}
+	for _, c := range cases {
+		got, err := Parse(c.in)
+		if err != nil || got != c.want {
+			t.Errorf("Parse(%q) = %+v, %v, want %+v", c.in, got, err, c.want)
+		}
+		if got.String() != strings.TrimSpace(c.in) {
+			t.Errorf("Parse(%q).String() = %q", c.in, got.String())
+		}
+	}
+	for _, in := range []string{"", "0", "abc", "123_", "_7", "123_x", "-1", "+1", "123_[1-3]", "99999999999", "1234+", "+1", "1234+1_2", "1234_1+2"} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) expected error", in)
+		}
+	}
+}
@@ -25,3 +25,9 @@ type Account struct {
 
 func (Account) TableName() string { return "acct_table" }
 
+
+// AccountDetail 账户详情, History 为 txn_table 中涉及该账户及其关联的最近变更.
+type AccountDetail struct {
+    Account
+    History Txns `json:"history,omitempty"`
+}
@@ -1,7 +1,5 @@
 package model
 
-import "fmt"
-
 // UserAssociation represents a row from the cluster-specific <ClusterName>_assoc_table
 // for a user. It captures common relationship columns.
 type UserAssociation struct {
@@ -24,22 +22,60 @@ type UserAssociation struct {
 	MaxJobsAccrue  int32  `gorm:"column:max_jobs_accrue" json:"max_jobs_accrue"`
 	MinPrioThresh  int32  `gorm:"column:min_prio_thresh" json:"min_prio_thresh"`
 	MaxSubmitJobs  int32  `gorm:"column:max_submit_jobs" json:"max_submit_jobs"`
-	MaxTresPJ      string `gorm:"column:max_tres_pj" json:"max_tres_pj"`
-	MaxTresPN      string `gorm:"column:max_tres_pn" json:"max_tres_pn"`
-	MaxTresMinsPJ  string `gorm:"column:max_tres_mins_pj" json:"max_tres_mins_pj"`
-	MaxTresRunMins string `gorm:"column:max_tres_run_mins" json:"max_tres_run_mins"`
+	MaxTresPJ      string `gorm:"column:max_tres_pj" json:"-"`
+	MaxTresPN      string `gorm:"column:max_tres_pn" json:"-"`
+	MaxTresMinsPJ  string `gorm:"column:max_tres_mins_pj" json:"-"`
+	MaxTresRunMins string `gorm:"column:max_tres_run_mins" json:"-"`
 	MaxWallPJ      int32  `gorm:"column:max_wall_pj" json:"max_wall_pj"`
 	GrpJobs        int32  `gorm:"column:grp_jobs" json:"grp_jobs"`
 	GrpJobsAccrue  int32  `gorm:"column:grp_jobs_accrue" json:"grp_jobs_accrue"`
 	GrpSubmitJobs  int32  `gorm:"column:grp_submit_jobs" json:"grp_submit_jobs"`
-	GrpTres        string `gorm:"column:grp_tres" json:"grp_tres"`
-	GrpTresMins    string `gorm:"column:grp_tres_mins" json:"grp_tres_mins"`
-	GrpTresRunMins string `gorm:"column:grp_tres_run_mins" json:"grp_tres_run_mins"`
+	GrpTres        string `gorm:"column:grp_tres" json:"-"`
+	GrpTresMins    string `gorm:"column:grp_tres_mins" json:"-"`
+	GrpTresRunMins string `gorm:"column:grp_tres_run_mins" json:"-"`
 	GrpWall        int32  `gorm:"column:grp_wall" json:"grp_wall"`
 	Priority       uint32 `gorm:"column:priority" json:"priority"`
 	DefQosID       int32  `gorm:"column:def_qos_id" json:"def_qos_id"`
 	QOS            string `gorm:"column:qos" json:"qos"`
+
+	// 以下为 Decode 填充的解码结果, 原始取值保存在 Raw 中
+	MaxTresPJDecoded      []TresCount `gorm:"-" json:"max_tres_pj"`
+	MaxTresPNDecoded      []TresCount `gorm:"-" json:"max_tres_pn"`
+	MaxTresMinsPJDecoded  []TresCount `gorm:"-" json:"max_tres_mins_pj"`
+	MaxTresRunMinsDecoded []TresCount `gorm:"-" json:"max_tres_run_mins"`
+	GrpTresDecoded        []TresCount `gorm:"-" json:"grp_tres"`
+	GrpTresMinsDecoded    []TresCount `gorm:"-" json:"grp_tres_mins"`
+	GrpTresRunMinsDecoded []TresCount `gorm:"-" json:"grp_tres_run_mins"`
+	Raw                   *AssocRaw   `gorm:"-" json:"raw,omitempty"`
 }
 
-// AssocTableName returns the physical table name for the given cluster name.
-func AssocTableName(cluster string) string { return fmt.Sprintf("%s_assoc_table", cluster) }
+// AssocRaw 关联中被解码的 TRES 字段的原始取值.
+type AssocRaw struct {
+	MaxTresPJ      string `json:"max_tres_pj"`
+	MaxTresPN      string `json:"max_tres_pn"`
+	MaxTresMinsPJ  string `json:"max_tres_mins_pj"`
+	MaxTresRunMins string `json:"max_tres_run_mins"`
+	GrpTres        string `json:"grp_tres"`
+	GrpTresMins    string `json:"grp_tres_mins"`
+	GrpTresRunMins string `json:"grp_tres_run_mins"`
+}
+
+// Decode 将各 TRES 限制解码为可读形式, 原始取值保存到 Raw.
+func (a *UserAssociation) Decode(tres TresTable) {
+	a.MaxTresPJDecoded = tres.Decode(a.MaxTresPJ)
+	a.MaxTresPNDecoded = tres.Decode(a.MaxTresPN)
+	a.MaxTresMinsPJDecoded = tres.Decode(a.MaxTresMinsPJ)
+	a.MaxTresRunMinsDecoded = tres.Decode(a.MaxTresRunMins)
+	a.GrpTresDecoded = tres.Decode(a.GrpTres)
+	a.GrpTresMinsDecoded = tres.Decode(a.GrpTresMins)
+	a.GrpTresRunMinsDecoded = tres.Decode(a.GrpTresRunMins)
+	a.Raw = &AssocRaw{
+		MaxTresPJ:      a.MaxTresPJ,
+		MaxTresPN:      a.MaxTresPN,
+		MaxTresMinsPJ:  a.MaxTresMinsPJ,
+		MaxTresRunMins: a.MaxTresRunMins,
+		GrpTres:        a.GrpTres,
+		GrpTresMins:    a.GrpTresMins,
+		GrpTresRunMins: a.GrpTresRunMins,
+	}
+}
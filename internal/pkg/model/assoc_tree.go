@@ -0,0 +1,221 @@
+package model
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AssocLimits 关联上可被子关联继承的限制. slurmdbd 以 NULL 表示未设置, 对应字段为 nil 或空字符串.
+type AssocLimits struct {
+	MaxJobs        *int32  `gorm:"column:max_jobs" json:"max_jobs"`
+	MaxJobsAccrue  *int32  `gorm:"column:max_jobs_accrue" json:"max_jobs_accrue"`
+	MaxSubmitJobs  *int32  `gorm:"column:max_submit_jobs" json:"max_submit_jobs"`
+	MaxWallPJ      *int32  `gorm:"column:max_wall_pj" json:"max_wall_pj"` // 分钟
+	MinPrioThresh  *int32  `gorm:"column:min_prio_thresh" json:"min_prio_thresh"`
+	Priority       *uint32 `gorm:"column:priority" json:"priority"`
+	MaxTresPJ      string  `gorm:"column:max_tres_pj" json:"-"`
+	MaxTresPN      string  `gorm:"column:max_tres_pn" json:"-"`
+	MaxTresMinsPJ  string  `gorm:"column:max_tres_mins_pj" json:"-"`
+	MaxTresRunMins string  `gorm:"column:max_tres_run_mins" json:"-"`
+	DefQosID       *int32  `gorm:"column:def_qos_id" json:"def_qos_id"`
+	QOS            string  `gorm:"column:qos" json:"qos"` // 逗号分隔的 QoS ID, 如 ",1,3,"
+
+	// 以下为 Decode 填充的解码结果, 原始取值保存在 Raw 中
+	MaxTresPJDecoded      []TresCount     `gorm:"-" json:"max_tres_pj"`
+	MaxTresPNDecoded      []TresCount     `gorm:"-" json:"max_tres_pn"`
+	MaxTresMinsPJDecoded  []TresCount     `gorm:"-" json:"max_tres_mins_pj"`
+	MaxTresRunMinsDecoded []TresCount     `gorm:"-" json:"max_tres_run_mins"`
+	Raw                   *AssocLimitsRaw `gorm:"-" json:"raw,omitempty"`
+}
+
+// AssocLimitsRaw AssocLimits 中被解码字段的原始取值.
+type AssocLimitsRaw struct {
+	MaxTresPJ      string `json:"max_tres_pj"`
+	MaxTresPN      string `json:"max_tres_pn"`
+	MaxTresMinsPJ  string `json:"max_tres_mins_pj"`
+	MaxTresRunMins string `json:"max_tres_run_mins"`
+}
+
+// Decode 将各 TRES 限制解码为可读形式, 原始取值保存到 Raw.
+func (l *AssocLimits) Decode(tres TresTable) {
+	l.MaxTresPJDecoded = tres.Decode(l.MaxTresPJ)
+	l.MaxTresPNDecoded = tres.Decode(l.MaxTresPN)
+	l.MaxTresMinsPJDecoded = tres.Decode(l.MaxTresMinsPJ)
+	l.MaxTresRunMinsDecoded = tres.Decode(l.MaxTresRunMins)
+	l.Raw = &AssocLimitsRaw{
+		MaxTresPJ:      l.MaxTresPJ,
+		MaxTresPN:      l.MaxTresPN,
+		MaxTresMinsPJ:  l.MaxTresMinsPJ,
+		MaxTresRunMins: l.MaxTresRunMins,
+	}
+}
+
+// AssocGrpLimits 关联上的组限制. 组限制作用于该关联及其所有子关联的总和, 不会被子关联继承.
+type AssocGrpLimits struct {
+	GrpJobs        *int32 `gorm:"column:grp_jobs" json:"grp_jobs"`
+	GrpJobsAccrue  *int32 `gorm:"column:grp_jobs_accrue" json:"grp_jobs_accrue"`
+	GrpSubmitJobs  *int32 `gorm:"column:grp_submit_jobs" json:"grp_submit_jobs"`
+	GrpWall        *int32 `gorm:"column:grp_wall" json:"grp_wall"` // 分钟
+	GrpTres        string `gorm:"column:grp_tres" json:"-"`
+	GrpTresMins    string `gorm:"column:grp_tres_mins" json:"-"`
+	GrpTresRunMins string `gorm:"column:grp_tres_run_mins" json:"-"`
+
+	// 以下为 Decode 填充的解码结果, 原始取值保存在 Raw 中
+	GrpTresDecoded        []TresCount        `gorm:"-" json:"grp_tres"`
+	GrpTresMinsDecoded    []TresCount        `gorm:"-" json:"grp_tres_mins"`
+	GrpTresRunMinsDecoded []TresCount        `gorm:"-" json:"grp_tres_run_mins"`
+	Raw                   *AssocGrpLimitsRaw `gorm:"-" json:"raw,omitempty"`
+}
+
+// AssocGrpLimitsRaw AssocGrpLimits 中被解码字段的原始取值.
+type AssocGrpLimitsRaw struct {
+	GrpTres        string `json:"grp_tres"`
+	GrpTresMins    string `json:"grp_tres_mins"`
+	GrpTresRunMins string `json:"grp_tres_run_mins"`
+}
+
+// Decode 将各 TRES 组限制解码为可读形式, 原始取值保存到 Raw.
+func (l *AssocGrpLimits) Decode(tres TresTable) {
+	l.GrpTresDecoded = tres.Decode(l.GrpTres)
+	l.GrpTresMinsDecoded = tres.Decode(l.GrpTresMins)
+	l.GrpTresRunMinsDecoded = tres.Decode(l.GrpTresRunMins)
+	l.Raw = &AssocGrpLimitsRaw{
+		GrpTres:        l.GrpTres,
+		GrpTresMins:    l.GrpTresMins,
+		GrpTresRunMins: l.GrpTresRunMins,
+	}
+}
+
+// AssocTreeNode 关联树中的节点, 账户关联的子节点为子账户与用户关联, 按 lft 排序.
+type AssocTreeNode struct {
+	IDAssoc    uint32 `gorm:"column:id_assoc" json:"id_assoc"`
+	IDParent   uint32 `gorm:"column:id_parent" json:"id_parent"`
+	Acct       string `gorm:"column:acct" json:"acct"`
+	User       string `gorm:"column:user" json:"user"`
+	Partition  string `gorm:"column:partition" json:"partition"`
+	ParentAcct string `gorm:"column:parent_acct" json:"parent_acct"`
+	Lft        int32  `gorm:"column:lft" json:"lft"`
+	Rgt        int32  `gorm:"column:rgt" json:"rgt"`
+	Shares     int32  `gorm:"column:shares" json:"shares"`
+	IsDef      int8   `gorm:"column:is_def" json:"is_def"`
+
+	Limits    AssocLimits    `gorm:"embedded" json:"limits"`     // 关联自身设置的限制
+	GrpLimits AssocGrpLimits `gorm:"embedded" json:"grp_limits"` // 关联自身设置的组限制
+
+	Depth     int              `gorm:"-" json:"depth"`     // 相对于查询根节点的深度, 根节点为 0
+	Effective AssocLimits      `gorm:"-" json:"effective"` // 继承父关联后生效的限制
+	Children  []*AssocTreeNode `gorm:"-" json:"children"`  // 子关联
+}
+
+// Decode 解码该节点及其所有子节点的自身限制、组限制与生效限制中的 TRES 字段.
+func (n *AssocTreeNode) Decode(tres TresTable) {
+	n.Limits.Decode(tres)
+	n.GrpLimits.Decode(tres)
+	n.Effective.Decode(tres)
+	for _, child := range n.Children {
+		child.Decode(tres)
+	}
+}
+
+// IsUser 是否为用户关联.
+func (n *AssocTreeNode) IsUser() bool { return n.User != "" }
+
+// InheritLimits 返回 own 继承 parent 后生效的限制: 未设置的数值限制、默认 QoS 与 QoS 列表取父关联的值,
+// TRES 限制按 TRES 逐项继承.
+func InheritLimits(parent, own AssocLimits) AssocLimits {
+	eff := own
+	inheritInt32(&eff.MaxJobs, parent.MaxJobs)
+	inheritInt32(&eff.MaxJobsAccrue, parent.MaxJobsAccrue)
+	inheritInt32(&eff.MaxSubmitJobs, parent.MaxSubmitJobs)
+	inheritInt32(&eff.MaxWallPJ, parent.MaxWallPJ)
+	inheritInt32(&eff.MinPrioThresh, parent.MinPrioThresh)
+	inheritInt32(&eff.DefQosID, parent.DefQosID)
+	if eff.Priority == nil {
+		eff.Priority = parent.Priority
+	}
+	eff.MaxTresPJ = MergeTres(parent.MaxTresPJ, own.MaxTresPJ)
+	eff.MaxTresPN = MergeTres(parent.MaxTresPN, own.MaxTresPN)
+	eff.MaxTresMinsPJ = MergeTres(parent.MaxTresMinsPJ, own.MaxTresMinsPJ)
+	eff.MaxTresRunMins = MergeTres(parent.MaxTresRunMins, own.MaxTresRunMins)
+	if strings.Trim(eff.QOS, ",") == "" {
+		eff.QOS = parent.QOS
+	}
+	return eff
+}
+
+// inheritInt32 在 v 未设置(nil 或 -1)时取 parent 的值.
+func inheritInt32(v **int32, parent *int32) {
+	if *v == nil || **v == -1 {
+		*v = parent
+	}
+}
+
+// MergeTres 合并两个 "1=8,1001=2" 形式的 TRES 字符串, own 中的项覆盖 parent 中的同名项, 结果按 TRES ID 排序.
+func MergeTres(parent, own string) string {
+	m := ParseTresString(parent)
+	for id, v := range ParseTresString(own) {
+		m[id] = v
+	}
+	return FormatTresString(m)
+}
+
+// ParseTresString 解析 "1=8,1001=2" 形式的 TRES 字符串, 忽略无法解析的项与 -1(未设置).
+func ParseTresString(s string) map[uint32]string {
+	out := make(map[uint32]string)
+	for _, kv := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(kv), "=")
+		if !ok || v == "" || v == "-1" {
+			continue
+		}
+		id, err := strconv.ParseUint(k, 10, 32)
+		if err != nil {
+			continue
+		}
+		out[uint32(id)] = v
+	}
+	return out
+}
+
+// FormatTresString 将 TRES 映射格式化为按 ID 排序的 "1=8,1001=2" 形式.
+func FormatTresString(m map[uint32]string) string {
+	ids := make([]uint32, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.FormatUint(uint64(id), 10)+"="+m[id])
+	}
+	return strings.Join(parts, ",")
+}
+
+// BuildAssocTree 根据按 lft 升序排列的嵌套集合节点构建关联树. 第一个节点为根, lft 不在根 [lft, rgt] 内的节点被忽略.
+// inherited 为根节点从祖先继承的限制. depth 大于 0 时只保留相对根节点深度不超过 depth 的节点, 生效限制仍按完整路径计算.
+func BuildAssocTree(nodes []*AssocTreeNode, inherited AssocLimits, depth int) *AssocTreeNode {
+	if len(nodes) == 0 {
+		return nil
+	}
+	root := nodes[0]
+	root.Effective = InheritLimits(inherited, root.Limits)
+	root.Children = make([]*AssocTreeNode, 0)
+	stack := []*AssocTreeNode{root}
+	for _, n := range nodes[1:] {
+		if n.Lft < root.Lft || n.Lft > root.Rgt {
+			continue
+		}
+		for len(stack) > 1 && stack[len(stack)-1].Rgt < n.Lft {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1]
+		n.Depth = parent.Depth + 1
+		n.Effective = InheritLimits(parent.Effective, n.Limits)
+		n.Children = make([]*AssocTreeNode, 0)
+		if depth <= 0 || n.Depth <= depth {
+			parent.Children = append(parent.Children, n)
+		}
+		stack = append(stack, n)
+	}
+	return root
+}
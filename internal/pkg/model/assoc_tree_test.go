@@ -0,0 +1,35 @@
+package model
+
+import "testing"
+
+func int32p(v int32) *int32 { return &v }
+
+func TestBuildAssocTree(t *testing.T) {
+	nodes := []*AssocTreeNode{
+		{IDAssoc: 2, Acct: "phys", Lft: 2, Rgt: 9, Limits: AssocLimits{MaxJobs: int32p(100), MaxTresPJ: "1=64,1001=4", QOS: ",1,"}},
+		{IDAssoc: 3, Acct: "phys", User: "alice", Lft: 3, Rgt: 4, Limits: AssocLimits{MaxTresPJ: "1=32"}},
+		{IDAssoc: 4, Acct: "hep", ParentAcct: "phys", Lft: 5, Rgt: 8, Limits: AssocLimits{MaxJobs: int32p(-1), MaxWallPJ: int32p(60)}},
+		{IDAssoc: 5, Acct: "hep", User: "bob", Lft: 6, Rgt: 7, Limits: AssocLimits{MaxJobs: int32p(5), QOS: ",2,"}},
+		{IDAssoc: 6, Acct: "chem", Lft: 10, Rgt: 11},
+	}
+	root := BuildAssocTree(nodes, AssocLimits{MaxSubmitJobs: int32p(1000)}, 0)
+	if root == nil || len(root.Children) != 2 {
+		t.Fatalf("BuildAssocTree() root = %+v", root)
+	}
+	alice, hep := root.Children[0], root.Children[1]
+	if alice.User != "alice" || hep.Acct != "hep" || len(hep.Children) != 1 || hep.Depth != 1 {
+		t.Fatalf("BuildAssocTree() children = %+v, %+v", alice, hep)
+	}
+	if alice.Effective.MaxTresPJ != "1=32,1001=4" || *alice.Effective.MaxJobs != 100 || *alice.Effective.MaxSubmitJobs != 1000 {
+		t.Errorf("alice effective = %+v", alice.Effective)
+	}
+	bob := hep.Children[0]
+	if *hep.Effective.MaxJobs != 100 || *bob.Effective.MaxJobs != 5 || *bob.Effective.MaxWallPJ != 60 || bob.Effective.QOS != ",2," || bob.Depth != 2 {
+		t.Errorf("bob effective = %+v", bob.Effective)
+	}
+
+	shallow := BuildAssocTree(nodes, AssocLimits{}, 1)
+	if len(shallow.Children) != 2 || len(shallow.Children[1].Children) != 0 {
+		t.Errorf("BuildAssocTree(depth=1) = %+v", shallow.Children)
+	}
+}
@@ -0,0 +1,49 @@
+package model
+
+/*
++------------------+----------------------+------+-----+---------+-------+
+| Field            | Type                 | Null | Key | Default | Extra |
++------------------+----------------------+------+-----+---------+-------+
+| creation_time    | bigint(20) unsigned  | NO   |     | NULL    |       |
+| mod_time         | bigint(20) unsigned  | NO   |     | 0       |       |
+| deleted          | tinyint(4)           | YES  |     | 0       |       |
+| name             | tinytext             | NO   | PRI | NULL    |       |
+| id               | smallint(5) unsigned | YES  |     | NULL    |       |
+| control_host     | tinytext             | NO   |     | ''      |       |
+| control_port     | int(10) unsigned     | NO   |     | 0       |       |
+| last_port        | int(10) unsigned     | NO   |     | 0       |       |
+| rpc_version      | smallint(5) unsigned | NO   |     | 0       |       |
+| classification   | smallint(5) unsigned | YES  |     | 0       |       |
+| dimensions       | smallint(5) unsigned | YES  |     | 1       |       |
+| plugin_id_select | smallint(5) unsigned | YES  |     | 0       |       |
+| flags            | int(10) unsigned     | YES  |     | 0       |       |
+| federation       | tinytext             | NO   |     | NULL    |       |
+| features         | text                 | NO   |     | ''      |       |
+| fed_id           | int(10) unsigned     | NO   |     | 0       |       |
+| fed_state        | smallint(5) unsigned | NO   |     | NULL    |       |
++------------------+----------------------+------+-----+---------+-------+
+*/
+
+// Clusters is a slice of Cluster rows.
+type Clusters []Cluster
+
+// Cluster represents a row in cluster_table, 每个集群拥有一组 <cluster>_*_table.
+type Cluster struct {
+	CreationTime uint64  `gorm:"column:creation_time" json:"creation_time"`
+	ModTime      uint64  `gorm:"column:mod_time" json:"mod_time"`
+	Deleted      int8    `gorm:"column:deleted" json:"deleted"`
+	Name         string  `gorm:"column:name;primaryKey" json:"name"`
+	ID           *uint16 `gorm:"column:id" json:"id"`
+	ControlHost  string  `gorm:"column:control_host" json:"control_host"`
+	ControlPort  uint32  `gorm:"column:control_port" json:"control_port"`
+	RpcVersion   uint16  `gorm:"column:rpc_version" json:"rpc_version"`
+	Flags        uint32  `gorm:"column:flags" json:"flags"`
+	Federation   string  `gorm:"column:federation" json:"federation"`
+	Features     string  `gorm:"column:features" json:"features"`
+
+	Default bool `gorm:"-" json:"default"`          // 是否为配置的默认集群
+	Backend bool `gorm:"-" json:"slurmctl_backend"` // 是否配置了对应的 slurmctl 后端
+}
+
+// TableName implements gorm's tabler interface.
+func (Cluster) TableName() string { return "cluster_table" }
@@ -0,0 +1,143 @@
+package model
+
+import (
+	"fmt"
+	"math"
+)
+
+// JobEfficiency 作业资源使用效率, 与 seff 的计算方式一致. 比值字段为 nil 表示无法计算(如作业未开始、未申请内存或无 GPU 统计).
+type JobEfficiency struct {
+	JobID         string   `json:"jobid"`                 // 作业 ID, 数组任务为 123_7, 异构作业组件为 123+1
+	JobDBInx      uint64   `json:"job_db_inx"`            // job_table 中的记录 ID
+	JobName       string   `json:"job_name"`              // 作业名称
+	IDUser        uint32   `json:"id_user"`               // 用户 UID
+	Account       string   `json:"account"`               // 账户
+	State         string   `json:"state"`                 // 作业状态
+	Elapsed       int64    `json:"elapsed"`               // 运行时长(秒), 不含挂起时间, 运行中的作业计算到当前时间
+	TimeLimit     int64    `json:"timelimit"`             // 时间限制(秒), -1 表示不限制
+	WallUsage     *float64 `json:"wall_usage"`            // 运行时长 / 时间限制
+	AllocCPUs     int64    `json:"alloc_cpus"`            // 分配的 CPU 数
+	CPUTime       float64  `json:"cpu_time"`              // 各作业步 user + sys CPU 时间之和(秒)
+	CoreWalltime  int64    `json:"core_walltime"`         // 运行时长 * 分配 CPU 数(秒)
+	CPUEfficiency *float64 `json:"cpu_efficiency"`        // CPU 时间 / 核时
+	ReqMem        int64    `json:"req_mem"`               // 分配的内存(MB)
+	MaxRSS        int64    `json:"max_rss"`               // 各作业步中最大的内存使用量(MB), 多任务作业步按各任务峰值之和估算
+	MemEfficiency *float64 `json:"mem_efficiency"`        // 内存使用量 / 分配内存
+	AllocGPUs     int64    `json:"alloc_gpus"`            // 分配的 GPU 数
+	GPUUtil       *float64 `json:"gpu_utilization"`       // GPU 平均利用率(0-1), 需要 acct_gather 采集 gres/gpuutil
+	GPUMemMax     *int64   `json:"gpu_mem_max,omitempty"` // GPU 显存峰值(MB), 需要 acct_gather 采集 gres/gpumem
+	Steps         int      `json:"steps"`                 // 参与统计的作业步数量
+}
+
+// DisplayID 返回 sacct 中显示的作业 ID: 数组任务为 123_7, 异构作业组件为 123+1.
+func (j Job) DisplayID() string {
+	switch {
+	case j.HetJobID != 0 && j.HetJobOffset != NoHetJobOffset:
+		return fmt.Sprintf("%d+%d", j.HetJobID, j.HetJobOffset)
+	case j.IDArrayJob != 0 && j.IDArrayTask != NoArrayTask:
+		return fmt.Sprintf("%d_%d", j.IDArrayJob, j.IDArrayTask)
+	default:
+		return fmt.Sprintf("%d", j.IDJob)
+	}
+}
+
+// memPerCPU mem_req 最高位表示按 CPU 申请内存(MEM_PER_CPU).
+const memPerCPU = uint64(1) << 63
+
+// ComputeEfficiency 根据作业记录及其作业步计算资源使用效率, now 为计算运行中作业时长使用的当前时间(Unix 秒).
+func ComputeEfficiency(job Job, steps Steps, tres TresTable, now int64) JobEfficiency {
+	e := JobEfficiency{
+		JobID:     job.DisplayID(),
+		JobDBInx:  job.JobDBInx,
+		JobName:   job.JobName,
+		IDUser:    job.IDUser,
+		Account:   job.Account,
+		State:     JobStateName(job.State),
+		TimeLimit: -1,
+		Steps:     len(steps),
+	}
+	// timelimit 以分钟为单位, INFINITE 与 NO_VAL 表示不限制
+	if job.TimeLimit < 0xfffffffe {
+		e.TimeLimit = int64(job.TimeLimit) * 60
+	}
+	if job.TimeStart > 0 {
+		end := int64(job.TimeEnd)
+		if end == 0 {
+			end = now
+		}
+		e.Elapsed = end - int64(job.TimeStart) - int64(job.TimeSuspended)
+		if e.Elapsed < 0 {
+			e.Elapsed = 0
+		}
+	}
+
+	alloc := tresByName(tres.Decode(job.TresAlloc))
+	e.AllocCPUs = alloc["cpu"]
+	if e.AllocCPUs == 0 {
+		e.AllocCPUs = int64(job.CPUsReq)
+	}
+	e.ReqMem = alloc["mem"]
+	if e.ReqMem == 0 && job.MemReq > 0 {
+		e.ReqMem = int64(job.MemReq &^ memPerCPU)
+		if job.MemReq&memPerCPU != 0 {
+			e.ReqMem *= e.AllocCPUs
+		}
+	}
+	e.AllocGPUs = alloc["gres/gpu"]
+
+	var gpuUtil float64
+	var gpuUtilFound bool
+	for _, st := range steps {
+		e.CPUTime += float64(st.UserSec) + float64(st.SysSec) + float64(st.UserUsec+st.SysUsec)/1e6
+		max := tresByName(tres.Decode(st.TRESUsageInMax))
+		tot := tresByName(tres.Decode(st.TRESUsageInTot))
+		ave := tresByName(tres.Decode(st.TRESUsageInAve))
+		// 内存统计以字节为单位
+		rss := tot["mem"]
+		if rss < max["mem"] {
+			rss = max["mem"]
+		}
+		if rss/(1<<20) > e.MaxRSS {
+			e.MaxRSS = rss / (1 << 20)
+		}
+		if v, ok := ave["gres/gpuutil"]; ok {
+			gpuUtilFound = true
+			gpuUtil = math.Max(gpuUtil, float64(v)/100)
+		}
+		if v, ok := max["gres/gpumem"]; ok {
+			mb := v / (1 << 20)
+			if e.GPUMemMax == nil || mb > *e.GPUMemMax {
+				e.GPUMemMax = &mb
+			}
+		}
+	}
+
+	e.CoreWalltime = e.Elapsed * e.AllocCPUs
+	e.CPUEfficiency = ratio(e.CPUTime, float64(e.CoreWalltime))
+	e.MemEfficiency = ratio(float64(e.MaxRSS), float64(e.ReqMem))
+	if e.TimeLimit > 0 {
+		e.WallUsage = ratio(float64(e.Elapsed), float64(e.TimeLimit))
+	}
+	if gpuUtilFound && e.AllocGPUs > 0 {
+		e.GPUUtil = &gpuUtil
+	}
+	return e
+}
+
+// tresByName 将解码后的 TRES 转换为以名称索引的数量.
+func tresByName(list []TresCount) map[string]int64 {
+	out := make(map[string]int64, len(list))
+	for _, t := range list {
+		out[t.Name] = t.Count
+	}
+	return out
+}
+
+// ratio 返回 a / b, b 不为正数时返回 nil.
+func ratio(a, b float64) *float64 {
+	if b <= 0 {
+		return nil
+	}
+	v := a / b
+	return &v
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputeEfficiency(t *testing.T) {
+	tres := TresTable{
+		1:    {ID: 1, Type: "cpu"},
+		2:    {ID: 2, Type: "mem"},
+		1001: {ID: 1001, Type: "gres", Name: "gpu"},
+		1002: {ID: 1002, Type: "gres", Name: "gpuutil"},
+	}
+	job := Job{
+		JobDBInx: 7, IDJob: 101, IDArrayJob: 100, IDArrayTask: 1, HetJobOffset: NoHetJobOffset,
+		State: 3, TimeLimit: 60, TimeStart: 1000, TimeEnd: 1000 + 1800,
+		TresAlloc: "1=4,2=8192,1001=2",
+	}
+	steps := Steps{
+		{UserSec: 3000, SysSec: 600, TRESUsageInMax: "2=1073741824", TRESUsageInTot: "2=2147483648", TRESUsageInAve: "1002=50"},
+		{UserSec: 0, SysSec: 0, UserUsec: 500000, SysUsec: 500000, TRESUsageInMax: "2=1048576"},
+	}
+	e := ComputeEfficiency(job, steps, tres, 0)
+	if e.JobID != "100_1" || e.Elapsed != 1800 || e.TimeLimit != 3600 || e.AllocCPUs != 4 || e.AllocGPUs != 2 {
+		t.Fatalf("ComputeEfficiency() = %+v", e)
+	}
+	check := func(name string, got *float64, want float64) {
+		t.Helper()
+		if got == nil || math.Abs(*got-want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+	check("cpu", e.CPUEfficiency, 3601.0/7200)
+	check("mem", e.MemEfficiency, 2048.0/8192)
+	check("wall", e.WallUsage, 0.5)
+	check("gpu", e.GPUUtil, 0.5)
+
+	// 未开始的作业无法计算效率, 内存按 CPU 申请时乘以 CPU 数
+	e = ComputeEfficiency(Job{IDJob: 5, TimeLimit: 0xffffffff, CPUsReq: 2, MemReq: memPerCPU | 1000}, nil, tres, 0)
+	if e.CPUEfficiency != nil || e.WallUsage != nil || e.TimeLimit != -1 || e.ReqMem != 2000 || e.JobID != "5" {
+		t.Errorf("ComputeEfficiency() pending = %+v", e)
+	}
+}
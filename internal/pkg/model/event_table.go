@@ -0,0 +1,228 @@
+package model
+
+import "sort"
+
+/*
++---------------+---------------------+------+-----+------------+-------+
+| Field         | Type                | Null | Key | Default    | Extra |
++---------------+---------------------+------+-----+------------+-------+
+| time_start    | bigint(20) unsigned | NO   | PRI | NULL       |       |
+| time_end      | bigint(20) unsigned | NO   |     | 0          |       |
+| node_name     | tinytext            | NO   | PRI | ''         |       |
+| cluster_nodes | text                | NO   |     | ''         |       |
+| extra         | text                | YES  |     | NULL       |       |
+| reason        | tinytext            | NO   |     | NULL       |       |
+| reason_uid    | int(10) unsigned    | NO   |     | 4294967294 |       |
+| state         | int(10) unsigned    | NO   |     | 0          |       |
+| tres          | text                | NO   |     | NULL       |       |
++---------------+---------------------+------+-----+------------+-------+
+*/
+
+// NoReasonUID 为 reason_uid 的默认值(NO_VAL), 表示没有记录操作者.
+const NoReasonUID = 0xfffffffe
+
+// Events is a slice of Event rows.
+type Events []Event
+
+// Event represents a node row in <cluster>_event_table. node_name 为空的行是集群 TRES 变化记录, 不在此建模.
+// 每行对应节点进入不可用状态(DOWN、DRAIN、FAIL 等)的一段时间, time_end 为 0 表示尚未恢复.
+// Note: physical table name is cluster-specific ("<cluster>_event_table").
+type Event struct {
+	TimeStart    uint64 `gorm:"column:time_start;primaryKey" json:"time_start"`
+	TimeEnd      uint64 `gorm:"column:time_end" json:"time_end"`
+	NodeName     string `gorm:"column:node_name;primaryKey" json:"node_name"`
+	ClusterNodes string `gorm:"column:cluster_nodes" json:"cluster_nodes"`
+	Reason       string `gorm:"column:reason" json:"reason"`
+	ReasonUID    uint32 `gorm:"column:reason_uid" json:"reason_uid"`
+	State        uint32 `gorm:"column:state" json:"state"`
+	TRES         string `gorm:"column:tres" json:"tres"`
+
+	// 以下为解码或查询时计算的字段
+	StateName  string      `gorm:"-" json:"state_name"`            // 节点状态, 如 DOWN、IDLE+DRAIN
+	ReasonUser string      `gorm:"-" json:"reason_user"`           // 设置原因的用户, 由 reason_uid 经 LDAP 换算
+	Duration   int64       `gorm:"-" json:"duration"`              // 持续时长(秒), 未恢复时计算到当前时间
+	Ongoing    bool        `gorm:"-" json:"ongoing"`               // 是否尚未恢复
+	FailedJobs []FailedJob `gorm:"-" json:"failed_jobs,omitempty"` // 期间因该节点失败的作业
+}
+
+// Fill 解码状态并计算持续时长, 未恢复的事件计算到 now.
+func (e *Event) Fill(now int64) {
+	e.StateName = NodeStateName(e.State)
+	e.Ongoing = e.TimeEnd == 0
+	end := int64(e.TimeEnd)
+	if e.Ongoing {
+		end = now
+	}
+	if d := end - int64(e.TimeStart); d > 0 {
+		e.Duration = d
+	}
+}
+
+// FailedJob 因节点故障失败的作业(<cluster>_job_table 中 failed_node 为该节点).
+type FailedJob struct {
+	IDJob     uint32 `gorm:"column:id_job" json:"id_job"`
+	JobName   string `gorm:"column:job_name" json:"job_name"`
+	IDUser    uint32 `gorm:"column:id_user" json:"id_user"`
+	Account   string `gorm:"column:account" json:"account"`
+	Partition string `gorm:"column:partition" json:"partition"`
+	State     uint32 `gorm:"column:state" json:"-"`
+	StateName string `gorm:"-" json:"state"`
+	Node      string `gorm:"column:failed_node" json:"failed_node"`
+	TimeStart uint64 `gorm:"column:time_start" json:"time_start"`
+	TimeEnd   uint64 `gorm:"column:time_end" json:"time_end"`
+}
+
+// nodeStateNames 节点基本状态(state & 0xf)的名称.
+var nodeStateNames = []string{"UNKNOWN", "DOWN", "IDLE", "ALLOCATED", "ERROR", "MIXED", "FUTURE"}
+
+// nodeStateFlagNames 节点状态标志, 与 slurm.h 中 NODE_STATE_* 一致.
+var nodeStateFlagNames = []flagName{
+	{0x00000020, "RESERVED"},
+	{0x00000080, "CLOUD"},
+	{0x00000200, "DRAIN"},
+	{0x00000400, "COMPLETING"},
+	{0x00000800, "NOT_RESPONDING"},
+	{0x00001000, "POWERED_DOWN"},
+	{0x00002000, "FAIL"},
+	{0x00004000, "POWERING_UP"},
+	{0x00008000, "MAINTENANCE"},
+	{0x00010000, "REBOOT_REQUESTED"},
+	{0x00040000, "POWERING_DOWN"},
+	{0x00100000, "REBOOT_ISSUED"},
+	{0x00200000, "PLANNED"},
+	{0x00400000, "INVALID_REG"},
+}
+
+// NodeStateName 返回 event_table 中 state 列对应的节点状态, 基本状态与标志以 + 连接, 如 IDLE+DRAIN.
+func NodeStateName(state uint32) string {
+	name := "UNKNOWN"
+	if base := state & 0xf; int(base) < len(nodeStateNames) {
+		name = nodeStateNames[base]
+	}
+	for _, f := range decodeFlags(uint64(state), nodeStateFlagNames) {
+		name += "+" + f
+	}
+	return name
+}
+
+// NodeAvailability 节点在统计窗口内的可用性. 窗口内 event_table 记录的不可用时间均计为停机, 重叠的事件只计一次.
+type NodeAvailability struct {
+	Node         string   `json:"node,omitempty"`
+	Period       int64    `json:"period"`       // 统计窗口(秒)
+	Outages      int      `json:"outages"`      // 窗口内开始的停机次数
+	Downtime     int64    `json:"downtime"`     // 窗口内不可用时长(秒)
+	Availability float64  `json:"availability"` // 1 - downtime / period
+	MTBF         *float64 `json:"mtbf"`         // 平均故障间隔(秒), 即可用时长 / 停机次数; 无停机时为空
+	MTTR         *float64 `json:"mttr"`         // 平均恢复时间(秒), 即不可用时长 / 停机次数; 无停机时为空
+}
+
+// PartitionAvailability 分区内所有节点的可用性汇总.
+type PartitionAvailability struct {
+	Partition string `json:"partition"`
+	Nodes     int    `json:"nodes"` // 节点数
+	NodeAvailability
+}
+
+// AvailabilityReport 节点与分区的可用性报表.
+type AvailabilityReport struct {
+	Start      int64                   `json:"start"` // 统计窗口开始时间(Unix 秒), 含
+	End        int64                   `json:"end"`   // 统计窗口结束时间(Unix 秒), 不含
+	Nodes      []NodeAvailability      `json:"nodes"`
+	Partitions []PartitionAvailability `json:"partitions"`
+}
+
+// ComputeAvailability 根据事件计算 nodes 中每个节点在 [start, end) 内的可用性, 未恢复的事件计算到 now.
+// nodes 为空时只统计出现在 events 中的节点. 结果按节点名称排序.
+func ComputeAvailability(events Events, nodes []string, start, end, now int64) []NodeAvailability {
+	type span struct{ from, to int64 }
+	byNode := make(map[string][]span)
+	for _, n := range nodes {
+		byNode[n] = nil
+	}
+	for _, e := range events {
+		if e.NodeName == "" {
+			continue
+		}
+		if _, ok := byNode[e.NodeName]; !ok && len(nodes) > 0 {
+			continue
+		}
+		to := int64(e.TimeEnd)
+		if to == 0 {
+			to = now
+		}
+		byNode[e.NodeName] = append(byNode[e.NodeName], span{int64(e.TimeStart), to})
+	}
+
+	period := end - start
+	out := make([]NodeAvailability, 0, len(byNode))
+	for node, spans := range byNode {
+		sort.Slice(spans, func(i, j int) bool { return spans[i].from < spans[j].from })
+		a := NodeAvailability{Node: node, Period: period}
+		var cur *span
+		flush := func() {
+			if cur == nil {
+				return
+			}
+			from, to := max(cur.from, start), min(cur.to, end)
+			if to > from {
+				a.Downtime += to - from
+			}
+			if cur.from >= start && cur.from < end {
+				a.Outages++
+			}
+		}
+		for i := range spans {
+			if cur != nil && spans[i].from <= cur.to {
+				cur.to = max(cur.to, spans[i].to)
+				continue
+			}
+			flush()
+			cur = &spans[i]
+		}
+		flush()
+		a.fill()
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Node < out[j].Node })
+	return out
+}
+
+// AggregateAvailability 汇总 nodes 中节点的可用性, 各节点的统计窗口相同.
+func AggregateAvailability(partition string, nodes []NodeAvailability) PartitionAvailability {
+	p := PartitionAvailability{Partition: partition, Nodes: len(nodes)}
+	for _, n := range nodes {
+		p.Period += n.Period
+		p.Outages += n.Outages
+		p.Downtime += n.Downtime
+	}
+	p.fill()
+	return p
+}
+
+// fill 根据窗口、停机次数与停机时长计算可用率、MTBF 与 MTTR.
+func (a *NodeAvailability) fill() {
+	a.Availability = 1
+	if a.Period > 0 {
+		a.Availability = 1 - float64(a.Downtime)/float64(a.Period)
+	}
+	if a.Outages > 0 {
+		mtbf := float64(a.Period-a.Downtime) / float64(a.Outages)
+		mttr := float64(a.Downtime) / float64(a.Outages)
+		a.MTBF, a.MTTR = &mtbf, &mttr
+	}
+}
+
+// NodeNames 返回 events 中出现的节点名称(去重并排序).
+func (es Events) NodeNames() []string {
+	seen := make(map[string]struct{})
+	out := make([]string, 0)
+	for _, e := range es {
+		if _, ok := seen[e.NodeName]; ok || e.NodeName == "" {
+			continue
+		}
+		seen[e.NodeName] = struct{}{}
+		out = append(out, e.NodeName)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNodeStateName(t *testing.T) {
+	cases := map[uint32]string{
+		1:          "DOWN",
+		2 | 0x200:  "IDLE+DRAIN",
+		3 | 0x200:  "ALLOCATED+DRAIN",
+		1 | 0x2800: "DOWN+NOT_RESPONDING+FAIL",
+		15:         "UNKNOWN",
+	}
+	for state, want := range cases {
+		if got := NodeStateName(state); got != want {
+			t.Errorf("NodeStateName(%#x) = %q, want %q", state, got, want)
+		}
+	}
+}
+
+func TestComputeAvailability(t *testing.T) {
+	events := Events{
+		{NodeName: "a", TimeStart: 100, TimeEnd: 200}, // 窗口外
+		{NodeName: "a", TimeStart: 600, TimeEnd: 700}, // 与下一条重叠, 合并为 600-800
+		{NodeName: "a", TimeStart: 650, TimeEnd: 800},
+		{NodeName: "a", TimeStart: 1400},              // 尚未恢复, 截断到窗口结束
+		{NodeName: "b", TimeStart: 400, TimeEnd: 600}, // 窗口开始前发生, 不计入停机次数
+		{NodeName: "d", TimeStart: 700, TimeEnd: 800}, // 不在节点列表中
+		{NodeName: "", TimeStart: 700, TimeEnd: 800},  // 集群事件
+	}
+	got := ComputeAvailability(events, []string{"a", "b", "c"}, 500, 1500, 2000)
+	if len(got) != 3 {
+		t.Fatalf("ComputeAvailability() = %+v", got)
+	}
+	a, b, c := got[0], got[1], got[2]
+	if a.Node != "a" || a.Outages != 2 || a.Downtime != 300 || math.Abs(a.Availability-0.7) > 1e-9 ||
+		a.MTBF == nil || *a.MTBF != 350 || a.MTTR == nil || *a.MTTR != 150 {
+		t.Errorf("node a = %+v", a)
+	}
+	if b.Node != "b" || b.Outages != 0 || b.Downtime != 100 || b.MTBF != nil {
+		t.Errorf("node b = %+v", b)
+	}
+	if c.Node != "c" || c.Downtime != 0 || c.Availability != 1 {
+		t.Errorf("node c = %+v", c)
+	}
+
+	p := AggregateAvailability("cpu", got)
+	if p.Nodes != 3 || p.Period != 3000 || p.Outages != 2 || p.Downtime != 400 || p.MTBF == nil || *p.MTBF != 1300 {
+		t.Errorf("AggregateAvailability() = %+v", p)
+	}
+}
@@ -1,5 +1,7 @@
 package model
 
+import "strings"
+
 // Jobs is a slice of Job rows.
 type Jobs []Job
 
@@ -17,12 +19,12 @@ type Job struct {
 	Constraints      string `gorm:"column:constraints" json:"constraints"`
 	Container        string `gorm:"column:container" json:"container"`
 	CPUsReq          uint32 `gorm:"column:cpus_req" json:"cpus_req"`
-	DerivedEC        uint32 `gorm:"column:derived_ec" json:"derived_ec"`
+	DerivedEC        uint32 `gorm:"column:derived_ec" json:"-"`
 	DerivedES        string `gorm:"column:derived_es" json:"derived_es"`
 	EnvHashInx       uint64 `gorm:"column:env_hash_inx" json:"env_hash_inx"`
-	ExitCode         uint32 `gorm:"column:exit_code" json:"exit_code"`
+	ExitCode         uint32 `gorm:"column:exit_code" json:"-"`
 	Extra            string `gorm:"column:extra" json:"extra"`
-	Flags            uint32 `gorm:"column:flags" json:"flags"`
+	Flags            uint32 `gorm:"column:flags" json:"-"`
 	FailedNode       string `gorm:"column:failed_node" json:"failed_node"`
 	JobName          string `gorm:"column:job_name" json:"job_name"`
 	IDAssoc          uint32 `gorm:"column:id_assoc" json:"id_assoc"`
@@ -48,7 +50,7 @@ type Job struct {
 	Partition        string `gorm:"column:partition" json:"partition"`
 	Priority         uint32 `gorm:"column:priority" json:"priority"`
 	ScriptHashInx    uint64 `gorm:"column:script_hash_inx" json:"script_hash_inx"`
-	State            uint32 `gorm:"column:state" json:"state"`
+	State            uint32 `gorm:"column:state" json:"-"`
 	TimeLimit        uint32 `gorm:"column:timelimit" json:"timelimit"`
 	TimeSubmit       uint64 `gorm:"column:time_submit" json:"time_submit"`
 	TimeEligible     uint64 `gorm:"column:time_eligible" json:"time_eligible"`
@@ -63,6 +65,133 @@ type Job struct {
 	StdOut           string `gorm:"column:std_out" json:"std_out"`
 	SubmitLine       string `gorm:"column:submit_line" json:"submit_line"`
 	SystemComment    string `gorm:"column:system_comment" json:"system_comment"`
-	TresAlloc        string `gorm:"column:tres_alloc" json:"tres_alloc"`
-	TresReq          string `gorm:"column:tres_req" json:"tres_req"`
+	TresAlloc        string `gorm:"column:tres_alloc" json:"-"`
+	TresReq          string `gorm:"column:tres_req" json:"-"`
+	// Nodes holds the expanded Nodelist. It is only filled on request and
+	// ignored by GORM.
+	Nodes []string `gorm:"-" json:"nodes,omitempty"`
+	// Cluster 作业所属集群, 仅在跨集群查询时填充.
+	Cluster string `gorm:"-" json:"cluster,omitempty"`
+	// WckeyName 由 id_wckey 在 <cluster>_wckey_table 中换算的 WCKey 名称.
+	WckeyName string `gorm:"-" json:"wckey_name,omitempty"`
+
+	// 以下为 Decode 填充的解码结果, 原始取值保存在 Raw 中
+	StateName        string      `gorm:"-" json:"state"`
+	Exit             ExitCode    `gorm:"-" json:"exit_code"`
+	DerivedExit      ExitCode    `gorm:"-" json:"derived_ec"`
+	FlagNames        []string    `gorm:"-" json:"flags"`
+	TresAllocDecoded []TresCount `gorm:"-" json:"tres_alloc"`
+	TresReqDecoded   []TresCount `gorm:"-" json:"tres_req"`
+	Raw              *JobRaw     `gorm:"-" json:"raw,omitempty"`
+}
+
+// JobRaw 作业中被解码字段的原始取值.
+type JobRaw struct {
+	State     uint32 `json:"state"`
+	ExitCode  uint32 `json:"exit_code"`
+	DerivedEC uint32 `json:"derived_ec"`
+	Flags     uint32 `json:"flags"`
+	TresAlloc string `json:"tres_alloc"`
+	TresReq   string `json:"tres_req"`
+}
+
+// jobFlagNames job_table 中 flags 列的取值(SLURMDB_JOB_FLAG_*).
+var jobFlagNames = []flagName{
+	{1 << 0, "SchedNotSet"},
+	{1 << 1, "SchedSubmit"},
+	{1 << 2, "SchedMain"},
+	{1 << 3, "SchedBackfill"},
+	{1 << 4, "StartReceived"},
+}
+
+// Decode 将状态、退出码、标志与 TRES 解码为可读形式, 原始取值保存到 Raw.
+func (j *Job) Decode(tres TresTable) {
+	j.StateName = JobStateName(j.State)
+	j.Exit = DecodeExitCode(j.ExitCode)
+	j.DerivedExit = DecodeExitCode(j.DerivedEC)
+	j.FlagNames = decodeFlags(uint64(j.Flags), jobFlagNames)
+	j.TresAllocDecoded = tres.Decode(j.TresAlloc)
+	j.TresReqDecoded = tres.Decode(j.TresReq)
+	j.Raw = &JobRaw{
+		State:     j.State,
+		ExitCode:  j.ExitCode,
+		DerivedEC: j.DerivedEC,
+		Flags:     j.Flags,
+		TresAlloc: j.TresAlloc,
+		TresReq:   j.TresReq,
+	}
+}
+
+// Decode 解码列表中的所有作业.
+func (js Jobs) Decode(tres TresTable) {
+	for i := range js {
+		js[i].Decode(tres)
+	}
+}
+
+// NoArrayTask 为 id_array_task 的特殊值(NO_VAL), 表示数组作业中尚未开始、合并记录的任务.
+const NoArrayTask = 0xfffffffe
+
+// jobStateNames 作业基本状态(state & 0xff)的名称.
+var jobStateNames = []string{
+	"PENDING", "RUNNING", "SUSPENDED", "COMPLETED", "CANCELLED", "FAILED",
+	"TIMEOUT", "NODE_FAIL", "PREEMPTED", "BOOT_FAIL", "DEADLINE", "OUT_OF_MEMORY",
+}
+
+// JobStateName 返回 job_table 中 state 列对应的基本状态名称.
+func JobStateName(state uint32) string {
+	if base := state & 0xff; int(base) < len(jobStateNames) {
+		return jobStateNames[base]
+	}
+	return "UNKNOWN"
+}
+
+// jobStateShortNames sacct 使用的状态缩写.
+var jobStateShortNames = map[string]uint32{
+	"PD": 0, "R": 1, "S": 2, "CD": 3, "CA": 4, "F": 5,
+	"TO": 6, "NF": 7, "PR": 8, "BF": 9, "DL": 10, "OOM": 11,
+}
+
+// JobStateValue 返回状态名称或缩写(不区分大小写)对应的 state 列取值.
+func JobStateValue(name string) (uint32, bool) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if v, ok := jobStateShortNames[name]; ok {
+		return v, true
+	}
+	for i, n := range jobStateNames {
+		if n == name {
+			return uint32(i), true
+		}
+	}
+	return 0, false
+}
+
+// ArraySummary 数组作业的任务统计.
+type ArraySummary struct {
+	ArrayJobID uint32           `json:"array_job_id"` // 数组作业 ID
+	Tasks      int64            `json:"tasks"`        // 任务数量
+	States     map[string]int64 `json:"states"`       // 各状态的任务数量
+}
+
+// NoHetJobOffset 为 het_job_offset 的特殊值(NO_VAL), 表示非异构作业.
+const NoHetJobOffset = 0xfffffffe
+
+// HetJob 异构作业, 由 leader 与按偏移排序的组件组成. 非异构作业只有一个组件.
+type HetJob struct {
+	HetJobID   uint32            `json:"het_job_id"` // leader 作业 ID
+	Components []HetJobComponent `json:"components"` // 各组件
+}
+
+// HetJobComponent 异构作业的组件及其作业步.
+type HetJobComponent struct {
+	Job
+	Steps Steps `json:"steps"`
+}
+
+// Decode 解码所有组件及其作业步.
+func (h *HetJob) Decode(tres TresTable) {
+	for i := range h.Components {
+		h.Components[i].Job.Decode(tres)
+		h.Components[i].Steps.Decode(tres)
+	}
 }
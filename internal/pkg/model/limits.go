@@ -0,0 +1,205 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// QosLimits qos_table 中与资源限制相关的列. slurmdbd 以 NULL 表示未设置, 对应字段为 nil 或空字符串.
+type QosLimits struct {
+	ID                    uint32 `gorm:"column:id" json:"id"`
+	Name                  string `gorm:"column:name" json:"name"`
+	Flags                 uint32 `gorm:"column:flags" json:"flags"`
+	MaxJobsPA             *int32 `gorm:"column:max_jobs_pa"`
+	MaxJobsPerUser        *int32 `gorm:"column:max_jobs_per_user"`
+	MaxJobsAccruePA       *int32 `gorm:"column:max_jobs_accrue_pa"`
+	MaxJobsAccruePU       *int32 `gorm:"column:max_jobs_accrue_pu"`
+	MinPrioThresh         *int32 `gorm:"column:min_prio_thresh"`
+	MaxSubmitJobsPA       *int32 `gorm:"column:max_submit_jobs_pa"`
+	MaxSubmitJobsPerUser  *int32 `gorm:"column:max_submit_jobs_per_user"`
+	MaxTresPA             string `gorm:"column:max_tres_pa"`
+	MaxTresPJ             string `gorm:"column:max_tres_pj"`
+	MaxTresPN             string `gorm:"column:max_tres_pn"`
+	MaxTresPU             string `gorm:"column:max_tres_pu"`
+	MaxTresMinsPJ         string `gorm:"column:max_tres_mins_pj"`
+	MaxTresRunMinsPA      string `gorm:"column:max_tres_run_mins_pa"`
+	MaxTresRunMinsPU      string `gorm:"column:max_tres_run_mins_pu"`
+	MinTresPJ             string `gorm:"column:min_tres_pj"`
+	MaxWallDurationPerJob *int32 `gorm:"column:max_wall_duration_per_job"`
+	GrpJobs               *int32 `gorm:"column:grp_jobs"`
+	GrpJobsAccrue         *int32 `gorm:"column:grp_jobs_accrue"`
+	GrpSubmitJobs         *int32 `gorm:"column:grp_submit_jobs"`
+	GrpTres               string `gorm:"column:grp_tres"`
+	GrpTresMins           string `gorm:"column:grp_tres_mins"`
+	GrpTresRunMins        string `gorm:"column:grp_tres_run_mins"`
+	GrpWall               *int32 `gorm:"column:grp_wall"`
+}
+
+// QosFlagOverPartQOS QoS 的 OverPartQOS 标志: 作业 QoS 的限制优先于分区 QoS.
+const QosFlagOverPartQOS = 0x80
+
+// LimitSource 限制的来源.
+type LimitSource struct {
+	Kind string `json:"kind"`         // partition_qos、qos、association 或 partition
+	ID   uint32 `json:"id,omitempty"` // QoS ID 或 id_assoc
+	Name string `json:"name"`         // QoS 名称、分区名称或 account/user(partition) 形式的关联
+}
+
+// LimitLayer 一层来源设置的限制. 键为限制名称, TRES 限制为 "名称/TRES ID", 如 grp_tres/1001.
+type LimitLayer struct {
+	Source LimitSource
+	Values map[string]int64
+}
+
+// EffectiveLimit 一项生效的限制.
+type EffectiveLimit struct {
+	Name   string       `json:"name"`             // 限制名称, 使用关联中的命名, 如 max_jobs、grp_tres
+	Tres   string       `json:"tres,omitempty"`   // TRES 限制的 TRES 名称
+	Value  int64        `json:"value"`            // 取值, 时间类限制以分钟为单位
+	Source LimitSource  `json:"source"`           // 设置该取值的来源
+	Layers []LimitValue `json:"layers,omitempty"` // 由关联设置的组限制: 同时生效的各级关联的取值, 叶子在前
+}
+
+// LimitValue 一层来源设置的取值.
+type LimitValue struct {
+	Value  int64       `json:"value"`
+	Source LimitSource `json:"source"`
+}
+
+// EffectiveLimits 关联最终生效的限制.
+type EffectiveLimits struct {
+	IDAssoc      uint32           `json:"id_assoc"`      // 匹配的关联
+	Account      string           `json:"account"`       // 账户
+	User         string           `json:"user"`          // 用户, 账户关联为空
+	Partition    string           `json:"partition"`     // 关联的分区, 不区分分区时为空
+	Qos          string           `json:"qos"`           // 作业 QoS, 未指定时为关联的默认 QoS
+	PartitionQos string           `json:"partition_qos"` // 分区 QoS
+	OverPartQOS  bool             `json:"over_part_qos"` // 作业 QoS 是否带有 OverPartQOS 标志
+	Chain        []LimitSource    `json:"chain"`         // 按优先级排列的来源
+	Limits       []EffectiveLimit `json:"limits"`        // 生效的限制
+}
+
+// setInt32 v 已设置(非 nil 且不为 -1)时写入 values.
+func setInt32(values map[string]int64, name string, v *int32) {
+	if v != nil && *v != -1 {
+		values[name] = int64(*v)
+	}
+}
+
+// setTres 将 TRES 字符串中的各项写入 values.
+func setTres(values map[string]int64, name, s string) {
+	for id, v := range ParseTresString(s) {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			values[fmt.Sprintf("%s/%d", name, id)] = n
+		}
+	}
+}
+
+// AssocLimitLayer 返回关联自身设置的限制.
+func AssocLimitLayer(n *AssocTreeNode) LimitLayer {
+	name := n.Acct
+	if n.User != "" {
+		name += "/" + n.User
+	}
+	if n.Partition != "" {
+		name += "(" + n.Partition + ")"
+	}
+	l := LimitLayer{Source: LimitSource{Kind: "association", ID: n.IDAssoc, Name: name}, Values: map[string]int64{}}
+	setInt32(l.Values, "max_jobs", n.Limits.MaxJobs)
+	setInt32(l.Values, "max_jobs_accrue", n.Limits.MaxJobsAccrue)
+	setInt32(l.Values, "max_submit_jobs", n.Limits.MaxSubmitJobs)
+	setInt32(l.Values, "max_wall_pj", n.Limits.MaxWallPJ)
+	setInt32(l.Values, "min_prio_thresh", n.Limits.MinPrioThresh)
+	setTres(l.Values, "max_tres_pj", n.Limits.MaxTresPJ)
+	setTres(l.Values, "max_tres_pn", n.Limits.MaxTresPN)
+	setTres(l.Values, "max_tres_mins_pj", n.Limits.MaxTresMinsPJ)
+	setTres(l.Values, "max_tres_run_mins", n.Limits.MaxTresRunMins)
+	setInt32(l.Values, "grp_jobs", n.GrpLimits.GrpJobs)
+	setInt32(l.Values, "grp_jobs_accrue", n.GrpLimits.GrpJobsAccrue)
+	setInt32(l.Values, "grp_submit_jobs", n.GrpLimits.GrpSubmitJobs)
+	setInt32(l.Values, "grp_wall", n.GrpLimits.GrpWall)
+	setTres(l.Values, "grp_tres", n.GrpLimits.GrpTres)
+	setTres(l.Values, "grp_tres_mins", n.GrpLimits.GrpTresMins)
+	setTres(l.Values, "grp_tres_run_mins", n.GrpLimits.GrpTresRunMins)
+	return l
+}
+
+// QosLimitLayer 返回 QoS 设置的限制. 按用户的 QoS 限制映射到关联中对应的限制名称, 按账户的限制保留 QoS 中的名称.
+func QosLimitLayer(kind string, q *QosLimits) LimitLayer {
+	l := LimitLayer{Source: LimitSource{Kind: kind, ID: q.ID, Name: q.Name}, Values: map[string]int64{}}
+	setInt32(l.Values, "max_jobs", q.MaxJobsPerUser)
+	setInt32(l.Values, "max_jobs_pa", q.MaxJobsPA)
+	setInt32(l.Values, "max_jobs_accrue", q.MaxJobsAccruePU)
+	setInt32(l.Values, "max_jobs_accrue_pa", q.MaxJobsAccruePA)
+	setInt32(l.Values, "max_submit_jobs", q.MaxSubmitJobsPerUser)
+	setInt32(l.Values, "max_submit_jobs_pa", q.MaxSubmitJobsPA)
+	setInt32(l.Values, "max_wall_pj", q.MaxWallDurationPerJob)
+	setInt32(l.Values, "min_prio_thresh", q.MinPrioThresh)
+	setTres(l.Values, "max_tres_pj", q.MaxTresPJ)
+	setTres(l.Values, "max_tres_pn", q.MaxTresPN)
+	setTres(l.Values, "max_tres_pu", q.MaxTresPU)
+	setTres(l.Values, "max_tres_pa", q.MaxTresPA)
+	setTres(l.Values, "max_tres_mins_pj", q.MaxTresMinsPJ)
+	setTres(l.Values, "max_tres_run_mins", q.MaxTresRunMinsPU)
+	setTres(l.Values, "max_tres_run_mins_pa", q.MaxTresRunMinsPA)
+	setTres(l.Values, "min_tres_pj", q.MinTresPJ)
+	setInt32(l.Values, "grp_jobs", q.GrpJobs)
+	setInt32(l.Values, "grp_jobs_accrue", q.GrpJobsAccrue)
+	setInt32(l.Values, "grp_submit_jobs", q.GrpSubmitJobs)
+	setInt32(l.Values, "grp_wall", q.GrpWall)
+	setTres(l.Values, "grp_tres", q.GrpTres)
+	setTres(l.Values, "grp_tres_mins", q.GrpTresMins)
+	setTres(l.Values, "grp_tres_run_mins", q.GrpTresRunMins)
+	return l
+}
+
+// ResolveLimits 按 layers 的优先级(从高到低)取每项限制第一个设置的取值, 结果按名称与 TRES ID 排序.
+// 组限制(grp_*)作用于关联及其所有祖先的总和, 各级关联的组限制同时生效, 因此未被 QoS 设置时取各级关联中
+// 最严格(最小)的取值, 并在 Layers 中列出每一级的取值. QoS 设置的组限制与 Slurm 一致, 覆盖关联的同名限制.
+func ResolveLimits(layers []LimitLayer, tres TresTable) []EffectiveLimit {
+	type resolved struct {
+		value  int64
+		source LimitSource
+		layers []LimitValue
+	}
+	seen := make(map[string]*resolved)
+	for _, l := range layers {
+		for k, v := range l.Values {
+			r, ok := seen[k]
+			if !ok {
+				r = &resolved{value: v, source: l.Source}
+				seen[k] = r
+			}
+			if !strings.HasPrefix(k, "grp_") || l.Source.Kind != "association" || r.source.Kind != "association" {
+				continue
+			}
+			if v < r.value {
+				r.value, r.source = v, l.Source
+			}
+			r.layers = append(r.layers, LimitValue{Value: v, Source: l.Source})
+		}
+	}
+
+	out := make([]EffectiveLimit, 0, len(seen))
+	for k, r := range seen {
+		el := EffectiveLimit{Name: k, Value: r.value, Source: r.source, Layers: r.layers}
+		if name, id, ok := strings.Cut(k, "/"); ok {
+			n, _ := strconv.ParseUint(id, 10, 32)
+			el.Name = name
+			el.Tres = id
+			if t, ok := tres[uint32(n)]; ok {
+				el.Tres = t.FullName()
+			}
+		}
+		out = append(out, el)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].Tres < out[j].Tres
+	})
+	return out
+}
@@ -0,0 +1,48 @@
+package model
+
+import "testing"
+
+func TestResolveLimits(t *testing.T) {
+	user := &AssocTreeNode{IDAssoc: 3, Acct: "phys", User: "alice", Partition: "gpu",
+		Limits:    AssocLimits{MaxJobs: int32p(10), MaxTresPJ: "1=32"},
+		GrpLimits: AssocGrpLimits{GrpJobs: int32p(20)}}
+	acct := &AssocTreeNode{IDAssoc: 2, Acct: "phys",
+		Limits:    AssocLimits{MaxJobs: int32p(50), MaxWallPJ: int32p(-1), MaxTresPJ: "1=64,1001=4"},
+		GrpLimits: AssocGrpLimits{GrpTres: "1001=8", GrpJobs: int32p(30)}}
+	// 父账户更严格的组限制同样生效
+	root := &AssocTreeNode{IDAssoc: 1, Acct: "root", GrpLimits: AssocGrpLimits{GrpTres: "1=128", GrpJobs: int32p(15)}}
+	qos := &QosLimits{ID: 1, Name: "normal", MaxJobsPerUser: int32p(5), MaxWallDurationPerJob: int32p(1440), GrpTres: "1001=-1"}
+	part := LimitLayer{Source: LimitSource{Kind: "partition", Name: "gpu"}, Values: map[string]int64{"max_wall_pj": 2880}}
+
+	layers := []LimitLayer{QosLimitLayer("qos", qos), AssocLimitLayer(user), AssocLimitLayer(acct), AssocLimitLayer(root), part}
+	got := ResolveLimits(layers, TresTable{1: {ID: 1, Type: "cpu"}, 1001: {ID: 1001, Type: "gres", Name: "gpu"}})
+
+	want := map[string]struct {
+		value  int64
+		source string
+	}{
+		"max_jobs":             {5, "normal"},
+		"max_wall_pj":          {1440, "normal"},
+		"max_tres_pj/cpu":      {32, "phys/alice(gpu)"},
+		"max_tres_pj/gres/gpu": {4, "phys"},
+		"grp_tres/gres/gpu":    {8, "phys"},
+		"grp_tres/cpu":         {128, "root"},
+		"grp_jobs":             {15, "root"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ResolveLimits() = %+v", got)
+	}
+	for _, l := range got {
+		key := l.Name
+		if l.Tres != "" {
+			key += "/" + l.Tres
+		}
+		w, ok := want[key]
+		if !ok || w.value != l.Value || w.source != l.Source.Name {
+			t.Errorf("ResolveLimits() %s = %d from %s, want %+v", key, l.Value, l.Source.Name, w)
+		}
+		if key == "grp_jobs" && (len(l.Layers) != 3 || l.Layers[0].Value != 20 || l.Layers[2].Source.Name != "root") {
+			t.Errorf("ResolveLimits() grp_jobs layers = %+v", l.Layers)
+		}
+	}
+}
@@ -54,7 +54,7 @@ type Qos struct {
 	ID                    int32   `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
 	Name                  string  `gorm:"column:name;unique" json:"name"`
 	Description           string  `gorm:"column:description" json:"description"`
-	Flags                 uint32  `gorm:"column:flags" json:"flags"`
+	Flags                 uint32  `gorm:"column:flags" json:"-"`
 	GraceTime             uint32  `gorm:"column:grace_time" json:"grace_time"`
 	MaxJobsPA             int32   `gorm:"column:max_jobs_pa" json:"max_jobs_pa"`
 	MaxJobsPerUser        int32   `gorm:"column:max_jobs_per_user" json:"max_jobs_per_user"`
@@ -63,29 +63,129 @@ type Qos struct {
 	MinPrioThresh         int32   `gorm:"column:min_prio_thresh" json:"min_prio_thresh"`
 	MaxSubmitJobsPA       int32   `gorm:"column:max_submit_jobs_pa" json:"max_submit_jobs_pa"`
 	MaxSubmitJobsPerUser  int32   `gorm:"column:max_submit_jobs_per_user" json:"max_submit_jobs_per_user"`
-	MaxTresPA             string  `gorm:"column:max_tres_pa" json:"max_tres_pa"`
-	MaxTresPJ             string  `gorm:"column:max_tres_pj" json:"max_tres_pj"`
-	MaxTresPN             string  `gorm:"column:max_tres_pn" json:"max_tres_pn"`
-	MaxTresPU             string  `gorm:"column:max_tres_pu" json:"max_tres_pu"`
-	MaxTresMinsPJ         string  `gorm:"column:max_tres_mins_pj" json:"max_tres_mins_pj"`
-	MaxTresRunMinsPA      string  `gorm:"column:max_tres_run_mins_pa" json:"max_tres_run_mins_pa"`
-	MaxTresRunMinsPU      string  `gorm:"column:max_tres_run_mins_pu" json:"max_tres_run_mins_pu"`
-	MinTresPJ             string  `gorm:"column:min_tres_pj" json:"min_tres_pj"`
+	MaxTresPA             string  `gorm:"column:max_tres_pa" json:"-"`
+	MaxTresPJ             string  `gorm:"column:max_tres_pj" json:"-"`
+	MaxTresPN             string  `gorm:"column:max_tres_pn" json:"-"`
+	MaxTresPU             string  `gorm:"column:max_tres_pu" json:"-"`
+	MaxTresMinsPJ         string  `gorm:"column:max_tres_mins_pj" json:"-"`
+	MaxTresRunMinsPA      string  `gorm:"column:max_tres_run_mins_pa" json:"-"`
+	MaxTresRunMinsPU      string  `gorm:"column:max_tres_run_mins_pu" json:"-"`
+	MinTresPJ             string  `gorm:"column:min_tres_pj" json:"-"`
 	MaxWallDurationPerJob int32   `gorm:"column:max_wall_duration_per_job" json:"max_wall_duration_per_job"`
 	GrpJobs               int32   `gorm:"column:grp_jobs" json:"grp_jobs"`
 	GrpJobsAccrue         int32   `gorm:"column:grp_jobs_accrue" json:"grp_jobs_accrue"`
 	GrpSubmitJobs         int32   `gorm:"column:grp_submit_jobs" json:"grp_submit_jobs"`
-	GrpTres               string  `gorm:"column:grp_tres" json:"grp_tres"`
-	GrpTresMins           string  `gorm:"column:grp_tres_mins" json:"grp_tres_mins"`
-	GrpTresRunMins        string  `gorm:"column:grp_tres_run_mins" json:"grp_tres_run_mins"`
+	GrpTres               string  `gorm:"column:grp_tres" json:"-"`
+	GrpTresMins           string  `gorm:"column:grp_tres_mins" json:"-"`
+	GrpTresRunMins        string  `gorm:"column:grp_tres_run_mins" json:"-"`
 	GrpWall               int32   `gorm:"column:grp_wall" json:"grp_wall"`
 	Preempt               string  `gorm:"column:preempt" json:"preempt"`
-	PreemptMode           int32   `gorm:"column:preempt_mode" json:"preempt_mode"`
+	PreemptMode           int32   `gorm:"column:preempt_mode" json:"-"`
 	PreemptExemptTime     uint32  `gorm:"column:preempt_exempt_time" json:"preempt_exempt_time"`
 	Priority              uint32  `gorm:"column:priority" json:"priority"`
 	UsageFactor           float64 `gorm:"column:usage_factor" json:"usage_factor"`
 	UsageThres            float64 `gorm:"column:usage_thres" json:"usage_thres"`
 	LimitFactor           float64 `gorm:"column:limit_factor" json:"limit_factor"`
+
+	// 以下为 Decode 填充的解码结果, 原始取值保存在 Raw 中
+	FlagNames               []string    `gorm:"-" json:"flags"`
+	PreemptModeNames        []string    `gorm:"-" json:"preempt_mode"`
+	MaxTresPADecoded        []TresCount `gorm:"-" json:"max_tres_pa"`
+	MaxTresPJDecoded        []TresCount `gorm:"-" json:"max_tres_pj"`
+	MaxTresPNDecoded        []TresCount `gorm:"-" json:"max_tres_pn"`
+	MaxTresPUDecoded        []TresCount `gorm:"-" json:"max_tres_pu"`
+	MaxTresMinsPJDecoded    []TresCount `gorm:"-" json:"max_tres_mins_pj"`
+	MaxTresRunMinsPADecoded []TresCount `gorm:"-" json:"max_tres_run_mins_pa"`
+	MaxTresRunMinsPUDecoded []TresCount `gorm:"-" json:"max_tres_run_mins_pu"`
+	MinTresPJDecoded        []TresCount `gorm:"-" json:"min_tres_pj"`
+	GrpTresDecoded          []TresCount `gorm:"-" json:"grp_tres"`
+	GrpTresMinsDecoded      []TresCount `gorm:"-" json:"grp_tres_mins"`
+	GrpTresRunMinsDecoded   []TresCount `gorm:"-" json:"grp_tres_run_mins"`
+	Raw                     *QosRaw     `gorm:"-" json:"raw,omitempty"`
+}
+
+// QosRaw QoS 中被解码字段的原始取值.
+type QosRaw struct {
+	Flags            uint32 `json:"flags"`
+	PreemptMode      int32  `json:"preempt_mode"`
+	MaxTresPA        string `json:"max_tres_pa"`
+	MaxTresPJ        string `json:"max_tres_pj"`
+	MaxTresPN        string `json:"max_tres_pn"`
+	MaxTresPU        string `json:"max_tres_pu"`
+	MaxTresMinsPJ    string `json:"max_tres_mins_pj"`
+	MaxTresRunMinsPA string `json:"max_tres_run_mins_pa"`
+	MaxTresRunMinsPU string `json:"max_tres_run_mins_pu"`
+	MinTresPJ        string `json:"min_tres_pj"`
+	GrpTres          string `json:"grp_tres"`
+	GrpTresMins      string `json:"grp_tres_mins"`
+	GrpTresRunMins   string `json:"grp_tres_run_mins"`
+}
+
+// qosFlagNames qos_table 中 flags 列的取值(QOS_FLAG_*).
+var qosFlagNames = []flagName{
+	{0x1, "PartitionMinNodes"},
+	{0x2, "PartitionMaxNodes"},
+	{0x4, "PartitionTimeLimit"},
+	{0x8, "EnforceUsageThreshold"},
+	{0x10, "NoReserve"},
+	{0x20, "RequiresReservation"},
+	{0x40, "DenyOnLimit"},
+	{0x80, "OverPartQOS"},
+	{0x100, "NoDecay"},
+	{0x200, "UsageFactorSafe"},
+	{0x400, "Relative"},
+}
+
+// preemptModeNames preempt_mode 列的取值(PREEMPT_MODE_*), 0 表示 OFF.
+var preemptModeNames = []flagName{
+	{0x1, "SUSPEND"},
+	{0x2, "REQUEUE"},
+	{0x8, "CANCEL"},
+	{0x10, "COND_OFF"},
+	{0x4000, "WITHIN"},
+	{0x8000, "GANG"},
+}
+
+// Decode 将标志、抢占模式与各 TRES 限制解码为可读形式, 原始取值保存到 Raw.
+func (q *Qos) Decode(tres TresTable) {
+	q.FlagNames = decodeFlags(uint64(q.Flags), qosFlagNames)
+	q.PreemptModeNames = decodeFlags(uint64(q.PreemptMode), preemptModeNames)
+	if q.PreemptMode == 0 {
+		q.PreemptModeNames = []string{"OFF"}
+	}
+	q.MaxTresPADecoded = tres.Decode(q.MaxTresPA)
+	q.MaxTresPJDecoded = tres.Decode(q.MaxTresPJ)
+	q.MaxTresPNDecoded = tres.Decode(q.MaxTresPN)
+	q.MaxTresPUDecoded = tres.Decode(q.MaxTresPU)
+	q.MaxTresMinsPJDecoded = tres.Decode(q.MaxTresMinsPJ)
+	q.MaxTresRunMinsPADecoded = tres.Decode(q.MaxTresRunMinsPA)
+	q.MaxTresRunMinsPUDecoded = tres.Decode(q.MaxTresRunMinsPU)
+	q.MinTresPJDecoded = tres.Decode(q.MinTresPJ)
+	q.GrpTresDecoded = tres.Decode(q.GrpTres)
+	q.GrpTresMinsDecoded = tres.Decode(q.GrpTresMins)
+	q.GrpTresRunMinsDecoded = tres.Decode(q.GrpTresRunMins)
+	q.Raw = &QosRaw{
+		Flags:            q.Flags,
+		PreemptMode:      q.PreemptMode,
+		MaxTresPA:        q.MaxTresPA,
+		MaxTresPJ:        q.MaxTresPJ,
+		MaxTresPN:        q.MaxTresPN,
+		MaxTresPU:        q.MaxTresPU,
+		MaxTresMinsPJ:    q.MaxTresMinsPJ,
+		MaxTresRunMinsPA: q.MaxTresRunMinsPA,
+		MaxTresRunMinsPU: q.MaxTresRunMinsPU,
+		MinTresPJ:        q.MinTresPJ,
+		GrpTres:          q.GrpTres,
+		GrpTresMins:      q.GrpTresMins,
+		GrpTresRunMins:   q.GrpTresRunMins,
+	}
+}
+
+// Decode 解码列表中的所有 QoS.
+func (qs Qoses) Decode(tres TresTable) {
+	for i := range qs {
+		qs[i].Decode(tres)
+	}
 }
 
 // Qoses is a slice of Qos.
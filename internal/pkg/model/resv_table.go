@@ -0,0 +1,59 @@
+package model
+
+/*
++-------------+---------------------+------+-----+---------+-------+
+| Field       | Type                | Null | Key | Default | Extra |
++-------------+---------------------+------+-----+---------+-------+
+| id_resv     | int(10) unsigned    | NO   | PRI | 0       |       |
+| deleted     | tinyint(4)          | YES  |     | 0       |       |
+| assoclist   | text                | NO   |     | NULL    |       |
+| flags       | bigint(20) unsigned | NO   |     | 0       |       |
+| nodelist    | text                | NO   |     | NULL    |       |
+| node_inx    | text                | NO   |     | NULL    |       |
+| resv_name   | text                | NO   |     | NULL    |       |
+| time_start  | bigint(20) unsigned | NO   | PRI | NULL    |       |
+| time_end    | bigint(20) unsigned | NO   |     | 0       |       |
+| tres        | text                | NO   |     | NULL    |       |
+| unused_wall | double unsigned     | NO   |     | 0       |       |
+| comment     | text                | YES  |     | NULL    |       |
++-------------+---------------------+------+-----+---------+-------+
+*/
+
+// Reservations is a slice of Reservation rows.
+type Reservations []Reservation
+
+// Reservation represents a row in <cluster>_resv_table joined with the usage of jobs run inside it.
+// Note: physical table name is cluster-specific ("<cluster>_resv_table").
+// Use DB.Table to target it, while this struct defines the columns' mapping.
+type Reservation struct {
+	IDResv     uint32  `gorm:"column:id_resv;primaryKey" json:"id_resv"`
+	Deleted    int8    `gorm:"column:deleted" json:"deleted"`
+	AssocList  string  `gorm:"column:assoclist" json:"assoclist"`
+	Flags      uint64  `gorm:"column:flags" json:"flags"`
+	Nodelist   string  `gorm:"column:nodelist" json:"nodelist"`
+	NodeInx    string  `gorm:"column:node_inx" json:"node_inx"`
+	Name       string  `gorm:"column:resv_name" json:"resv_name"`
+	TimeStart  uint64  `gorm:"column:time_start;primaryKey" json:"time_start"`
+	TimeEnd    uint64  `gorm:"column:time_end" json:"time_end"`
+	TRES       string  `gorm:"column:tres" json:"tres"`
+	UnusedWall float64 `gorm:"column:unused_wall" json:"unused_wall"`
+	Comment    *string `gorm:"column:comment" json:"comment"`
+
+	// 以下为查询时计算的使用情况
+	JobCount       int64   `gorm:"column:job_count" json:"job_count"`               // 在预约期间内开始运行的作业数
+	JobWallSeconds int64   `gorm:"column:job_wall_seconds" json:"job_wall_seconds"` // 作业在预约时间窗口内的运行时长之和(秒)
+	Duration       int64   `gorm:"-" json:"duration"`                               // 预约时长(秒)
+	Utilization    float64 `gorm:"-" json:"utilization"`                            // 1 - unused_wall / duration
+}
+
+// FillUsage 根据起止时间与 unused_wall 计算预约时长与利用率.
+func (r *Reservation) FillUsage() {
+	if r.TimeEnd <= r.TimeStart {
+		return
+	}
+	r.Duration = int64(r.TimeEnd - r.TimeStart)
+	r.Utilization = 1 - r.UnusedWall/float64(r.Duration)
+	if r.Utilization < 0 {
+		r.Utilization = 0
+	}
+}
@@ -9,14 +9,14 @@ type Steps []Step
 type Step struct {
 	JobDBInx              uint64  `gorm:"column:job_db_inx;primaryKey" json:"job_db_inx"`
 	Deleted               int8    `gorm:"column:deleted" json:"deleted"`
-	ExitCode              int32   `gorm:"column:exit_code" json:"exit_code"`
+	ExitCode              int32   `gorm:"column:exit_code" json:"-"`
 	IDStep                int32   `gorm:"column:id_step;primaryKey" json:"id_step"`
 	StepHetComp           uint32  `gorm:"column:step_het_comp;primaryKey" json:"step_het_comp"`
 	KillRequid            int32   `gorm:"column:kill_requid" json:"kill_requid"`
 	Nodelist              string  `gorm:"column:nodelist" json:"nodelist"`
 	NodesAlloc            uint32  `gorm:"column:nodes_alloc" json:"nodes_alloc"`
 	NodeInx               string  `gorm:"column:node_inx" json:"node_inx"`
-	State                 uint64  `gorm:"column:state" json:"state"`
+	State                 uint64  `gorm:"column:state" json:"-"`
 	StepName              string  `gorm:"column:step_name" json:"step_name"`
 	TaskCnt               uint32  `gorm:"column:task_cnt" json:"task_cnt"`
 	TaskDist              int16   `gorm:"column:task_dist" json:"task_dist"`
@@ -32,7 +32,7 @@ type Step struct {
 	ReqCPUFreqMin         uint32  `gorm:"column:req_cpufreq_min" json:"req_cpufreq_min"`
 	ReqCPUFreq            uint32  `gorm:"column:req_cpufreq" json:"req_cpufreq"`
 	ReqCPUFreqGov         uint32  `gorm:"column:req_cpufreq_gov" json:"req_cpufreq_gov"`
-	TRESAlloc             string  `gorm:"column:tres_alloc" json:"tres_alloc"`
+	TRESAlloc             string  `gorm:"column:tres_alloc" json:"-"`
 	TRESUsageInAve        string  `gorm:"column:tres_usage_in_ave" json:"tres_usage_in_ave"`
 	TRESUsageInMax        string  `gorm:"column:tres_usage_in_max" json:"tres_usage_in_max"`
 	TRESUsageInMaxTaskID  string  `gorm:"column:tres_usage_in_max_taskid" json:"tres_usage_in_max_taskid"`
@@ -49,6 +49,41 @@ type Step struct {
 	TRESUsageOutMinTaskID string  `gorm:"column:tres_usage_out_min_taskid" json:"tres_usage_out_min_taskid"`
 	TRESUsageOutMinNodeID string  `gorm:"column:tres_usage_out_min_nodeid" json:"tres_usage_out_min_nodeid"`
 	TRESUsageOutTot       string  `gorm:"column:tres_usage_out_tot" json:"tres_usage_out_tot"`
+	// Nodes holds the expanded Nodelist. It is only filled on request and
+	// ignored by GORM.
+	Nodes []string `gorm:"-" json:"nodes,omitempty"`
+
+	// 以下为 Decode 填充的解码结果, 原始取值保存在 Raw 中
+	StateName        string      `gorm:"-" json:"state"`
+	Exit             ExitCode    `gorm:"-" json:"exit_code"`
+	TRESAllocDecoded []TresCount `gorm:"-" json:"tres_alloc"`
+	Raw              *StepRaw    `gorm:"-" json:"raw,omitempty"`
+}
+
+// StepRaw 作业步中被解码字段的原始取值.
+type StepRaw struct {
+	State     uint64 `json:"state"`
+	ExitCode  int32  `json:"exit_code"`
+	TRESAlloc string `json:"tres_alloc"`
+}
+
+// Decode 将状态、退出码与 TRES 解码为可读形式, 原始取值保存到 Raw.
+// 退出码为负数(NO_VAL)时表示未知, 解码结果为零值.
+func (s *Step) Decode(tres TresTable) {
+	s.StateName = JobStateName(uint32(s.State))
+	s.Exit = ExitCode{}
+	if s.ExitCode >= 0 {
+		s.Exit = DecodeExitCode(uint32(s.ExitCode))
+	}
+	s.TRESAllocDecoded = tres.Decode(s.TRESAlloc)
+	s.Raw = &StepRaw{State: s.State, ExitCode: s.ExitCode, TRESAlloc: s.TRESAlloc}
+}
+
+// Decode 解码列表中的所有作业步.
+func (ss Steps) Decode(tres TresTable) {
+	for i := range ss {
+		ss[i].Decode(tres)
+	}
 }
 
 // TableName intentionally omitted due to cluster-specific physical table names.
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
++---------------+---------------------+------+-----+---------+----------------+
+| Field         | Type                | Null | Key | Default | Extra          |
++---------------+---------------------+------+-----+---------+----------------+
+| creation_time | bigint(20) unsigned | NO   |     | NULL    |                |
+| deleted       | tinyint(4)          | NO   |     | 0       |                |
+| id            | int(11)             | NO   | PRI | NULL    | auto_increment |
+| type          | tinytext            | NO   | MUL | NULL    |                |
+| name          | tinytext            | NO   |     |         |                |
++---------------+---------------------+------+-----+---------+----------------+
+*/
+
+// Tres represents a row in tres_table.
+type Tres struct {
+	CreationTime uint64 `gorm:"column:creation_time" json:"creation_time"`
+	Deleted      int8   `gorm:"column:deleted" json:"deleted"`
+	ID           uint32 `gorm:"column:id;primaryKey" json:"id"`
+	Type         string `gorm:"column:type" json:"type"`
+	Name         string `gorm:"column:name" json:"name"`
+}
+
+// TableName implements gorm's tabler interface.
+func (Tres) TableName() string { return "tres_table" }
+
+// FullName 返回 sacct 中使用的 TRES 名称, 如 cpu、gres/gpu.
+func (t Tres) FullName() string {
+	if t.Name == "" {
+		return t.Type
+	}
+	return t.Type + "/" + t.Name
+}
+
+// TresTable 以 ID 索引的 TRES 定义.
+type TresTable map[uint32]Tres
+
+// tresUnits 各 TRES 类型在数据库中的计量单位, 未列出的类型为计数.
+var tresUnits = map[string]string{
+	"mem":    "MB",
+	"vmem":   "MB",
+	"pages":  "",
+	"energy": "J",
+	"fs":     "B",
+	"ic":     "B",
+}
+
+// TresCount 解码后的单项 TRES.
+type TresCount struct {
+	ID    uint32 `json:"id"`             // tres_table 中的 ID
+	Name  string `json:"name"`           // 名称, 如 cpu、mem、gres/gpu
+	Count int64  `json:"count"`          // 数量, -1 表示不限制
+	Unit  string `json:"unit,omitempty"` // 计量单位, 为空表示计数
+}
+
+// Decode 将 "1=8,2=16000,1001=2" 形式的 TRES 字符串解码为按 ID 排序的列表.
+// 未在 tres_table 中定义的 ID 以 ID 作为名称; 超出 int64 范围的取值(INFINITE64 等)视为不限制.
+func (t TresTable) Decode(s string) []TresCount {
+	out := make([]TresCount, 0)
+	for _, kv := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(kv), "=")
+		if !ok {
+			continue
+		}
+		id, err := strconv.ParseUint(k, 10, 32)
+		if err != nil {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			u, uerr := strconv.ParseUint(v, 10, 64)
+			if uerr != nil {
+				continue
+			}
+			n = -1
+			if u <= math.MaxInt64 {
+				n = int64(u)
+			}
+		}
+		tc := TresCount{ID: uint32(id), Name: k, Count: n}
+		if def, ok := t[uint32(id)]; ok {
+			tc.Name = def.FullName()
+			tc.Unit = tresUnits[def.Type]
+		}
+		out = append(out, tc)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// ExitCode 由 wait status 拆分的退出码与信号.
+type ExitCode struct {
+	Status uint32 `json:"status"` // 退出码
+	Signal uint32 `json:"signal"` // 终止信号, 0 表示正常退出
+}
+
+// DecodeExitCode 拆分 exit_code 列中保存的 wait status.
+func DecodeExitCode(ec uint32) ExitCode {
+	return ExitCode{Status: (ec >> 8) & 0xff, Signal: ec & 0x7f}
+}
+
+// flagName 位标志与名称的对应关系.
+type flagName struct {
+	bit  uint64
+	name string
+}
+
+// decodeFlags 返回 flags 中已置位标志的名称, 未知位忽略.
+func decodeFlags(flags uint64, names []flagName) []string {
+	out := make([]string, 0)
+	for _, f := range names {
+		if flags&f.bit != 0 {
+			out = append(out, f.name)
+		}
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package model
+
+import "testing"
+
+func TestTresTableDecode(t *testing.T) {
+	tres := TresTable{
+		1:    {ID: 1, Type: "cpu"},
+		2:    {ID: 2, Type: "mem"},
+		4:    {ID: 4, Type: "node"},
+		1001: {ID: 1001, Type: "gres", Name: "gpu"},
+	}
+	got := tres.Decode("1001=2,1=8,2=16000,4=1,9=5,3=18446744073709551614")
+	want := []TresCount{
+		{ID: 1, Name: "cpu", Count: 8},
+		{ID: 2, Name: "mem", Count: 16000, Unit: "MB"},
+		{ID: 3, Name: "3", Count: -1},
+		{ID: 4, Name: "node", Count: 1},
+		{ID: 9, Name: "9", Count: 5},
+		{ID: 1001, Name: "gres/gpu", Count: 2},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Decode() = %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Decode()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if got := tres.Decode(""); len(got) != 0 {
+		t.Errorf("Decode(\"\") = %+v", got)
+	}
+}
+
+func TestJobDecode(t *testing.T) {
+	j := Job{State: 5, ExitCode: 2 << 8, DerivedEC: 9, Flags: 1<<2 | 1<<4, TresAlloc: "1=4"}
+	j.Decode(TresTable{1: {ID: 1, Type: "cpu"}})
+	if j.StateName != "FAILED" || j.Exit != (ExitCode{Status: 2}) || j.DerivedExit != (ExitCode{Signal: 9}) {
+		t.Errorf("Decode() state = %s, exit = %+v, derived = %+v", j.StateName, j.Exit, j.DerivedExit)
+	}
+	if len(j.FlagNames) != 2 || j.FlagNames[0] != "SchedMain" || j.FlagNames[1] != "StartReceived" {
+		t.Errorf("Decode() flags = %v", j.FlagNames)
+	}
+	if j.Raw == nil || j.Raw.State != 5 || j.Raw.TresAlloc != "1=4" || len(j.TresAllocDecoded) != 1 {
+		t.Errorf("Decode() raw = %+v, tres = %+v", j.Raw, j.TresAllocDecoded)
+	}
+}
+
+func TestJobStateValue(t *testing.T) {
+	for name, want := range map[string]uint32{"running": 1, "CD": 3, "OUT_OF_MEMORY": 11, " pd ": 0} {
+		if got, ok := JobStateValue(name); !ok || got != want {
+			t.Errorf("JobStateValue(%q) = %d, %v", name, got, ok)
+		}
+	}
+	if _, ok := JobStateValue("BOGUS"); ok {
+		t.Errorf("JobStateValue(BOGUS) ok")
+	}
+}
+
+func TestAssocDecode(t *testing.T) {
+	tres := TresTable{1: {ID: 1, Type: "cpu"}, 1001: {ID: 1001, Type: "gres", Name: "gpu"}}
+	a := UserAssociation{GrpTres: "1=8,1001=2", MaxTresPJ: "1=4"}
+	a.Decode(tres)
+	if len(a.GrpTresDecoded) != 2 || a.GrpTresDecoded[1].Name != "gres/gpu" || len(a.MaxTresPJDecoded) != 1 {
+		t.Errorf("Decode() grp_tres = %+v, max_tres_pj = %+v", a.GrpTresDecoded, a.MaxTresPJDecoded)
+	}
+	if a.Raw == nil || a.Raw.GrpTres != "1=8,1001=2" || a.Raw.MaxTresPJ != "1=4" {
+		t.Errorf("Decode() raw = %+v", a.Raw)
+	}
+
+	child := &AssocTreeNode{Acct: "phys", User: "alice", Limits: AssocLimits{MaxTresPJ: "1=2"}}
+	root := &AssocTreeNode{Acct: "phys", GrpLimits: AssocGrpLimits{GrpTres: "1001=4"}, Children: []*AssocTreeNode{child}}
+	child.Effective = InheritLimits(root.Limits, child.Limits)
+	root.Decode(tres)
+	if len(root.GrpLimits.GrpTresDecoded) != 1 || root.GrpLimits.Raw == nil || root.GrpLimits.Raw.GrpTres != "1001=4" {
+		t.Errorf("Decode() root grp_limits = %+v", root.GrpLimits)
+	}
+	if len(child.Effective.MaxTresPJDecoded) != 1 || child.Limits.Raw == nil || child.Limits.Raw.MaxTresPJ != "1=2" {
+		t.Errorf("Decode() child limits = %+v, effective = %+v", child.Limits, child.Effective)
+	}
+}
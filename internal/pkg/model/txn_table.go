@@ -0,0 +1,187 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
++-----------+---------------------+------+-----+---------+----------------+
+| Field     | Type                | Null | Key | Default | Extra          |
++-----------+---------------------+------+-----+---------+----------------+
+| id        | int(11)             | NO   | PRI | NULL    | auto_increment |
+| timestamp | bigint(20) unsigned | NO   |     | 0       |                |
+| action    | smallint(6)         | NO   |     | NULL    |                |
+| name      | text                | NO   |     | NULL    |                |
+| actor     | tinytext            | NO   |     | NULL    |                |
+| cluster   | tinytext            | NO   |     | ''      |                |
+| info      | blob                | YES  |     | NULL    |                |
++-----------+---------------------+------+-----+---------+----------------+
+*/
+
+// Txns is a slice of Txn rows.
+type Txns []Txn
+
+// Txn represents a row in txn_table. slurmdbd 为每次 sacctmgr 变更记录一行:
+// action 为 slurmdbd 消息类型, name 为变更对象(新增时为名称, 修改与删除时为 SQL 条件, 如 (name='a' || name='b')),
+// info 为新增或修改的列(如 max_jobs=10, grp_tres='1=100'). 账户、用户与 QoS 等全局对象的 cluster 为空.
+type Txn struct {
+	ID        uint32 `gorm:"column:id;primaryKey" json:"id"`
+	Timestamp uint64 `gorm:"column:timestamp" json:"timestamp"`
+	Action    uint16 `gorm:"column:action" json:"action"`
+	Name      string `gorm:"column:name" json:"name"`
+	Actor     string `gorm:"column:actor" json:"actor"`
+	Cluster   string `gorm:"column:cluster" json:"cluster"`
+	Info      string `gorm:"column:info" json:"info"`
+
+	// 以下为 Decode 填充的解码结果
+	ActionName  string      `gorm:"-" json:"action_name"` // 如 Modify Associations
+	Targets     []string    `gorm:"-" json:"targets"`     // 变更对象, 如 name='a'、id_assoc=12
+	Changes     []TxnChange `gorm:"-" json:"changes"`     // 新增或修改的列
+	Description string      `gorm:"-" json:"description"` // 可读的变更描述
+}
+
+func (Txn) TableName() string { return "txn_table" }
+
+// TxnChange txn_table info 列中的一项变更.
+type TxnChange struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// txnAction slurmdbd 消息类型(slurmdbd_msg_type_t)中记录到 txn_table 的取值.
+type txnAction struct {
+	value uint16
+	key   string // 查询参数使用的名称
+	name  string // 与 sacctmgr list transactions 一致的显示名称
+}
+
+var txnActions = []txnAction{
+	{1402, "add_accounts", "Add Accounts"},
+	{1403, "add_account_coords", "Add Account Coordinators"},
+	{1404, "add_associations", "Add Associations"},
+	{1405, "add_clusters", "Add Clusters"},
+	{1406, "add_users", "Add Users"},
+	{1428, "modify_accounts", "Modify Accounts"},
+	{1429, "modify_associations", "Modify Associations"},
+	{1430, "modify_clusters", "Modify Clusters"},
+	{1431, "modify_users", "Modify Users"},
+	{1435, "remove_accounts", "Remove Accounts"},
+	{1436, "remove_account_coords", "Remove Account Coordinators"},
+	{1437, "archive_dump", "Archive Dump"},
+	{1438, "archive_load", "Archive Load"},
+	{1439, "remove_associations", "Remove Associations"},
+	{1440, "remove_association_usage", "Remove Association Usage"},
+	{1441, "remove_clusters", "Remove Clusters"},
+	{1442, "remove_cluster_usage", "Remove Cluster Usage"},
+	{1443, "remove_users", "Remove Users"},
+	{1451, "add_qos", "Add QOS"},
+	{1454, "remove_qos", "Remove QOS"},
+	{1455, "modify_qos", "Modify QOS"},
+	{1456, "add_wckeys", "Add WCKeys"},
+	{1459, "remove_wckeys", "Remove WCKeys"},
+	{1460, "modify_wckeys", "Modify WCKeys"},
+	{1463, "add_reservation", "Add Reservation"},
+	{1464, "remove_reservation", "Remove Reservation"},
+	{1465, "modify_reservation", "Modify Reservation"},
+}
+
+// TxnActionName 返回 action 的显示名称, 未知取值返回 Unknown(<action>).
+func TxnActionName(action uint16) string {
+	for _, a := range txnActions {
+		if a.value == action {
+			return a.name
+		}
+	}
+	return fmt.Sprintf("Unknown(%d)", action)
+}
+
+// TxnActionValue 返回名称对应的 action, 不区分大小写, 接受 modify_associations 与 Modify Associations 两种形式.
+func TxnActionValue(name string) (uint16, bool) {
+	norm := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), " ", "_"))
+	for _, a := range txnActions {
+		if a.key == norm || strings.ToLower(strings.ReplaceAll(a.name, " ", "_")) == norm {
+			return a.value, true
+		}
+	}
+	return 0, false
+}
+
+// Decode 解码 action, 拆分 name 与 info, 并生成可读的变更描述.
+func (t *Txn) Decode() {
+	t.ActionName = TxnActionName(t.Action)
+	t.Targets = splitTxnTargets(t.Name)
+	t.Changes = ParseTxnInfo(t.Info)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s %s", t.Actor, t.ActionName, strings.Join(t.Targets, ", "))
+	if t.Cluster != "" {
+		fmt.Fprintf(&b, " on cluster %s", t.Cluster)
+	}
+	switch {
+	case len(t.Changes) > 0:
+		pairs := make([]string, 0, len(t.Changes))
+		for _, c := range t.Changes {
+			pairs = append(pairs, c.Key+"="+c.Value)
+		}
+		fmt.Fprintf(&b, " (%s)", strings.Join(pairs, ", "))
+	case strings.TrimSpace(t.Info) != "":
+		fmt.Fprintf(&b, " (%s)", strings.TrimSpace(t.Info))
+	}
+	t.Description = b.String()
+}
+
+// splitTxnTargets 拆分 name 列中以 || 或 OR 连接的条件, 去掉外层括号. 新增操作的 name 为对象名称, 原样返回.
+func splitTxnTargets(name string) []string {
+	name = strings.TrimSpace(name)
+	for len(name) >= 2 && name[0] == '(' && name[len(name)-1] == ')' {
+		name = strings.TrimSpace(name[1 : len(name)-1])
+	}
+	out := make([]string, 0)
+	for _, part := range strings.Split(strings.ReplaceAll(name, " OR ", " || "), "||") {
+		part = strings.Trim(strings.TrimSpace(part), "()")
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// ParseTxnInfo 解析 info 列中逗号分隔的 key=value 项, 去掉取值两侧的单引号. 引号内的逗号不作分隔.
+// 不含 = 的项(如新增协调员时记录的账户列表)不返回.
+func ParseTxnInfo(info string) []TxnChange {
+	var (
+		out    = make([]TxnChange, 0)
+		cur    strings.Builder
+		quoted bool
+	)
+	flush := func() {
+		item := strings.TrimSpace(cur.String())
+		cur.Reset()
+		k, v, ok := strings.Cut(item, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return
+		}
+		v = strings.TrimSpace(v)
+		if len(v) >= 2 && v[0] == '\'' && v[len(v)-1] == '\'' {
+			v = v[1 : len(v)-1]
+		}
+		out = append(out, TxnChange{Key: strings.TrimSpace(k), Value: v})
+	}
+	for i := 0; i < len(info); i++ {
+		switch ch := info[i]; {
+		case ch == '\\' && i+1 < len(info):
+			cur.WriteByte(info[i+1])
+			i++
+		case ch == '\'':
+			quoted = !quoted
+			cur.WriteByte(ch)
+		case ch == ',' && !quoted:
+			flush()
+		default:
+			cur.WriteByte(ch)
+		}
+	}
+	flush()
+	return out
+}
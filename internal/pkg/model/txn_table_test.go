@@ -0,0 +1,46 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTxnInfo(t *testing.T) {
+	got := ParseTxnInfo(", description='GPU, CPU 项目', organization='lab',max_jobs=10, grp_tres='1=100,1001=8'")
+	want := []TxnChange{
+		{"description", "GPU, CPU 项目"},
+		{"organization", "lab"},
+		{"max_jobs", "10"},
+		{"grp_tres", "1=100,1001=8"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTxnInfo() = %v, want %v", got, want)
+	}
+	if got := ParseTxnInfo("acct1,acct2"); len(got) != 0 {
+		t.Errorf("ParseTxnInfo(acct list) = %v, want empty", got)
+	}
+}
+
+func TestTxnDecode(t *testing.T) {
+	txn := Txn{Action: 1429, Name: "(id_assoc=12 || id_assoc=15)", Actor: "alice", Cluster: "linux", Info: "max_jobs=10"}
+	txn.Decode()
+	if txn.ActionName != "Modify Associations" || !reflect.DeepEqual(txn.Targets, []string{"id_assoc=12", "id_assoc=15"}) {
+		t.Errorf("Decode() = %+v", txn)
+	}
+	if want := "alice: Modify Associations id_assoc=12, id_assoc=15 on cluster linux (max_jobs=10)"; txn.Description != want {
+		t.Errorf("Description = %q, want %q", txn.Description, want)
+	}
+
+	if v, ok := TxnActionValue("Modify QOS"); !ok || v != 1455 {
+		t.Errorf("TxnActionValue(Modify QOS) = %d, %v", v, ok)
+	}
+	if v, ok := TxnActionValue("add_users"); !ok || v != 1406 {
+		t.Errorf("TxnActionValue(add_users) = %d, %v", v, ok)
+	}
+	if _, ok := TxnActionValue("job_start"); ok {
+		t.Errorf("TxnActionValue(job_start) ok = true")
+	}
+	if got := TxnActionName(1); got != "Unknown(1)" {
+		t.Errorf("TxnActionName(1) = %q", got)
+	}
+}
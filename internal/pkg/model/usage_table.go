@@ -0,0 +1,93 @@
+package model
+
+/*
+<cluster>_assoc_usage_{hour,day,month}_table
++---------------+---------------------+------+-----+---------+-------+
+| Field         | Type                | Null | Key | Default | Extra |
++---------------+---------------------+------+-----+---------+-------+
+| creation_time | bigint(20) unsigned | NO   |     | NULL    |       |
+| mod_time      | bigint(20) unsigned | NO   |     | 0       |       |
+| deleted       | tinyint(4)          | YES  |     | 0       |       |
+| id            | int(10) unsigned    | NO   | PRI | NULL    |       |
+| id_tres       | int(11)             | NO   | PRI | 1       |       |
+| time_start    | bigint(20) unsigned | NO   | PRI | NULL    |       |
+| alloc_secs    | bigint(20) unsigned | NO   |     | 0       |       |
++---------------+---------------------+------+-----+---------+-------+
+
+<cluster>_usage_{hour,day,month}_table
++---------------+---------------------+------+-----+---------+-------+
+| Field         | Type                | Null | Key | Default | Extra |
++---------------+---------------------+------+-----+---------+-------+
+| creation_time | bigint(20) unsigned | NO   |     | NULL    |       |
+| mod_time      | bigint(20) unsigned | NO   |     | 0       |       |
+| deleted       | tinyint(4)          | YES  |     | 0       |       |
+| id_tres       | int(11)             | NO   | PRI | NULL    |       |
+| time_start    | bigint(20) unsigned | NO   | PRI | NULL    |       |
+| count         | bigint(20) unsigned | NO   |     | 0       |       |
+| alloc_secs    | bigint(20) unsigned | NO   |     | 0       |       |
+| down_secs     | bigint(20) unsigned | NO   |     | 0       |       |
+| pdown_secs    | bigint(20) unsigned | NO   |     | 0       |       |
+| idle_secs     | bigint(20) unsigned | NO   |     | 0       |       |
+| plan_secs     | bigint(20) unsigned | NO   |     | 0       |       |
+| over_secs     | bigint(20) unsigned | NO   |     | 0       |       |
++---------------+---------------------+------+-----+---------+-------+
+*/
+
+// AssocUsage represents an aggregated row of <cluster>_assoc_usage_*_table.
+// alloc_secs 为 TRES 数量与秒数的乘积, 如 CPU 秒、MB 秒.
+type AssocUsage struct {
+	IDAssoc   uint32 `gorm:"column:id"`
+	IDTres    uint32 `gorm:"column:id_tres"`
+	TimeStart uint64 `gorm:"column:time_start"`
+	AllocSecs uint64 `gorm:"column:alloc_secs"`
+}
+
+// ClusterUsage represents a row of <cluster>_usage_*_table with the TRES name decoded.
+type ClusterUsage struct {
+	TimeStart   uint64  `gorm:"column:time_start" json:"time_start"` // 统计周期开始时间
+	IDTres      uint32  `gorm:"column:id_tres" json:"id_tres"`       // TRES ID
+	Tres        string  `gorm:"-" json:"tres"`                       // TRES 名称
+	Count       uint64  `gorm:"column:count" json:"count"`           // 周期内的 TRES 总量
+	AllocSecs   uint64  `gorm:"column:alloc_secs" json:"alloc_secs"` // 已分配
+	DownSecs    uint64  `gorm:"column:down_secs" json:"down_secs"`   // 节点宕机
+	PDownSecs   uint64  `gorm:"column:pdown_secs" json:"pdown_secs"` // 计划停机(节点或分区被管理员下线)
+	IdleSecs    uint64  `gorm:"column:idle_secs" json:"idle_secs"`   // 空闲
+	PlanSecs    uint64  `gorm:"column:plan_secs" json:"plan_secs"`   // 被预约或为等待作业保留
+	OverSecs    uint64  `gorm:"column:over_secs" json:"over_secs"`   // 超出上报容量的分配
+	Reported    uint64  `gorm:"-" json:"reported"`                   // 上报总量, alloc + down + pdown + idle + plan
+	Utilization float64 `gorm:"-" json:"utilization"`                // alloc / reported
+	AllocHours  float64 `gorm:"-" json:"alloc_hours"`                // alloc_secs / 3600, 如 CPU 小时
+}
+
+// Fill 计算上报总量、利用率与分配小时数.
+func (u *ClusterUsage) Fill() {
+	u.Reported = u.AllocSecs + u.DownSecs + u.PDownSecs + u.IdleSecs + u.PlanSecs
+	u.Utilization = 0
+	if u.Reported > 0 {
+		u.Utilization = float64(u.AllocSecs) / float64(u.Reported)
+	}
+	u.AllocHours = float64(u.AllocSecs) / 3600
+}
+
+// UsageSample 一个统计周期内单项 TRES 的使用量.
+type UsageSample struct {
+	TimeStart uint64  `json:"time_start"`  // 统计周期开始时间, 汇总整个时间范围时为范围开始时间
+	Tres      string  `json:"tres"`        // TRES 名称
+	AllocSecs uint64  `json:"alloc_secs"`  // TRES 数量与秒数的乘积
+	Hours     float64 `json:"alloc_hours"` // alloc_secs / 3600, 如 CPU 小时、GPU 小时
+}
+
+// UserUsage 用户的使用量.
+type UserUsage struct {
+	User     string        `json:"user"`               // 用户名
+	Accounts []string      `json:"accounts,omitempty"` // 产生使用量的账户
+	Usage    []UsageSample `json:"usage"`              // 使用量
+}
+
+// AccountUsageNode 账户树中的节点, 使用量包含所有子账户与用户.
+type AccountUsageNode struct {
+	Account     string             `json:"account"`      // 账户名称
+	Usage       []UsageSample      `json:"usage"`        // 汇总后的使用量
+	Users       []UserUsage        `json:"users"`        // 直属用户的使用量
+	SubAccounts []AccountUsageNode `json:"sub_accounts"` // 子账户
+}
@@ -0,0 +1,39 @@
+package model
+
+/*
++---------------+---------------------+------+-----+---------+----------------+
+| Field         | Type                | Null | Key | Default | Extra          |
++---------------+---------------------+------+-----+---------+----------------+
+| creation_time | bigint(20) unsigned | NO   |     | NULL    |                |
+| mod_time      | bigint(20) unsigned | NO   |     | 0       |                |
+| deleted       | tinyint(4)          | YES  |     | 0       |                |
+| is_def        | tinyint(4)          | NO   |     | 0       |                |
+| id_wckey      | int(10) unsigned    | NO   | PRI | NULL    | auto_increment |
+| wckey_name    | tinytext            | NO   | MUL | ''      |                |
+| user          | tinytext            | NO   |     | NULL    |                |
++---------------+---------------------+------+-----+---------+----------------+
+
+<cluster>_wckey_usage_{hour,day,month}_table 的结构与 <cluster>_assoc_usage_*_table 相同, id 为 id_wckey.
+*/
+
+// Wckeys is a slice of Wckey rows.
+type Wckeys []Wckey
+
+// Wckey represents a row in <cluster>_wckey_table. 每个用户与 WCKey 名称的组合对应一行.
+// Note: physical table name is cluster-specific ("<cluster>_wckey_table").
+type Wckey struct {
+	CreationTime uint64 `gorm:"column:creation_time" json:"creation_time"`
+	ModTime      uint64 `gorm:"column:mod_time" json:"mod_time"`
+	Deleted      int8   `gorm:"column:deleted" json:"deleted"`
+	IsDef        int8   `gorm:"column:is_def" json:"is_def"` // 是否为用户的默认 WCKey
+	IDWckey      uint32 `gorm:"column:id_wckey;primaryKey" json:"id_wckey"`
+	Name         string `gorm:"column:wckey_name" json:"wckey_name"`
+	User         string `gorm:"column:user" json:"user"`
+}
+
+// WckeyUsage WCKey 的使用量, 汇总了所有用户的该 WCKey.
+type WckeyUsage struct {
+	Wckey string        `json:"wckey"`           // WCKey 名称
+	Users []UserUsage   `json:"users,omitempty"` // 各用户的使用量
+	Usage []UsageSample `json:"usage"`           // 汇总后的使用量
+}